@@ -2,7 +2,10 @@ package main
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"itfinder.adrianescat.com/graph/model"
+	"net"
 	"net/http"
 )
 
@@ -25,3 +28,18 @@ func (app *app) contextGetUser(r *http.Request) *model.User {
 	}
 	return user
 }
+
+// The contextSetClient() method adds to the context of the request a key that identifies
+// the client, a hash of its IP address and user agent, so the anonymous users can be told
+// apart without storing their IP address.
+func (app *app) contextSetClient(r *http.Request) *http.Request {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+
+	sum := sha256.Sum256([]byte(ip + "\n" + r.UserAgent()))
+
+	ctx := context.WithValue(r.Context(), "client", hex.EncodeToString(sum[:16]))
+	return r.WithContext(ctx)
+}
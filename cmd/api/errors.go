@@ -66,3 +66,13 @@ func (app *app) inactiveAccountResponse(w http.ResponseWriter, r *http.Request)
 	message := "your user account must be activated to access this resource"
 	app.errorResponse(w, r, http.StatusForbidden, message)
 }
+
+func (app *app) notPermittedResponse(w http.ResponseWriter, r *http.Request) {
+	message := "your user account doesn't have the necessary permissions to access this resource"
+	app.errorResponse(w, r, http.StatusForbidden, message)
+}
+
+func (app *app) invalidSignedURLResponse(w http.ResponseWriter, r *http.Request) {
+	message := "the link is invalid or has expired"
+	app.errorResponse(w, r, http.StatusForbidden, message)
+}
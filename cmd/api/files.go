@@ -0,0 +1,135 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"itfinder.adrianescat.com/graph/model"
+	"itfinder.adrianescat.com/internal/storage"
+)
+
+// showPictureHandler serves the thumbnails of the uploaded pictures. Every upload gets
+// a new random id and the files are never overwritten, so clients and proxies can
+// cache them forever.
+func (app *app) showPictureHandler(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id := ps.ByName("id")
+	file := ps.ByName("file")
+
+	contentType, ok := model.ParsePictureFile(id, file)
+	if !ok {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	etag := `"` + id + `"`
+
+	setCacheHeaders := func() {
+		w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+		w.Header().Set("ETag", etag)
+	}
+
+	if r.Header.Get("If-None-Match") == etag {
+		setCacheHeaders()
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	body, err := app.storage.Get(r.Context(), "pictures/"+id+"/"+file)
+	if err != nil {
+		switch {
+		case errors.Is(err, storage.ErrNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	defer body.Close()
+
+	setCacheHeaders()
+	w.Header().Set("Content-Type", contentType)
+
+	_, err = io.Copy(w, body)
+	if err != nil {
+		app.logError(r, err)
+	}
+}
+
+// downloadResumeHandler serves a resume through the signed URL built by the downloadUrl
+// field. The URL is issued to a user, and the permission of that user is checked again
+// here, in case it was revoked or the user was suspended since. Range requests are supported so large files can
+// be resumed.
+func (app *app) downloadResumeHandler(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	userId, err := app.signer.Verify(r.URL.Path, r.URL.Query(), time.Now())
+	if err != nil {
+		app.invalidSignedURLResponse(w, r)
+		return
+	}
+
+	// Links are usually opened by the browser without the bearer token, but when there
+	// is one it must belong to the user the link was issued to.
+	if user := app.contextGetUser(r); !user.IsAnonymous() && user.ID != userId {
+		app.notPermittedResponse(w, r)
+		return
+	}
+
+	id, err := strconv.ParseInt(ps.ByName("id"), 10, 64)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	resume, err := app.models.Resumes.Get(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	allowed, err := app.models.Resumes.CanDownload(resume, userId)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if !allowed {
+		app.notPermittedResponse(w, r)
+		return
+	}
+
+	body, err := app.storage.Get(r.Context(), resume.StorageKey)
+	if err != nil {
+		switch {
+		case errors.Is(err, storage.ErrNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	defer body.Close()
+
+	w.Header().Set("Content-Type", resume.ContentType)
+	disposition := mime.FormatMediaType("attachment", map[string]string{"filename": resume.Filename})
+	if disposition == "" {
+		disposition = "attachment"
+	}
+
+	w.Header().Set("Content-Disposition", disposition)
+	w.Header().Set("Cache-Control", "private, no-store")
+	w.Header().Set("ETag", `"`+resume.Checksum+`"`)
+
+	// ServeContent handles the Range, If-Range and conditional headers.
+	http.ServeContent(w, r, resume.Filename, resume.CreatedAt, body)
+}
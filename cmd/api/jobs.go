@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"itfinder.adrianescat.com/graph/model"
+	"itfinder.adrianescat.com/internal/storage"
+)
+
+// startJobs launches the periodic background jobs of the application.
+func (app *app) startJobs() {
+	app.runPeriodically("saved search digests", app.config.jobs.savedSearchDigestInterval, app.sendSavedSearchDigests)
+	app.runPeriodically("offer scheduler", app.config.jobs.offerSchedulerInterval, app.scheduleOffers)
+	app.runPeriodically("account purge", app.config.jobs.accountPurgeInterval, app.purgeDeletedAccounts)
+}
+
+// runPeriodically calls fn every interval in a background goroutine until the
+// application starts shutting down.
+func (app *app) runPeriodically(name string, interval time.Duration, fn func() error) {
+	app.background(func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-app.quit:
+				return
+			case <-ticker.C:
+				err := fn()
+				if err != nil {
+					app.logger.PrintError(err, map[string]string{
+						"job": name,
+					})
+				}
+			}
+		}
+	})
+}
+
+// sendSavedSearchDigests emails the users the offers that matched their saved searches
+// since the last digest.
+func (app *app) sendSavedSearchDigests() error {
+	digests, err := app.models.SavedSearches.GetDueDigests()
+	if err != nil {
+		return err
+	}
+
+	for _, digest := range digests {
+		err = app.mailer.Send(digest.Email, "saved_search_alert.tmpl", digest)
+		if err != nil {
+			app.logger.PrintError(err, map[string]string{
+				"saved_search_id": fmt.Sprintf("%d", digest.SavedSearchId),
+			})
+			continue
+		}
+
+		err = app.models.SavedSearches.MarkNotified(digest)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// scheduleOffers closes the expired offers, publishes the scheduled ones, matching them
+// against the saved searches, and tells the applicants of the closed offers. The
+// notifications stop early when the application is shutting down, the remaining ones
+// are sent on the next run.
+func (app *app) scheduleOffers() error {
+	closed, err := app.models.Offers.CloseExpired()
+	if err != nil {
+		return err
+	}
+
+	published, err := app.models.Offers.PublishDue()
+	if err != nil {
+		return err
+	}
+
+	if closed > 0 || len(published) > 0 {
+		app.logger.PrintInfo("offers scheduled", map[string]string{
+			"closed":    fmt.Sprintf("%d", closed),
+			"published": fmt.Sprintf("%d", len(published)),
+		})
+	}
+
+	for _, id := range published {
+		_, err = app.models.SavedSearches.RecordMatches(id)
+		if err != nil {
+			app.logger.PrintError(err, map[string]string{
+				"offer_id": fmt.Sprintf("%d", id),
+			})
+		}
+	}
+
+	closures, err := app.models.Offers.GetPendingClosures()
+	if err != nil {
+		return err
+	}
+
+	for _, closure := range closures {
+		select {
+		case <-app.quit:
+			return nil
+		default:
+		}
+
+		err = app.mailer.Send(closure.Email, "offer_closed.tmpl", closure)
+		if err != nil {
+			app.logger.PrintError(err, map[string]string{
+				"offer_id":   fmt.Sprintf("%d", closure.OfferId),
+				"profile_id": fmt.Sprintf("%d", closure.ProfileId),
+			})
+			continue
+		}
+
+		err = app.models.Offers.MarkClosureNotified(closure)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// purgeDeletedAccounts deletes the accounts whose deletion grace period is over, and the
+// pictures of their offers.
+func (app *app) purgeDeletedAccounts() error {
+	purged, pictures, err := app.models.Users.PurgeDeleted(app.config.accounts.deletionGracePeriod)
+	if err != nil {
+		return err
+	}
+
+	for _, url := range pictures {
+		picture, ok := model.PictureFromURL(app.config.publicURL, url)
+		if !ok {
+			continue
+		}
+
+		for size := range model.PictureSizes {
+			err := app.storage.Delete(context.Background(), model.PictureKey(picture.ID, size, picture.Format))
+			if err != nil && !errors.Is(err, storage.ErrNotFound) {
+				app.logger.PrintError(err, map[string]string{
+					"picture_id": picture.ID,
+				})
+			}
+		}
+	}
+
+	if purged > 0 {
+		app.logger.PrintInfo("deleted accounts purged", map[string]string{
+			"accounts": fmt.Sprintf("%d", purged),
+		})
+	}
+
+	return nil
+}
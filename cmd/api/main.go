@@ -2,7 +2,10 @@ package main
 
 import (
 	"context"
+	"crypto/rand"
 	"database/sql"
+	"errors"
+	"fmt"
 	"os"
 	"sync"
 	"time"
@@ -12,6 +15,9 @@ import (
 	_ "github.com/sakirsensoy/genv/dotenv/autoload"
 	"itfinder.adrianescat.com/graph/model"
 	"itfinder.adrianescat.com/internal/jsonlog"
+	"itfinder.adrianescat.com/internal/mailer"
+	"itfinder.adrianescat.com/internal/signedurl"
+	"itfinder.adrianescat.com/internal/storage"
 	"itfinder.adrianescat.com/internal/vcs"
 )
 
@@ -27,19 +33,59 @@ type dbConfig struct {
 }
 
 type config struct {
-	port int
-	env  string
-	db   dbConfig
-	cors struct {
+	port      int
+	env       string
+	publicURL string
+	db        dbConfig
+	cors      struct {
 		trustedOrigins []string
 	}
+	smtp struct {
+		host     string
+		port     int
+		username string
+		password string
+		sender   string
+	}
+	jobs struct {
+		savedSearchDigestInterval time.Duration
+		offerSchedulerInterval    time.Duration
+		accountPurgeInterval      time.Duration
+	}
+	offers struct {
+		duplicates string
+	}
+	accounts struct {
+		deletionGracePeriod time.Duration
+	}
+	downloads struct {
+		secret string
+		ttl    time.Duration
+	}
+	storage struct {
+		backend   string
+		localPath string
+		s3        struct {
+			endpoint  string
+			region    string
+			bucket    string
+			accessKey string
+			secretKey string
+		}
+	}
 }
 
 type app struct {
-	config *config
-	logger *jsonlog.Logger
-	models model.Models
-	wg     sync.WaitGroup
+	config  *config
+	logger  *jsonlog.Logger
+	models  model.Models
+	mailer  mailer.Mailer
+	storage storage.Storage
+	signer  *signedurl.Signer
+	wg      sync.WaitGroup
+	// quit is closed when the server is shutting down, so the long-running
+	// background jobs know they have to return.
+	quit chan struct{}
 }
 
 func main() {
@@ -48,6 +94,8 @@ func main() {
 		env:  genv.Key("ENV").Default("development").String(),
 	}
 
+	cfg.publicURL = genv.Key("PUBLIC-URL").Default(fmt.Sprintf("http://localhost:%d", cfg.port)).String()
+
 	cfg.db.dsn = genv.Key("DB-DSN").String()
 	cfg.db.maxOpenConns = genv.Key("DB-MAX-OPEN-CONNS").Default(25).Int()
 	cfg.db.maxIdleConns = genv.Key("DB-MAX-IDLE-CONNS").Default(25).Int()
@@ -56,8 +104,65 @@ func main() {
 	trustedDomains := []string{"http://localhost:3000"}
 	cfg.cors.trustedOrigins = trustedDomains
 
+	cfg.smtp.host = genv.Key("SMTP-HOST").String()
+	cfg.smtp.port = genv.Key("SMTP-PORT").Default(25).Int()
+	cfg.smtp.username = genv.Key("SMTP-USERNAME").String()
+	cfg.smtp.password = genv.Key("SMTP-PASSWORD").String()
+	cfg.smtp.sender = genv.Key("SMTP-SENDER").Default("ITFinder <no-reply@itfinder.adrianescat.com>").String()
+
+	cfg.storage.backend = genv.Key("STORAGE-BACKEND").Default("local").String()
+	cfg.storage.localPath = genv.Key("STORAGE-LOCAL-PATH").Default("./uploads").String()
+	cfg.storage.s3.endpoint = genv.Key("S3-ENDPOINT").String()
+	cfg.storage.s3.region = genv.Key("S3-REGION").Default("us-east-1").String()
+	cfg.storage.s3.bucket = genv.Key("S3-BUCKET").String()
+	cfg.storage.s3.accessKey = genv.Key("S3-ACCESS-KEY").String()
+	cfg.storage.s3.secretKey = genv.Key("S3-SECRET-KEY").String()
+
+	cfg.downloads.secret = genv.Key("DOWNLOAD-URL-SECRET").String()
+
+	cfg.offers.duplicates = genv.Key("DUPLICATE-OFFERS").Default("warn").String()
+
 	logger := jsonlog.New(os.Stdout, jsonlog.LevelInfo)
 
+	downloadTTL, err := time.ParseDuration(genv.Key("DOWNLOAD-URL-TTL").Default("15m").String())
+	if err != nil {
+		logger.PrintFatal(err, nil)
+	}
+
+	cfg.downloads.ttl = downloadTTL
+
+	digestInterval, err := time.ParseDuration(genv.Key("SAVED-SEARCH-DIGEST-INTERVAL").Default("1h").String())
+	if err != nil {
+		logger.PrintFatal(err, nil)
+	}
+
+	cfg.jobs.savedSearchDigestInterval = digestInterval
+
+	schedulerInterval, err := time.ParseDuration(genv.Key("OFFER-SCHEDULER-INTERVAL").Default("1m").String())
+	if err != nil {
+		logger.PrintFatal(err, nil)
+	}
+
+	cfg.jobs.offerSchedulerInterval = schedulerInterval
+
+	purgeInterval, err := time.ParseDuration(genv.Key("ACCOUNT-PURGE-INTERVAL").Default("1h").String())
+	if err != nil {
+		logger.PrintFatal(err, nil)
+	}
+
+	cfg.jobs.accountPurgeInterval = purgeInterval
+
+	gracePeriod, err := time.ParseDuration(genv.Key("ACCOUNT-DELETION-GRACE-PERIOD").Default("720h").String())
+	if err != nil {
+		logger.PrintFatal(err, nil)
+	}
+
+	cfg.accounts.deletionGracePeriod = gracePeriod
+
+	if cfg.offers.duplicates != "warn" && cfg.offers.duplicates != "block" {
+		logger.PrintFatal(fmt.Errorf("DUPLICATE-OFFERS must be warn or block, got %q", cfg.offers.duplicates), nil)
+	}
+
 	db, err := openDB(cfg)
 	if err != nil {
 		logger.PrintFatal(err, nil)
@@ -71,9 +176,71 @@ func main() {
 		config: cfg,
 		logger: logger,
 		models: model.NewModels(db),
+		quit:   make(chan struct{}),
 	}
 
-	app.serve(db)
+	// Without an SMTP server the emails are written to the log, which is enough for
+	// development.
+	if cfg.smtp.host != "" {
+		app.mailer = mailer.NewSMTPMailer(cfg.smtp.host, cfg.smtp.port, cfg.smtp.username, cfg.smtp.password, cfg.smtp.sender)
+	} else {
+		app.mailer = mailer.NewLogMailer(logger)
+	}
+
+	app.storage, err = openStorage(cfg)
+	if err != nil {
+		logger.PrintFatal(err, nil)
+	}
+
+	app.signer, err = newSigner(cfg, logger)
+	if err != nil {
+		logger.PrintFatal(err, nil)
+	}
+
+	app.startJobs()
+
+	err = app.serve(db)
+	if err != nil {
+		logger.PrintFatal(err, nil)
+	}
+}
+
+// openStorage builds the configured storage backend. The s3 backend talks to any S3
+// compatible service, for development point S3-ENDPOINT to a local MinIO.
+func openStorage(cfg *config) (storage.Storage, error) {
+	switch cfg.storage.backend {
+	case "local":
+		return storage.NewLocalStorage(cfg.storage.localPath)
+	case "s3":
+		s3 := cfg.storage.s3
+		return storage.NewS3Storage(s3.endpoint, s3.region, s3.bucket, s3.accessKey, s3.secretKey)
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", cfg.storage.backend)
+	}
+}
+
+// newSigner builds the signer of the download URLs. Without a configured secret a
+// random one is used, so the URLs stop working when the server restarts, which is only
+// acceptable during development.
+func newSigner(cfg *config, logger *jsonlog.Logger) (*signedurl.Signer, error) {
+	if cfg.downloads.secret != "" {
+		return signedurl.New([]byte(cfg.downloads.secret)), nil
+	}
+
+	if cfg.env == "production" {
+		return nil, errors.New("DOWNLOAD-URL-SECRET must be set in production")
+	}
+
+	secret := make([]byte, 32)
+
+	_, err := rand.Read(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.PrintInfo("DOWNLOAD-URL-SECRET not set, using a random secret", nil)
+
+	return signedurl.New(secret), nil
 }
 
 func openDB(cfg *config) (*sql.DB, error) {
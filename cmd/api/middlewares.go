@@ -44,6 +44,12 @@ func (app *app) recoverPanic(next http.Handler) http.Handler {
 	})
 }
 
+func (app *app) identifyClient(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, app.contextSetClient(r))
+	})
+}
+
 func (app *app) authenticate(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Add the "Vary: Authorization" header to the response. This indicates to any
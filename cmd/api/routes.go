@@ -3,6 +3,9 @@ package main
 import (
 	"database/sql"
 	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/99designs/gqlgen/graphql/handler/lru"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
 	"github.com/99designs/gqlgen/graphql/playground"
 	"github.com/julienschmidt/httprouter"
 	"github.com/justinas/alice"
@@ -10,34 +13,71 @@ import (
 	"itfinder.adrianescat.com/graph/dataloaders"
 	"itfinder.adrianescat.com/graph/model"
 	"net/http"
+	"time"
 )
 
+// maxUploadSize bounds the whole multipart request, leaving room for the largest file
+// accepted (see model.MaxResumeSize and model.MaxPictureSize) and the rest of the form.
+// The size of every kind of file is validated on its own by the mutation receiving it.
+const maxUploadSize = 6 << 20
+
 func (app *app) routes(db *sql.DB) http.Handler {
 	router := httprouter.New()
 	router.NotFound = http.HandlerFunc(app.notFoundResponse)
 	router.MethodNotAllowed = http.HandlerFunc(app.methodNotAllowedResponse)
 
-	loader := dataloaders.NewDataLoader(&model.UserModel{DB: db})
+	models := model.NewModels(db)
 
-	gql := handler.NewDefaultServer(graph.NewExecutableSchema(graph.Config{Resolvers: &graph.Resolver{
-		Models: model.NewModels(db),
-		Logger: app.logger,
+	gql := handler.New(graph.NewExecutableSchema(graph.Config{Resolvers: &graph.Resolver{
+		Models:                     models,
+		Logger:                     app.logger,
+		Mailer:                     app.mailer,
+		Storage:                    app.storage,
+		PublicURL:                  app.config.publicURL,
+		Signer:                     app.signer,
+		DownloadURLTTL:             app.config.downloads.ttl,
+		Background:                 app.background,
+		BlockDuplicateOffers:       app.config.offers.duplicates == "block",
+		AccountDeletionGracePeriod: app.config.accounts.deletionGracePeriod,
 	}}))
 
+	// Same setup as handler.NewDefaultServer, with the multipart transport limited to
+	// the size of the uploads we accept.
+	gql.AddTransport(transport.Websocket{
+		KeepAlivePingInterval: 10 * time.Second,
+	})
+	gql.AddTransport(transport.Options{})
+	gql.AddTransport(transport.GET{})
+	gql.AddTransport(transport.POST{})
+	gql.AddTransport(transport.MultipartForm{
+		MaxUploadSize: maxUploadSize,
+		MaxMemory:     1 << 20,
+	})
+
+	gql.SetQueryCache(lru.New(1000))
+
+	gql.Use(extension.Introspection{})
+	gql.Use(extension.AutomaticPersistedQuery{
+		Cache: lru.New(100),
+	})
+
 	plg := playground.Handler("GraphQL playground", "/query")
 
 	router.Handle(http.MethodPost, "/query", func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
 		gql.ServeHTTP(w, req)
 	})
 
+	router.Handle(http.MethodGet, "/pictures/:id/:file", app.showPictureHandler)
+	router.Handle(http.MethodGet, "/resumes/:id/download", app.downloadResumeHandler)
+
 	router.Handle(http.MethodGet, "/", func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
 		plg.ServeHTTP(w, req)
 	})
 
-	standard := alice.New(app.recoverPanic, app.logRequest, secureHeaders, app.enableCORS, app.authenticate)
+	standard := alice.New(app.recoverPanic, app.logRequest, secureHeaders, app.enableCORS, app.identifyClient, app.authenticate)
 
 	// wrap the query handler with middleware to inject dataloader
-	dataloaderMiddleware := dataloaders.Middleware(loader, router)
+	dataloaderMiddleware := dataloaders.Middleware(models, router)
 
 	return standard.Then(dataloaderMiddleware)
 }
@@ -44,6 +44,9 @@ func (app *app) serve(db *sql.DB) error {
 			shutdownError <- err
 		}
 
+		// Tell the periodic background jobs to stop.
+		close(app.quit)
+
 		// Log a message to say that we're waiting for any background goroutines to
 		// complete their tasks.
 		app.logger.PrintInfo("completing background tasks", map[string]string{
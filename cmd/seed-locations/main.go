@@ -0,0 +1,65 @@
+// Command seed-locations loads the ISO 3166 countries and subdivisions embedded in
+// internal/geo into the database, normalizes the locations of the existing profiles to
+// their codes and resolves the coordinates of profiles and offers from the gazetteer.
+// It's safe to run it more than once.
+package main
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"strconv"
+	"time"
+
+	_ "github.com/lib/pq"
+	"github.com/sakirsensoy/genv"
+	_ "github.com/sakirsensoy/genv/dotenv/autoload"
+	"itfinder.adrianescat.com/graph/model"
+	"itfinder.adrianescat.com/internal/jsonlog"
+)
+
+func main() {
+	logger := jsonlog.New(os.Stdout, jsonlog.LevelInfo)
+
+	db, err := sql.Open("postgres", genv.Key("DB-DSN").String())
+	if err != nil {
+		logger.PrintFatal(err, nil)
+	}
+
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err = db.PingContext(ctx)
+	if err != nil {
+		logger.PrintFatal(err, nil)
+	}
+
+	locations := model.LocationModel{DB: db}
+
+	err = locations.Seed()
+	if err != nil {
+		logger.PrintFatal(err, nil)
+	}
+
+	logger.PrintInfo("countries and subdivisions loaded", nil)
+
+	updated, err := locations.NormalizeProfiles()
+	if err != nil {
+		logger.PrintFatal(err, nil)
+	}
+
+	logger.PrintInfo("profile locations normalized", map[string]string{
+		"updated": strconv.Itoa(updated),
+	})
+
+	updated, err = locations.GeocodeOffers()
+	if err != nil {
+		logger.PrintFatal(err, nil)
+	}
+
+	logger.PrintInfo("offer coordinates resolved", map[string]string{
+		"updated": strconv.Itoa(updated),
+	})
+}
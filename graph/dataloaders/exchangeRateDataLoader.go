@@ -0,0 +1,65 @@
+package dataloaders
+
+import (
+	"context"
+
+	"github.com/graph-gophers/dataloader"
+	gopher_dataloader "github.com/graph-gophers/dataloader"
+	"itfinder.adrianescat.com/graph/model"
+	"itfinder.adrianescat.com/internal/validator"
+)
+
+// exchangeRateBatcher wraps storage and provides a "get" method for the exchange rate dataloader
+type exchangeRateBatcher struct {
+	e *model.ExchangeRateModel
+}
+
+// GetRates wraps the exchange rate dataloader, so the rates are loaded once per request
+// however many salaries are converted. Unknown currencies are missing from the result.
+func (i *DataLoader) GetRates(ctx context.Context, currencies ...string) (model.ExchangeRates, error) {
+	keys := make(dataloader.Keys, len(currencies))
+	for ix, currency := range currencies {
+		keys[ix] = gopher_dataloader.StringKey(validator.NormalizeCurrency(currency))
+	}
+
+	results, errs := i.exchangeRateLoader.LoadMany(ctx, keys)()
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	rates := make(model.ExchangeRates, len(keys))
+	for ix, result := range results {
+		if rate, ok := result.(float64); ok {
+			rates[keys[ix].String()] = rate
+		}
+	}
+
+	return rates, nil
+}
+
+// get implements the dataloader for finding many exchange rates by currency and returns
+// them in the order requested, with no data for unknown currencies
+func (ebatcher *exchangeRateBatcher) get(ctx context.Context, keys dataloader.Keys) []*dataloader.Result {
+	rates, err := ebatcher.e.GetRates(keys.Keys()...)
+	if err != nil {
+		results := make([]*dataloader.Result, len(keys))
+		for ix := range keys {
+			results[ix] = &dataloader.Result{Data: nil, Error: err}
+		}
+		return results
+	}
+
+	results := make([]*dataloader.Result, len(keys))
+	for ix, key := range keys {
+		rate, ok := rates[key.String()]
+		if !ok {
+			results[ix] = &dataloader.Result{Data: nil, Error: nil}
+			continue
+		}
+		results[ix] = &dataloader.Result{Data: rate, Error: nil}
+	}
+
+	return results
+}
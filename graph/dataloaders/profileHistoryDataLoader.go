@@ -0,0 +1,132 @@
+package dataloaders
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/graph-gophers/dataloader"
+	gopher_dataloader "github.com/graph-gophers/dataloader"
+	"itfinder.adrianescat.com/graph/model"
+)
+
+// experienceBatcher wraps storage and provides a "get" method for the experience dataloader
+type experienceBatcher struct {
+	m *model.ExperienceModel
+}
+
+// educationBatcher wraps storage and provides a "get" method for the education dataloader
+type educationBatcher struct {
+	m *model.EducationModel
+}
+
+// GetExperiences wraps the experience dataloader for efficient retrieval by profile ID
+func (i *DataLoader) GetExperiences(ctx context.Context, profileID int64) ([]*model.Experience, error) {
+	thunk := i.experienceLoader.Load(ctx, gopher_dataloader.StringKey(strconv.FormatInt(profileID, 10)))
+
+	result, err := thunk()
+	if err != nil {
+		return nil, err
+	}
+
+	return result.([]*model.Experience), nil
+}
+
+// GetEducations wraps the education dataloader for efficient retrieval by profile ID
+func (i *DataLoader) GetEducations(ctx context.Context, profileID int64) ([]*model.Education, error) {
+	thunk := i.educationLoader.Load(ctx, gopher_dataloader.StringKey(strconv.FormatInt(profileID, 10)))
+
+	result, err := thunk()
+	if err != nil {
+		return nil, err
+	}
+
+	return result.([]*model.Education), nil
+}
+
+// get implements the dataloader for finding the experiences of many profiles, profiles
+// without experiences get an empty list
+func (b *experienceBatcher) get(ctx context.Context, keys dataloader.Keys) []*dataloader.Result {
+	profileIDs, err := profileIDsFromKeys(keys)
+	if err != nil {
+		return errorResults(keys, err)
+	}
+
+	records, err := b.m.GetAllByProfileIds(profileIDs)
+	if err != nil {
+		return errorResults(keys, err)
+	}
+
+	grouped := make(map[int64][]*model.Experience, len(keys))
+	for _, record := range records {
+		grouped[record.ProfileId] = append(grouped[record.ProfileId], record)
+	}
+
+	results := make([]*dataloader.Result, len(keys))
+	for ix, profileID := range profileIDs {
+		experiences := grouped[profileID]
+		if experiences == nil {
+			experiences = []*model.Experience{}
+		}
+
+		results[ix] = &dataloader.Result{Data: experiences}
+	}
+
+	return results
+}
+
+// get implements the dataloader for finding the educations of many profiles, profiles
+// without educations get an empty list
+func (b *educationBatcher) get(ctx context.Context, keys dataloader.Keys) []*dataloader.Result {
+	profileIDs, err := profileIDsFromKeys(keys)
+	if err != nil {
+		return errorResults(keys, err)
+	}
+
+	records, err := b.m.GetAllByProfileIds(profileIDs)
+	if err != nil {
+		return errorResults(keys, err)
+	}
+
+	grouped := make(map[int64][]*model.Education, len(keys))
+	for _, record := range records {
+		grouped[record.ProfileId] = append(grouped[record.ProfileId], record)
+	}
+
+	results := make([]*dataloader.Result, len(keys))
+	for ix, profileID := range profileIDs {
+		educations := grouped[profileID]
+		if educations == nil {
+			educations = []*model.Education{}
+		}
+
+		results[ix] = &dataloader.Result{Data: educations}
+	}
+
+	return results
+}
+
+// profileIDsFromKeys parses the keys in the same order they were requested
+func profileIDsFromKeys(keys dataloader.Keys) ([]int64, error) {
+	profileIDs := make([]int64, 0, len(keys))
+
+	for _, key := range keys {
+		id, err := strconv.ParseInt(key.String(), 10, 64)
+		if err != nil {
+			return nil, err
+		}
+
+		profileIDs = append(profileIDs, id)
+	}
+
+	return profileIDs, nil
+}
+
+// errorResults fails every key of the batch with the same error
+func errorResults(keys dataloader.Keys, err error) []*dataloader.Result {
+	results := make([]*dataloader.Result, len(keys))
+	for ix := range keys {
+		results[ix] = &dataloader.Result{Error: err}
+	}
+
+	return results
+}
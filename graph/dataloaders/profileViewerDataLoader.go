@@ -0,0 +1,83 @@
+package dataloaders
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/graph-gophers/dataloader"
+	"itfinder.adrianescat.com/graph/model"
+)
+
+// profileViewerKey identifies the relationship of a user with the candidate of a profile
+type profileViewerKey struct {
+	profile *model.Profile
+	viewer  *model.User
+}
+
+func (k profileViewerKey) String() string {
+	return fmt.Sprintf("%d:%d", k.viewer.ID, k.profile.ID)
+}
+
+func (k profileViewerKey) Raw() interface{} {
+	return k
+}
+
+// profileViewerBatcher wraps storage and provides a "get" method for the profile viewer dataloader
+type profileViewerBatcher struct {
+	m *model.ProfileModel
+}
+
+// GetProfileViewer wraps the profile viewer dataloader, so the relationship is looked up
+// once per profile and request however many masked fields are resolved
+func (i *DataLoader) GetProfileViewer(ctx context.Context, profile *model.Profile, viewer *model.User) (model.ProfileViewer, error) {
+	thunk := i.profileViewerLoader.Load(ctx, profileViewerKey{profile: profile, viewer: viewer})
+
+	result, err := thunk()
+	if err != nil {
+		return model.ViewerAnonymous, err
+	}
+
+	return result.(model.ProfileViewer), nil
+}
+
+// get implements the dataloader for finding the relationships of the viewers with many
+// profiles and returns them in the order requested
+func (pbatcher *profileViewerBatcher) get(ctx context.Context, keys dataloader.Keys) []*dataloader.Result {
+	results := make([]*dataloader.Result, len(keys))
+
+	// group the profiles by viewer, a request usually has a single one
+	type group struct {
+		viewer   *model.User
+		profiles []*model.Profile
+		indexes  []int
+	}
+
+	groups := make(map[int64]*group)
+	for ix, key := range keys {
+		k := key.Raw().(profileViewerKey)
+
+		g, ok := groups[k.viewer.ID]
+		if !ok {
+			g = &group{viewer: k.viewer}
+			groups[k.viewer.ID] = g
+		}
+
+		g.profiles = append(g.profiles, k.profile)
+		g.indexes = append(g.indexes, ix)
+	}
+
+	for _, g := range groups {
+		viewers, err := pbatcher.m.Viewers(g.profiles, g.viewer)
+
+		for i, ix := range g.indexes {
+			if err != nil {
+				results[ix] = &dataloader.Result{Data: nil, Error: err}
+				continue
+			}
+
+			results[ix] = &dataloader.Result{Data: viewers[i], Error: nil}
+		}
+	}
+
+	return results
+}
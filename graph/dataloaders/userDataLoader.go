@@ -20,7 +20,11 @@ const (
 
 // DataLoader offers data loaders scoped to a context
 type DataLoader struct {
-	userLoader *dataloader.Loader
+	userLoader          *dataloader.Loader
+	exchangeRateLoader  *dataloader.Loader
+	experienceLoader    *dataloader.Loader
+	educationLoader     *dataloader.Loader
+	profileViewerLoader *dataloader.Loader
 }
 
 // userBatcher wraps storage and provides a "get" method for the user dataloader
@@ -41,20 +45,30 @@ func (i *DataLoader) GetUser(ctx context.Context, userID string) (*model.User, e
 }
 
 // NewDataLoader returns the instantiated Loaders struct for use in a request
-func NewDataLoader(u *model.UserModel) *DataLoader {
-	// instantiate the user dataloader
-	users := &userBatcher{u: u}
+func NewDataLoader(models model.Models) *DataLoader {
+	// instantiate the dataloaders
+	users := &userBatcher{u: &models.Users}
+	exchangeRates := &exchangeRateBatcher{e: &models.ExchangeRates}
+	experiences := &experienceBatcher{m: &models.Experiences}
+	educations := &educationBatcher{m: &models.Educations}
+	profileViewers := &profileViewerBatcher{m: &models.Profiles}
 	// return the DataLoader
 	return &DataLoader{
-		userLoader: dataloader.NewBatchedLoader(users.get),
+		userLoader:         dataloader.NewBatchedLoader(users.get),
+		exchangeRateLoader: dataloader.NewBatchedLoader(exchangeRates.get),
+		// the profile history is edited through mutations, so it's only batched and
+		// never cached
+		experienceLoader:    dataloader.NewBatchedLoader(experiences.get, dataloader.WithCache(&dataloader.NoCache{})),
+		educationLoader:     dataloader.NewBatchedLoader(educations.get, dataloader.WithCache(&dataloader.NoCache{})),
+		profileViewerLoader: dataloader.NewBatchedLoader(profileViewers.get),
 	}
 }
 
-// Middleware injects a DataLoader into the request context so it can be
-// used later in the schema resolvers
-func Middleware(loader *DataLoader, next http.Handler) http.Handler {
+// Middleware injects a new DataLoader into every request context so it can be
+// used later in the schema resolvers, caching the loaded data for that request only
+func Middleware(models model.Models, next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		nextCtx := context.WithValue(r.Context(), loadersKey, loader)
+		nextCtx := context.WithValue(r.Context(), loadersKey, NewDataLoader(models))
 		r = r.WithContext(nextCtx)
 		next.ServeHTTP(w, r)
 	})
@@ -0,0 +1,398 @@
+package model
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"itfinder.adrianescat.com/internal/validator"
+	"time"
+)
+
+const (
+	ApplicationStatusApplied      = "applied"
+	ApplicationStatusReviewing    = "reviewing"
+	ApplicationStatusInterviewing = "interviewing"
+	ApplicationStatusOffered      = "offered"
+	ApplicationStatusHired        = "hired"
+	ApplicationStatusRejected     = "rejected"
+)
+
+var ApplicationStatuses = []string{
+	ApplicationStatusApplied,
+	ApplicationStatusReviewing,
+	ApplicationStatusInterviewing,
+	ApplicationStatusOffered,
+	ApplicationStatusHired,
+	ApplicationStatusRejected,
+}
+
+const (
+	QuestionKindText           = "text"
+	QuestionKindYesNo          = "yes_no"
+	QuestionKindMultipleChoice = "multiple_choice"
+)
+
+type OfferQuestion struct {
+	ID       int64    `json:"id"`
+	OfferId  int64    `json:"offer_id"`
+	Question string   `json:"question"`
+	Kind     string   `json:"kind"`
+	Options  []string `json:"options"`
+	Required bool     `json:"required"`
+	Position int      `json:"position"`
+}
+
+type ApplicationAnswer struct {
+	QuestionId int64  `json:"question_id"`
+	Question   string `json:"question"`
+	Answer     string `json:"answer"`
+}
+
+type Application struct {
+	OfferId     int64                `json:"offer_id"`
+	Offer       *Offer               `json:"offer"`
+	ProfileId   int64                `json:"profile_id"`
+	Profile     *Profile             `json:"profile"`
+	AppliedBy   int64                `json:"-"`
+	Status      string               `json:"status"`
+	CoverLetter string               `json:"cover_letter"`
+	Answers     []*ApplicationAnswer `json:"answers"`
+	CreatedAt   time.Time            `json:"created_at"`
+	UpdatedAt   time.Time            `json:"-"`
+}
+
+type ApplicationModel struct {
+	DB *sql.DB
+}
+
+func ValidateOfferQuestions(v *validator.Validator, questions []*OfferQuestion) {
+	permittedKinds := []string{QuestionKindText, QuestionKindYesNo, QuestionKindMultipleChoice}
+
+	for i, q := range questions {
+		key := fmt.Sprintf("questions[%d]", i)
+
+		v.Check(q.Question != "", key+".question", "must be provided")
+		v.Check(len(q.Question) <= 500, key+".question", "must not be more than 500 bytes long")
+		v.Check(validator.PermittedValue(q.Kind, permittedKinds...), key+".kind", fmt.Sprintf("%s is not a permitted kind", q.Kind))
+
+		if q.Kind == QuestionKindMultipleChoice {
+			v.Check(len(q.Options) >= 2, key+".options", "must contain at least 2 options")
+			v.Check(validator.Unique(q.Options), key+".options", "options must be unique")
+		} else {
+			v.Check(len(q.Options) == 0, key+".options", "only allowed on multiple_choice questions")
+		}
+	}
+}
+
+// ValidateApplication checks the cover letter and that every answer belongs to one of
+// the offer questions, has a value compatible with the question kind, and that all the
+// required questions were answered.
+func ValidateApplication(v *validator.Validator, application *Application, questions []*OfferQuestion) {
+	v.Check(len(application.CoverLetter) <= 5000, "coverLetter", "must not be more than 5000 bytes long")
+
+	byId := make(map[int64]*OfferQuestion, len(questions))
+	for _, q := range questions {
+		byId[q.ID] = q
+	}
+
+	answered := make(map[int64]bool, len(application.Answers))
+
+	for i, a := range application.Answers {
+		key := fmt.Sprintf("answers[%d]", i)
+
+		q, ok := byId[a.QuestionId]
+		if !ok {
+			v.AddError(key+".questionId", "must be a question of the offer")
+			continue
+		}
+
+		v.Check(!answered[a.QuestionId], key+".questionId", "must not be answered more than once")
+		answered[a.QuestionId] = true
+
+		if a.Answer == "" {
+			v.Check(!q.Required, key+".answer", "must be provided")
+			continue
+		}
+
+		switch q.Kind {
+		case QuestionKindYesNo:
+			v.Check(validator.PermittedValue(a.Answer, "yes", "no"), key+".answer", "must be yes or no")
+		case QuestionKindMultipleChoice:
+			v.Check(validator.PermittedValue(a.Answer, q.Options...), key+".answer", "must be one of the question options")
+		default:
+			v.Check(len(a.Answer) <= 2000, key+".answer", "must not be more than 2000 bytes long")
+		}
+	}
+
+	for _, q := range questions {
+		if q.Required {
+			v.Check(answered[q.ID], "answers", fmt.Sprintf("question %d must be answered", q.ID))
+		}
+	}
+}
+
+// insertOfferQuestions stores the offer questions using the provided transaction, so
+// they are created together with the offer.
+func insertOfferQuestions(ctx context.Context, tx *sql.Tx, offerId int64, questions []*OfferQuestion) error {
+	query := `
+		INSERT INTO offer_questions (offer_id, question, kind, options, required, position)
+		VALUES ($1, $2, $3, $4::jsonb, $5, $6)
+		RETURNING id
+	`
+
+	for i, q := range questions {
+		optionsJSON, err := json.Marshal(q.Options)
+		if err != nil {
+			return err
+		}
+
+		q.OfferId = offerId
+		q.Position = i
+
+		args := []any{q.OfferId, q.Question, q.Kind, optionsJSON, q.Required, q.Position}
+
+		err = tx.QueryRowContext(ctx, query, args...).Scan(&q.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (a ApplicationModel) GetQuestionsByOfferId(offerId int64) ([]*OfferQuestion, error) {
+	query := `
+		SELECT id, offer_id, question, kind, options, required, position
+		FROM offer_questions
+		WHERE offer_id = $1
+		ORDER BY position
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := a.DB.QueryContext(ctx, query, offerId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var questions []*OfferQuestion
+
+	for rows.Next() {
+		var question OfferQuestion
+		var options []byte
+		err := rows.Scan(
+			&question.ID,
+			&question.OfferId,
+			&question.Question,
+			&question.Kind,
+			&options,
+			&question.Required,
+			&question.Position,
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if options != nil {
+			err = json.Unmarshal(options, &question.Options)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		questions = append(questions, &question)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return questions, nil
+}
+
+// Insert creates the applicant row and its answers in a single transaction.
+func (a ApplicationModel) Insert(application *Application) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := a.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO offers_applicants (offer_id, profile_id, cover_letter, applied_by)
+		VALUES ($1, $2, NULLIF($3, ''), $4)
+		RETURNING status, created_at, updated_at
+	`
+
+	args := []any{application.OfferId, application.ProfileId, application.CoverLetter, application.AppliedBy}
+
+	err = tx.QueryRowContext(ctx, query, args...).Scan(&application.Status, &application.CreatedAt, &application.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	query = `
+		INSERT INTO application_answers (profile_id, offer_id, question_id, answer)
+		VALUES ($1, $2, $3, $4)
+	`
+
+	for _, answer := range application.Answers {
+		args = []any{application.ProfileId, application.OfferId, answer.QuestionId, answer.Answer}
+
+		_, err = tx.ExecContext(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (a ApplicationModel) GetAllByOfferId(offerId int64) ([]*Application, error) {
+	query := `
+		SELECT offer_id, profile_id, status, COALESCE(cover_letter, ''), created_at, updated_at
+		FROM offers_applicants
+		WHERE offer_id = $1
+		ORDER BY created_at
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := a.DB.QueryContext(ctx, query, offerId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var applications []*Application
+
+	for rows.Next() {
+		var application Application
+		err := rows.Scan(
+			&application.OfferId,
+			&application.ProfileId,
+			&application.Status,
+			&application.CoverLetter,
+			&application.CreatedAt,
+			&application.UpdatedAt,
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		applications = append(applications, &application)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return applications, nil
+}
+
+// GetAllByUserId returns the applications sent with the profile of the given user,
+// most recent first.
+func (a ApplicationModel) GetAllByUserId(userId int64) ([]*Application, error) {
+	query := `
+		SELECT oa.offer_id, oa.profile_id, oa.status, COALESCE(oa.cover_letter, ''), oa.created_at, oa.updated_at
+		FROM offers_applicants oa
+		INNER JOIN profiles p on p.id = oa.profile_id
+		WHERE p.user_id = $1
+		ORDER BY oa.created_at DESC
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := a.DB.QueryContext(ctx, query, userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var applications []*Application
+
+	for rows.Next() {
+		var application Application
+		err := rows.Scan(
+			&application.OfferId,
+			&application.ProfileId,
+			&application.Status,
+			&application.CoverLetter,
+			&application.CreatedAt,
+			&application.UpdatedAt,
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		applications = append(applications, &application)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return applications, nil
+}
+
+func (a ApplicationModel) GetAnswers(offerId int64, profileId int64) ([]*ApplicationAnswer, error) {
+	query := `
+		SELECT aa.question_id, oq.question, aa.answer
+		FROM application_answers aa
+		INNER JOIN offer_questions oq on oq.id = aa.question_id
+		WHERE aa.offer_id = $1 AND aa.profile_id = $2
+		ORDER BY oq.position
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := a.DB.QueryContext(ctx, query, offerId, profileId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var answers []*ApplicationAnswer
+
+	for rows.Next() {
+		var answer ApplicationAnswer
+		err := rows.Scan(
+			&answer.QuestionId,
+			&answer.Question,
+			&answer.Answer,
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		answers = append(answers, &answer)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return answers, nil
+}
@@ -0,0 +1,374 @@
+package model
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"itfinder.adrianescat.com/internal/validator"
+	"time"
+)
+
+var (
+	ErrDuplicateCollection = errors.New("duplicate collection")
+)
+
+type BookmarkCollection struct {
+	ID        int64     `json:"id"`
+	UserId    int64     `json:"user_id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"-"`
+	Name      string    `json:"name"`
+	Version   int       `json:"-"`
+}
+
+// Bookmark is either a profile or an offer bookmark, only one of ProfileId and OfferId
+// is set.
+type Bookmark struct {
+	UserId       int64     `json:"user_id"`
+	ProfileId    *int64    `json:"profile_id"`
+	OfferId      *int64    `json:"offer_id"`
+	CollectionId *int64    `json:"collection_id"`
+	Note         string    `json:"note"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type BookmarkModel struct {
+	DB *sql.DB
+}
+
+func ValidateCollection(v *validator.Validator, collection *BookmarkCollection) {
+	v.Check(collection.Name != "", "name", "must be provided")
+	v.Check(len(collection.Name) <= 100, "name", "must not be more than 100 bytes long")
+}
+
+func ValidateBookmark(v *validator.Validator, bookmark *Bookmark) {
+	v.Check(bookmark.ProfileId != nil || bookmark.OfferId != nil, "bookmark", "a profileId or an offerId must be provided")
+	v.Check(bookmark.ProfileId == nil || bookmark.OfferId == nil, "bookmark", "only one of profileId and offerId must be provided")
+	v.Check(len(bookmark.Note) <= 1000, "note", "must not be more than 1000 bytes long")
+}
+
+func (b BookmarkModel) InsertCollection(collection *BookmarkCollection) error {
+	query := `
+		INSERT INTO bookmark_collections (user_id, name)
+		VALUES ($1, $2)
+		RETURNING id, created_at, updated_at, version
+	`
+
+	args := []any{collection.UserId, collection.Name}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := b.DB.QueryRowContext(ctx, query, args...).Scan(
+		&collection.ID,
+		&collection.CreatedAt,
+		&collection.UpdatedAt,
+		&collection.Version,
+	)
+
+	if err != nil {
+		switch {
+		case err.Error() == `pq: duplicate key value violates unique constraint "bookmark_collections_user_id_name_key"`:
+			return ErrDuplicateCollection
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (b BookmarkModel) GetCollectionsByUserId(userId int64) ([]*BookmarkCollection, error) {
+	query := `
+		SELECT id, user_id, created_at, updated_at, name, version
+		FROM bookmark_collections
+		WHERE user_id = $1
+		ORDER BY name
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := b.DB.QueryContext(ctx, query, userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var collections []*BookmarkCollection
+
+	for rows.Next() {
+		var collection BookmarkCollection
+		err := rows.Scan(
+			&collection.ID,
+			&collection.UserId,
+			&collection.CreatedAt,
+			&collection.UpdatedAt,
+			&collection.Name,
+			&collection.Version,
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		collections = append(collections, &collection)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return collections, nil
+}
+
+// GetItemsByCollectionId returns both the profile and offer bookmarks stored in the
+// collection, oldest first. Bookmarks of rejected or banned content, or of content of
+// suspended or deleted users, are left out.
+func (b BookmarkModel) GetItemsByCollectionId(collectionId int64) ([]*Bookmark, error) {
+	query := `
+		SELECT pb.user_id, pb.profile_id, NULL::bigint, pb.collection_id, COALESCE(pb.note, ''), pb.created_at
+		FROM profile_bookmarks pb
+		INNER JOIN profiles p ON p.id = pb.profile_id
+		WHERE pb.collection_id = $1
+		AND p.` + listedModerationSQL + `
+		AND ` + listedOwnerSQL("p") + `
+		UNION ALL
+		SELECT ob.user_id, NULL::bigint, ob.offer_id, ob.collection_id, COALESCE(ob.note, ''), ob.created_at
+		FROM offer_bookmarks ob
+		INNER JOIN offers o ON o.id = ob.offer_id
+		WHERE ob.collection_id = $1
+		AND o.` + listedModerationSQL + `
+		AND ` + listedOwnerSQL("o") + `
+		ORDER BY created_at
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := b.DB.QueryContext(ctx, query, collectionId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var bookmarks []*Bookmark
+
+	for rows.Next() {
+		var bookmark Bookmark
+		err := rows.Scan(
+			&bookmark.UserId,
+			&bookmark.ProfileId,
+			&bookmark.OfferId,
+			&bookmark.CollectionId,
+			&bookmark.Note,
+			&bookmark.CreatedAt,
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		bookmarks = append(bookmarks, &bookmark)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return bookmarks, nil
+}
+
+// Upsert creates the bookmark, or updates the collection and the note when the user
+// already bookmarked the profile or the offer, an empty note keeps the current one. The
+// collection must belong to the user.
+func (b BookmarkModel) Upsert(bookmark *Bookmark) error {
+	table, column, target := bookmarkTarget(bookmark)
+
+	query := `
+		INSERT INTO ` + table + ` (user_id, ` + column + `, collection_id, note)
+		SELECT $1, $2, $3, NULLIF($4, '')
+		WHERE $3::bigint IS NULL OR EXISTS (
+			SELECT 1 FROM bookmark_collections WHERE id = $3 AND user_id = $1
+		)
+		ON CONFLICT (user_id, ` + column + `) DO UPDATE
+		SET collection_id = EXCLUDED.collection_id, note = COALESCE(EXCLUDED.note, ` + table + `.note)
+		RETURNING created_at, COALESCE(note, '')
+	`
+
+	args := []any{bookmark.UserId, target, bookmark.CollectionId, bookmark.Note}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := b.DB.QueryRowContext(ctx, query, args...).Scan(&bookmark.CreatedAt, &bookmark.Note)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrRecordNotFound
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Move changes the collection of an existing bookmark, a nil CollectionId takes the
+// bookmark out of any collection.
+func (b BookmarkModel) Move(bookmark *Bookmark) error {
+	table, column, target := bookmarkTarget(bookmark)
+
+	query := `
+		UPDATE ` + table + `
+		SET collection_id = $3
+		WHERE user_id = $1 AND ` + column + ` = $2
+		AND ($3::bigint IS NULL OR EXISTS (
+			SELECT 1 FROM bookmark_collections WHERE id = $3 AND user_id = $1
+		))
+		RETURNING COALESCE(note, ''), created_at
+	`
+
+	args := []any{bookmark.UserId, target, bookmark.CollectionId}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := b.DB.QueryRowContext(ctx, query, args...).Scan(&bookmark.Note, &bookmark.CreatedAt)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrRecordNotFound
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (b BookmarkModel) CreateOfferBookmark(userId int64, offerId int64) error {
+	query := `
+		INSERT INTO offer_bookmarks (user_id, offer_id)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id, offer_id) DO NOTHING
+	`
+
+	args := []any{userId, offerId}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := b.DB.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (b BookmarkModel) DeleteOfferBookmark(userId int64, offerId int64) error {
+	query := `
+		DELETE FROM offer_bookmarks
+		WHERE user_id = $1 AND offer_id = $2
+	`
+
+	args := []any{userId, offerId}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := b.DB.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (b BookmarkModel) GetAllOfferBookmarksByUserId(userId int64) ([]*Offer, error) {
+	query := `
+		SELECT o.id, o.created_at, o.title, o.description, o.salary, o.picture_url, o.user_id, o.active, o.version,
+			COALESCE(o.country, ''), COALESCE(o.state, ''), COALESCE(o.city, ''), o.work_mode, o.company_id,
+			o.publish_at, o.expires_at, o.closed_at, o.status, o.moderation_status
+		FROM offers o
+		INNER JOIN offer_bookmarks ob on o.id = ob.offer_id
+		WHERE ob.user_id = $1
+		AND o.` + listedModerationSQL + `
+		AND ` + listedOwnerSQL("o") + `
+		ORDER BY ob.created_at DESC
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := b.DB.QueryContext(ctx, query, userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var offers []*Offer
+
+	for rows.Next() {
+		var offer Offer
+		var salaries []byte
+		err := rows.Scan(
+			&offer.ID,
+			&offer.CreatedAt,
+			&offer.Title,
+			&offer.Description,
+			&salaries,
+			&offer.PictureUrl,
+			&offer.UserId,
+			&offer.Active,
+			&offer.Version,
+			&offer.Country,
+			&offer.State,
+			&offer.City,
+			&offer.WorkMode,
+			&offer.CompanyId,
+			&offer.PublishAt,
+			&offer.ExpiresAt,
+			&offer.ClosedAt,
+			&offer.Status,
+			&offer.ModerationStatus,
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		var salariesObj Salaries
+		err = json.Unmarshal(salaries, &salariesObj)
+		offer.Salary = salariesObj
+
+		if err != nil {
+			return nil, err
+		}
+
+		offers = append(offers, &offer)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return offers, nil
+}
+
+// bookmarkTarget returns the table, the column and the id that the bookmark points to.
+func bookmarkTarget(bookmark *Bookmark) (string, string, int64) {
+	if bookmark.OfferId != nil {
+		return "offer_bookmarks", "offer_id", *bookmark.OfferId
+	}
+
+	return "profile_bookmarks", "profile_id", *bookmark.ProfileId
+}
@@ -0,0 +1,631 @@
+package model
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"itfinder.adrianescat.com/internal/geo"
+	"itfinder.adrianescat.com/internal/validator"
+	"strings"
+	"time"
+)
+
+// CompanyInvitationTTL is how long an invitation to join a company can be accepted.
+const CompanyInvitationTTL = 7 * 24 * time.Hour
+
+var (
+	ErrAlreadyMember = errors.New("already a member")
+)
+
+type Company struct {
+	ID          int64              `json:"id"`
+	CreatedAt   time.Time          `json:"created_at"`
+	UpdatedAt   time.Time          `json:"-"`
+	Name        string             `json:"name"`
+	Slug        string             `json:"slug"`
+	WebsiteUrl  string             `json:"website_url"`
+	LogoUrl     string             `json:"logo_url"`
+	Description string             `json:"description"`
+	Size        *CompanySize       `json:"size"`
+	Industry    string             `json:"industry"`
+	Locations   []*CompanyLocation `json:"locations"`
+	Version     int                `json:"version"`
+}
+
+// CompanyLocation is an office of the company.
+type CompanyLocation struct {
+	Country string `json:"country"`
+	State   string `json:"state,omitempty"`
+	City    string `json:"city,omitempty"`
+}
+
+// CompanyFilters narrows the public companies listing. Search is looked up in the name,
+// the industry and the description.
+type CompanyFilters struct {
+	Search   string
+	Industry string
+	Size     *CompanySize
+}
+
+// CompanyMember is a user working for a company. Any member can manage the company
+// offers and their applicants, owners and admins also manage the members.
+type CompanyMember struct {
+	CompanyId int64       `json:"company_id"`
+	UserId    int64       `json:"user_id"`
+	Role      CompanyRole `json:"role"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+// CompanyInvitation invites whoever owns the email to join the company. Plaintext is
+// only known when the invitation is created, to be sent by email.
+type CompanyInvitation struct {
+	Plaintext string      `json:"-"`
+	Hash      []byte      `json:"-"`
+	CompanyId int64       `json:"company_id"`
+	Email     string      `json:"email"`
+	Role      CompanyRole `json:"role"`
+	InvitedBy int64       `json:"-"`
+	CreatedAt time.Time   `json:"created_at"`
+	Expiry    time.Time   `json:"expiry"`
+}
+
+type CompanyModel struct {
+	DB *sql.DB
+}
+
+func ValidateCompany(v *validator.Validator, company *Company) {
+	v.Check(company.Name != "", "name", "must be provided")
+	v.Check(len(company.Name) <= 150, "name", "must not be more than 150 bytes long")
+	v.Check(Slugify(company.Name) != "", "name", "must contain at least one letter or digit")
+
+	validator.ValidateOptionalUrl(v, "websiteUrl", company.WebsiteUrl)
+	validator.ValidateOptionalUrl(v, "logoUrl", company.LogoUrl)
+
+	v.Check(len(company.Description) <= 5000, "description", "must not be more than 5000 bytes long")
+	v.Check(len(company.Industry) <= 100, "industry", "must not be more than 100 bytes long")
+
+	if company.Size != nil {
+		v.Check(company.Size.IsValid(), "size", fmt.Sprintf("%s is not a permitted company size", *company.Size))
+	}
+
+	v.Check(len(company.Locations) <= 50, "locations", "must not contain more than 50 locations")
+
+	for i, location := range company.Locations {
+		lv := validator.New()
+		ValidateLocation(lv, location.Country, location.State, location.City, true)
+
+		for key, message := range lv.Errors {
+			v.AddError(fmt.Sprintf("locations[%d].%s", i, key), message)
+		}
+	}
+}
+
+// Slugify builds the URL slug of the name: lower-cased ASCII letters and digits, with
+// any other run of characters turned into a single dash.
+func Slugify(name string) string {
+	var b strings.Builder
+
+	dash := false
+	for _, r := range geo.Fold(name) {
+		switch {
+		case 'a' <= r && r <= 'z', '0' <= r && r <= '9':
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+
+			b.WriteRune(r)
+			dash = false
+		default:
+			dash = true
+		}
+
+		if b.Len() >= 60 {
+			break
+		}
+	}
+
+	return b.String()
+}
+
+func ValidateCompanyInvitation(v *validator.Validator, invitation *CompanyInvitation) {
+	ValidateEmail(v, invitation.Email)
+
+	v.Check(
+		validator.PermittedValue(invitation.Role, CompanyRoleAdmin, CompanyRoleRecruiter),
+		"role",
+		fmt.Sprintf("%s is not a permitted role, members can be invited as ADMIN or RECRUITER", invitation.Role),
+	)
+}
+
+// companySlugAttempts is how many slugs are tried when creating a company. When the
+// slug of the name is taken a sequence number is appended to it, and the last attempt
+// falls back to a timestamp.
+const companySlugAttempts = 10
+
+const companyColumns = `c.id, c.created_at, c.updated_at, c.name, c.slug, c.website_url, c.logo_url,
+	c.description, c.size, c.industry, c.locations, c.version`
+
+func scanCompany(row interface{ Scan(...any) error }, company *Company) error {
+	var locations []byte
+
+	err := row.Scan(
+		&company.ID,
+		&company.CreatedAt,
+		&company.UpdatedAt,
+		&company.Name,
+		&company.Slug,
+		&company.WebsiteUrl,
+		&company.LogoUrl,
+		&company.Description,
+		&company.Size,
+		&company.Industry,
+		&locations,
+		&company.Version,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(locations, &company.Locations)
+}
+
+// Insert creates the company with the user as its owner, and a slug generated from its
+// name that isn't used by any other company.
+func (m CompanyModel) Insert(company *Company, ownerId int64) error {
+	locations, err := json.Marshal(company.locations())
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO companies (name, slug, website_url, logo_url, description, size, industry, locations)
+		SELECT $1, $2, $3, $4, $5, $6, $7, $8::jsonb
+		WHERE NOT EXISTS (SELECT 1 FROM companies WHERE slug = $2)
+		ON CONFLICT (slug) DO NOTHING
+		RETURNING id, created_at, updated_at, version
+	`
+
+	base := Slugify(company.Name)
+
+	for attempt := 1; ; attempt++ {
+		company.Slug = base
+		switch {
+		case attempt == companySlugAttempts:
+			company.Slug = fmt.Sprintf("%s-%d", base, time.Now().UnixNano()%1_000_000)
+		case attempt > 1:
+			company.Slug = fmt.Sprintf("%s-%d", base, attempt)
+		}
+
+		args := []any{
+			company.Name,
+			company.Slug,
+			company.WebsiteUrl,
+			company.LogoUrl,
+			company.Description,
+			company.Size,
+			company.Industry,
+			locations,
+		}
+
+		err = tx.QueryRowContext(ctx, query, args...).Scan(
+			&company.ID,
+			&company.CreatedAt,
+			&company.UpdatedAt,
+			&company.Version,
+		)
+
+		if err == nil {
+			break
+		}
+
+		if !errors.Is(err, sql.ErrNoRows) || attempt == companySlugAttempts {
+			return err
+		}
+	}
+
+	query = `
+		INSERT INTO company_members (company_id, user_id, role)
+		VALUES ($1, $2, $3)
+	`
+
+	_, err = tx.ExecContext(ctx, query, company.ID, ownerId, CompanyRoleOwner)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (m CompanyModel) Get(id int64) (*Company, error) {
+	if id < 1 {
+		return nil, ErrRecordNotFound
+	}
+
+	return m.getBy("c.id = $1", id)
+}
+
+func (m CompanyModel) GetBySlug(slug string) (*Company, error) {
+	return m.getBy("c.slug = $1", strings.ToLower(strings.TrimSpace(slug)))
+}
+
+func (m CompanyModel) getBy(where string, arg any) (*Company, error) {
+	query := `SELECT ` + companyColumns + ` FROM companies c WHERE ` + where
+
+	var company Company
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := scanCompany(m.DB.QueryRowContext(ctx, query, arg), &company)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &company, nil
+}
+
+// GetAll lists the companies matching the filters by name.
+func (m CompanyModel) GetAll(filters CompanyFilters) ([]*Company, error) {
+	query := `
+		SELECT ` + companyColumns + `
+		FROM companies c
+		WHERE ($1 = '' OR c.name ILIKE '%' || $1 || '%' OR c.industry ILIKE '%' || $1 || '%' OR c.description ILIKE '%' || $1 || '%')
+		AND ($2 = '' OR lower(c.industry) = lower($2))
+		AND ($3::text IS NULL OR c.size = $3)
+		ORDER BY c.name, c.id
+	`
+
+	args := []any{escapeLike(strings.TrimSpace(filters.Search)), strings.TrimSpace(filters.Industry), filters.Size}
+
+	return m.query(query, args...)
+}
+
+// GetAllByUserId returns the companies the user is a member of.
+func (m CompanyModel) GetAllByUserId(userId int64) ([]*Company, error) {
+	query := `
+		SELECT ` + companyColumns + `
+		FROM companies c
+		INNER JOIN company_members cm ON cm.company_id = c.id
+		WHERE cm.user_id = $1
+		ORDER BY c.name, c.id
+	`
+
+	return m.query(query, userId)
+}
+
+func (m CompanyModel) query(query string, args ...any) ([]*Company, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, args...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var companies []*Company
+
+	for rows.Next() {
+		var company Company
+
+		err := scanCompany(rows, &company)
+		if err != nil {
+			return nil, err
+		}
+
+		companies = append(companies, &company)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return companies, nil
+}
+
+func (m CompanyModel) Update(company *Company) error {
+	locations, err := json.Marshal(company.locations())
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE companies
+		SET name = $1, website_url = $2, logo_url = $3, description = $4, size = $5, industry = $6,
+			locations = $7::jsonb, updated_at = NOW(), version = version + 1
+		WHERE id = $8 AND version = $9
+		RETURNING updated_at, version
+	`
+
+	args := []any{
+		company.Name,
+		company.WebsiteUrl,
+		company.LogoUrl,
+		company.Description,
+		company.Size,
+		company.Industry,
+		locations,
+		company.ID,
+		company.Version,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err = m.DB.QueryRowContext(ctx, query, args...).Scan(&company.UpdatedAt, &company.Version)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrEditConflict
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+// locations never returns nil, so the column is stored as an empty array.
+func (c *Company) locations() []*CompanyLocation {
+	if c.Locations == nil {
+		return []*CompanyLocation{}
+	}
+
+	return c.Locations
+}
+
+func (m CompanyModel) GetMember(companyId int64, userId int64) (*CompanyMember, error) {
+	query := `
+		SELECT company_id, user_id, role, created_at
+		FROM company_members
+		WHERE company_id = $1 AND user_id = $2
+	`
+
+	var member CompanyMember
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, companyId, userId).Scan(
+		&member.CompanyId,
+		&member.UserId,
+		&member.Role,
+		&member.CreatedAt,
+	)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &member, nil
+}
+
+// GetMembers returns the members of the company, the owner first and then by seniority.
+func (m CompanyModel) GetMembers(companyId int64) ([]*CompanyMember, error) {
+	query := `
+		SELECT company_id, user_id, role, created_at
+		FROM company_members
+		WHERE company_id = $1
+		ORDER BY role = 'OWNER' DESC, created_at, user_id
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, companyId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var members []*CompanyMember
+
+	for rows.Next() {
+		var member CompanyMember
+		err := rows.Scan(
+			&member.CompanyId,
+			&member.UserId,
+			&member.Role,
+			&member.CreatedAt,
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		members = append(members, &member)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return members, nil
+}
+
+// UpdateMemberRole changes the role of a member other than the owner.
+func (m CompanyModel) UpdateMemberRole(member *CompanyMember) error {
+	query := `
+		UPDATE company_members
+		SET role = $1
+		WHERE company_id = $2 AND user_id = $3 AND role <> 'OWNER'
+		RETURNING created_at
+	`
+
+	args := []any{member.Role, member.CompanyId, member.UserId}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, args...).Scan(&member.CreatedAt)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrRecordNotFound
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DeleteMember removes a member other than the owner from the company.
+func (m CompanyModel) DeleteMember(companyId int64, userId int64) error {
+	query := `
+		DELETE FROM company_members
+		WHERE company_id = $1 AND user_id = $2 AND role <> 'OWNER'
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, companyId, userId)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// NewInvitation creates the invitation with a fresh token. Inviting the same email
+// again replaces the previous invitation, so only the last token sent works.
+func (m CompanyModel) NewInvitation(invitation *CompanyInvitation) error {
+	token, err := generateToken(invitation.InvitedBy, CompanyInvitationTTL, "")
+	if err != nil {
+		return err
+	}
+
+	invitation.Plaintext = token.Plaintext
+	invitation.Hash = token.Hash
+	invitation.Expiry = token.Expiry
+
+	query := `
+		INSERT INTO company_invitations (hash, company_id, email, role, invited_by, expiry)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (company_id, email) DO UPDATE
+		SET hash = EXCLUDED.hash, role = EXCLUDED.role, invited_by = EXCLUDED.invited_by,
+			created_at = NOW(), expiry = EXCLUDED.expiry
+		RETURNING created_at
+	`
+
+	args := []any{
+		invitation.Hash,
+		invitation.CompanyId,
+		invitation.Email,
+		invitation.Role,
+		invitation.InvitedBy,
+		invitation.Expiry,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	return m.DB.QueryRowContext(ctx, query, args...).Scan(&invitation.CreatedAt)
+}
+
+// AcceptInvitation makes the user a member of the company the token invites them to.
+// The invitation must be addressed to the user email and not be expired, it can only
+// be used once.
+func (m CompanyModel) AcceptInvitation(tokenPlaintext string, user *User) (*CompanyMember, error) {
+	hash := sha256.Sum256([]byte(tokenPlaintext))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	defer tx.Rollback()
+
+	query := `
+		DELETE FROM company_invitations
+		WHERE hash = $1 AND email = $2 AND expiry > NOW()
+		RETURNING company_id, role
+	`
+
+	member := CompanyMember{UserId: user.ID}
+
+	err = tx.QueryRowContext(ctx, query, hash[:], user.Email).Scan(&member.CompanyId, &member.Role)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	query = `
+		INSERT INTO company_members (company_id, user_id, role)
+		VALUES ($1, $2, $3)
+		RETURNING created_at
+	`
+
+	err = tx.QueryRowContext(ctx, query, member.CompanyId, member.UserId, member.Role).Scan(&member.CreatedAt)
+	if err != nil {
+		switch {
+		case err.Error() == `pq: duplicate key value violates unique constraint "company_members_pkey"`:
+			return nil, ErrAlreadyMember
+		default:
+			return nil, err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return &member, nil
+}
+
+// offerManagerSQL is the condition that the user, bound to the given parameter, manages
+// the offer with the given alias: they created it, or it belongs to a company they are
+// a member of. The offers of a company are managed by its members only, so removed
+// members lose access to the ones they created.
+func offerManagerSQL(alias string, param string) string {
+	return `(` + alias + `.company_id IS NULL AND ` + alias + `.user_id = ` + param + ` OR EXISTS (
+		SELECT 1 FROM company_members cm WHERE cm.company_id = ` + alias + `.company_id AND cm.user_id = ` + param + `
+	))`
+}
@@ -0,0 +1,224 @@
+package model
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"itfinder.adrianescat.com/internal/validator"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Education is an education entry of the profile history.
+type Education struct {
+	ID           int64      `json:"id"`
+	ProfileId    int64      `json:"profile_id"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"-"`
+	Institution  string     `json:"institution"`
+	Degree       string     `json:"degree"`
+	FieldOfStudy string     `json:"field_of_study"`
+	StartDate    time.Time  `json:"start_date"`
+	EndDate      *time.Time `json:"end_date"`
+	Current      bool       `json:"current"`
+	Description  string     `json:"description"`
+	Position     int        `json:"position"`
+	Version      int        `json:"version"`
+}
+
+type EducationModel struct {
+	DB *sql.DB
+}
+
+func ValidateEducation(v *validator.Validator, education *Education) {
+	v.Check(education.Institution != "", "institution", "must be provided")
+	v.Check(len(education.Institution) <= 150, "institution", "must not be more than 150 bytes long")
+
+	v.Check(education.Degree != "", "degree", "must be provided")
+	v.Check(len(education.Degree) <= 150, "degree", "must not be more than 150 bytes long")
+
+	v.Check(len(education.FieldOfStudy) <= 150, "fieldOfStudy", "must not be more than 150 bytes long")
+	v.Check(len(education.Description) <= 2000, "description", "must not be more than 2000 bytes long")
+
+	validateDateRange(v, education.StartDate, education.EndDate, education.Current)
+}
+
+// Insert adds the education at the end of the profile history.
+func (m EducationModel) Insert(education *Education) error {
+	query := `
+		INSERT INTO profile_educations (profile_id, institution, degree, field_of_study, start_date, end_date, current, description, position)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, (
+			SELECT COALESCE(max(position) + 1, 0) FROM profile_educations WHERE profile_id = $1
+		))
+		RETURNING id, created_at, updated_at, position, version
+	`
+
+	args := []any{
+		education.ProfileId,
+		education.Institution,
+		education.Degree,
+		education.FieldOfStudy,
+		education.StartDate,
+		education.EndDate,
+		education.Current,
+		education.Description,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	return m.DB.QueryRowContext(ctx, query, args...).Scan(
+		&education.ID,
+		&education.CreatedAt,
+		&education.UpdatedAt,
+		&education.Position,
+		&education.Version,
+	)
+}
+
+func (m EducationModel) Get(id int64) (*Education, error) {
+	if id < 1 {
+		return nil, ErrRecordNotFound
+	}
+
+	query := `
+		SELECT id, profile_id, created_at, updated_at, institution, degree, field_of_study, start_date, end_date, current, description, position, version
+		FROM profile_educations
+		WHERE id = $1
+	`
+
+	var education Education
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, id).Scan(
+		&education.ID,
+		&education.ProfileId,
+		&education.CreatedAt,
+		&education.UpdatedAt,
+		&education.Institution,
+		&education.Degree,
+		&education.FieldOfStudy,
+		&education.StartDate,
+		&education.EndDate,
+		&education.Current,
+		&education.Description,
+		&education.Position,
+		&education.Version,
+	)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &education, nil
+}
+
+// GetAllByProfileIds returns the educations of all the profiles, ordered by profile and
+// position.
+func (m EducationModel) GetAllByProfileIds(profileIds []int64) ([]*Education, error) {
+	query := `
+		SELECT id, profile_id, created_at, updated_at, institution, degree, field_of_study, start_date, end_date, current, description, position, version
+		FROM profile_educations
+		WHERE profile_id = ANY($1)
+		ORDER BY profile_id, position
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, pq.Array(profileIds))
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var educations []*Education
+
+	for rows.Next() {
+		var education Education
+		err := rows.Scan(
+			&education.ID,
+			&education.ProfileId,
+			&education.CreatedAt,
+			&education.UpdatedAt,
+			&education.Institution,
+			&education.Degree,
+			&education.FieldOfStudy,
+			&education.StartDate,
+			&education.EndDate,
+			&education.Current,
+			&education.Description,
+			&education.Position,
+			&education.Version,
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		educations = append(educations, &education)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return educations, nil
+}
+
+func (m EducationModel) Update(education *Education) error {
+	query := `
+		UPDATE profile_educations
+		SET institution = $1, degree = $2, field_of_study = $3, start_date = $4, end_date = $5, current = $6,
+			description = $7, updated_at = NOW(), version = version + 1
+		WHERE id = $8 AND version = $9
+		RETURNING updated_at, version
+	`
+
+	args := []any{
+		education.Institution,
+		education.Degree,
+		education.FieldOfStudy,
+		education.StartDate,
+		education.EndDate,
+		education.Current,
+		education.Description,
+		education.ID,
+		education.Version,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, args...).Scan(&education.UpdatedAt, &education.Version)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrEditConflict
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m EducationModel) Delete(id int64) error {
+	return deleteProfileEntry("profile_educations", m.DB, id)
+}
+
+// Reorder sets the position of every education of the profile to its index in ids,
+// which must contain all of them exactly once.
+func (m EducationModel) Reorder(profileId int64, ids []int64) error {
+	return reorderProfileEntries("profile_educations", m.DB, profileId, ids)
+}
@@ -0,0 +1,231 @@
+package model
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"itfinder.adrianescat.com/internal/validator"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// BaseCurrency is the currency every exchange rate is relative to.
+const BaseCurrency = "USD"
+
+// ExchangeRate is the amount of units of Currency that 1 unit of the BaseCurrency buys.
+type ExchangeRate struct {
+	Currency  string    `json:"currency"`
+	Rate      float64   `json:"rate"`
+	UpdatedAt time.Time `json:"updated_at"`
+	UpdatedBy *int64    `json:"-"`
+}
+
+// ExchangeRates maps currency codes to their rate against the BaseCurrency.
+type ExchangeRates map[string]float64
+
+type ExchangeRateModel struct {
+	DB *sql.DB
+}
+
+// Convert returns the amount expressed in the "to" currency, or false when the rate of
+// any of the currencies is unknown.
+func (r ExchangeRates) Convert(amount float64, from, to string) (float64, bool) {
+	from = validator.NormalizeCurrency(from)
+	to = validator.NormalizeCurrency(to)
+
+	if from == to {
+		return amount, true
+	}
+
+	fromRate, ok := r[from]
+	if !ok || fromRate <= 0 {
+		return 0, false
+	}
+
+	toRate, ok := r[to]
+	if !ok {
+		return 0, false
+	}
+
+	return amount / fromRate * toRate, true
+}
+
+func ValidateExchangeRates(v *validator.Validator, rates []*ExchangeRate) {
+	v.Check(len(rates) > 0, "rates", "must be provided")
+
+	currencies := make([]string, 0, len(rates))
+
+	for i, rate := range rates {
+		key := fmt.Sprintf("rates[%d]", i)
+
+		v.Check(validator.ValidCurrency(rate.Currency), key+".currency", "must be an ISO 4217 currency code")
+		v.Check(rate.Rate > 0, key+".rate", "must be greater than zero")
+
+		if rate.Currency == BaseCurrency {
+			v.Check(rate.Rate == 1, key+".rate", "must be 1 for the base currency")
+		}
+
+		currencies = append(currencies, rate.Currency)
+	}
+
+	v.Check(validator.Unique(currencies), "rates", "currencies must be unique")
+}
+
+// ParseExchangeRatesCSV reads "currency,rate" records. A first record whose rate is not
+// a number is treated as a header and skipped.
+func ParseExchangeRatesCSV(r io.Reader) ([]*ExchangeRate, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 2
+	reader.TrimLeadingSpace = true
+
+	var rates []*ExchangeRate
+
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		rate, err := strconv.ParseFloat(strings.TrimSpace(record[1]), 64)
+		if err != nil {
+			if line == 1 {
+				continue
+			}
+
+			return nil, fmt.Errorf("line %d: rate must be a number", line)
+		}
+
+		rates = append(rates, &ExchangeRate{
+			Currency: validator.NormalizeCurrency(record[0]),
+			Rate:     rate,
+		})
+	}
+
+	return rates, nil
+}
+
+// Upsert creates or replaces the rates in a single transaction.
+func (m ExchangeRateModel) Upsert(rates []*ExchangeRate, userId int64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO exchange_rates (currency, rate, updated_by)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (currency) DO UPDATE
+		SET rate = EXCLUDED.rate, updated_by = EXCLUDED.updated_by, updated_at = NOW()
+		RETURNING updated_at
+	`
+
+	for _, rate := range rates {
+		rate.UpdatedBy = &userId
+
+		err = tx.QueryRowContext(ctx, query, rate.Currency, rate.Rate, userId).Scan(&rate.UpdatedAt)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (m ExchangeRateModel) GetAll() ([]*ExchangeRate, error) {
+	query := `
+		SELECT currency, rate, updated_at, updated_by
+		FROM exchange_rates
+		ORDER BY currency
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var rates []*ExchangeRate
+
+	for rows.Next() {
+		var rate ExchangeRate
+		err := rows.Scan(
+			&rate.Currency,
+			&rate.Rate,
+			&rate.UpdatedAt,
+			&rate.UpdatedBy,
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		rates = append(rates, &rate)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return rates, nil
+}
+
+// GetRates returns the rates of the given currencies, unknown currencies are missing
+// from the result.
+func (m ExchangeRateModel) GetRates(currencies ...string) (ExchangeRates, error) {
+	query := `SELECT currency, rate FROM exchange_rates WHERE currency = ANY($1)`
+
+	for i := range currencies {
+		currencies[i] = validator.NormalizeCurrency(currencies[i])
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, pq.Array(currencies))
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	rates := make(ExchangeRates, len(currencies))
+
+	for rows.Next() {
+		var currency string
+		var rate float64
+
+		err := rows.Scan(&currency, &rate)
+		if err != nil {
+			return nil, err
+		}
+
+		rates[currency] = rate
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return rates, nil
+}
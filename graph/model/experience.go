@@ -0,0 +1,328 @@
+package model
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"itfinder.adrianescat.com/internal/validator"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+var (
+	ErrIncompleteOrder = errors.New("incomplete order")
+)
+
+// Experience is a work experience entry of the profile history.
+type Experience struct {
+	ID          int64      `json:"id"`
+	ProfileId   int64      `json:"profile_id"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"-"`
+	Company     string     `json:"company"`
+	Role        string     `json:"role"`
+	StartDate   time.Time  `json:"start_date"`
+	EndDate     *time.Time `json:"end_date"`
+	Current     bool       `json:"current"`
+	Description string     `json:"description"`
+	Position    int        `json:"position"`
+	Version     int        `json:"version"`
+}
+
+type ExperienceModel struct {
+	DB *sql.DB
+}
+
+func ValidateExperience(v *validator.Validator, experience *Experience) {
+	v.Check(experience.Company != "", "company", "must be provided")
+	v.Check(len(experience.Company) <= 150, "company", "must not be more than 150 bytes long")
+
+	v.Check(experience.Role != "", "role", "must be provided")
+	v.Check(len(experience.Role) <= 150, "role", "must not be more than 150 bytes long")
+
+	v.Check(len(experience.Description) <= 2000, "description", "must not be more than 2000 bytes long")
+
+	validateDateRange(v, experience.StartDate, experience.EndDate, experience.Current)
+}
+
+// validateDateRange checks the dates of a profile history entry. Current entries have no
+// end date, finished ones must end after they started, and no date is in the future.
+func validateDateRange(v *validator.Validator, start time.Time, end *time.Time, current bool) {
+	now := time.Now()
+
+	v.Check(!start.IsZero(), "startDate", "must be provided")
+	v.Check(!start.After(now), "startDate", "must not be in the future")
+
+	if current {
+		v.Check(end == nil, "endDate", "must not be provided for a current entry")
+		return
+	}
+
+	v.Check(end != nil, "endDate", "must be provided unless the entry is current")
+
+	if end != nil {
+		v.Check(!end.Before(start), "endDate", "must not be before startDate")
+		v.Check(!end.After(now), "endDate", "must not be in the future")
+	}
+}
+
+// Insert adds the experience at the end of the profile history.
+func (m ExperienceModel) Insert(experience *Experience) error {
+	query := `
+		INSERT INTO profile_experiences (profile_id, company, role, start_date, end_date, current, description, position)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, (
+			SELECT COALESCE(max(position) + 1, 0) FROM profile_experiences WHERE profile_id = $1
+		))
+		RETURNING id, created_at, updated_at, position, version
+	`
+
+	args := []any{
+		experience.ProfileId,
+		experience.Company,
+		experience.Role,
+		experience.StartDate,
+		experience.EndDate,
+		experience.Current,
+		experience.Description,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	return m.DB.QueryRowContext(ctx, query, args...).Scan(
+		&experience.ID,
+		&experience.CreatedAt,
+		&experience.UpdatedAt,
+		&experience.Position,
+		&experience.Version,
+	)
+}
+
+func (m ExperienceModel) Get(id int64) (*Experience, error) {
+	if id < 1 {
+		return nil, ErrRecordNotFound
+	}
+
+	query := `
+		SELECT id, profile_id, created_at, updated_at, company, role, start_date, end_date, current, description, position, version
+		FROM profile_experiences
+		WHERE id = $1
+	`
+
+	var experience Experience
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, id).Scan(
+		&experience.ID,
+		&experience.ProfileId,
+		&experience.CreatedAt,
+		&experience.UpdatedAt,
+		&experience.Company,
+		&experience.Role,
+		&experience.StartDate,
+		&experience.EndDate,
+		&experience.Current,
+		&experience.Description,
+		&experience.Position,
+		&experience.Version,
+	)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &experience, nil
+}
+
+// GetAllByProfileIds returns the experiences of all the profiles, ordered by profile and
+// position.
+func (m ExperienceModel) GetAllByProfileIds(profileIds []int64) ([]*Experience, error) {
+	query := `
+		SELECT id, profile_id, created_at, updated_at, company, role, start_date, end_date, current, description, position, version
+		FROM profile_experiences
+		WHERE profile_id = ANY($1)
+		ORDER BY profile_id, position
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, pq.Array(profileIds))
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var experiences []*Experience
+
+	for rows.Next() {
+		var experience Experience
+		err := rows.Scan(
+			&experience.ID,
+			&experience.ProfileId,
+			&experience.CreatedAt,
+			&experience.UpdatedAt,
+			&experience.Company,
+			&experience.Role,
+			&experience.StartDate,
+			&experience.EndDate,
+			&experience.Current,
+			&experience.Description,
+			&experience.Position,
+			&experience.Version,
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		experiences = append(experiences, &experience)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return experiences, nil
+}
+
+func (m ExperienceModel) Update(experience *Experience) error {
+	query := `
+		UPDATE profile_experiences
+		SET company = $1, role = $2, start_date = $3, end_date = $4, current = $5, description = $6,
+			updated_at = NOW(), version = version + 1
+		WHERE id = $7 AND version = $8
+		RETURNING updated_at, version
+	`
+
+	args := []any{
+		experience.Company,
+		experience.Role,
+		experience.StartDate,
+		experience.EndDate,
+		experience.Current,
+		experience.Description,
+		experience.ID,
+		experience.Version,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, args...).Scan(&experience.UpdatedAt, &experience.Version)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrEditConflict
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m ExperienceModel) Delete(id int64) error {
+	return deleteProfileEntry("profile_experiences", m.DB, id)
+}
+
+// Reorder sets the position of every experience of the profile to its index in ids,
+// which must contain all of them exactly once.
+func (m ExperienceModel) Reorder(profileId int64, ids []int64) error {
+	return reorderProfileEntries("profile_experiences", m.DB, profileId, ids)
+}
+
+func deleteProfileEntry(table string, db *sql.DB, id int64) error {
+	query := `DELETE FROM ` + table + ` WHERE id = $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	result, err := db.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func reorderProfileEntries(table string, db *sql.DB, profileId int64, ids []int64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	// The entries are locked so a concurrent insert or reorder can't interleave with the
+	// completeness check.
+	query := fmt.Sprintf(`SELECT id FROM %s WHERE profile_id = $1 FOR UPDATE`, table)
+
+	rows, err := tx.QueryContext(ctx, query, profileId)
+	if err != nil {
+		return err
+	}
+
+	existing := make(map[int64]bool)
+
+	for rows.Next() {
+		var id int64
+
+		err := rows.Scan(&id)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+
+		existing[id] = true
+	}
+
+	if err = rows.Err(); err != nil {
+		rows.Close()
+		return err
+	}
+
+	rows.Close()
+
+	if len(ids) != len(existing) || !validator.Unique(ids) {
+		return ErrIncompleteOrder
+	}
+
+	query = fmt.Sprintf(`UPDATE %s SET position = $1, updated_at = NOW(), version = version + 1 WHERE id = $2`, table)
+
+	for position, id := range ids {
+		if !existing[id] {
+			return ErrIncompleteOrder
+		}
+
+		_, err = tx.ExecContext(ctx, query, position, id)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,40 @@
+package model
+
+import (
+	"itfinder.adrianescat.com/internal/validator"
+	"strings"
+)
+
+// Filters holds the sorting parameters of the listing queries. SortSafelist contains
+// the values Sort is allowed to take, a leading "-" means descending order.
+type Filters struct {
+	Sort         string
+	SortSafelist []string
+}
+
+func ValidateFilters(v *validator.Validator, f Filters) {
+	v.Check(validator.PermittedValue(f.Sort, f.SortSafelist...), "sort", "invalid sort value")
+}
+
+// sortColumn checks that the client-provided Sort field matches one of the entries in
+// our safelist and if it does, extracts the column name from the Sort field by
+// stripping the leading hyphen character (if one exists).
+func (f Filters) sortColumn() string {
+	for _, safeValue := range f.SortSafelist {
+		if f.Sort == safeValue {
+			return strings.TrimPrefix(f.Sort, "-")
+		}
+	}
+
+	panic("unsafe sort parameter: " + f.Sort)
+}
+
+// sortDirection returns the sort direction ("ASC" or "DESC") depending on the prefix
+// character of the Sort field.
+func (f Filters) sortDirection() string {
+	if strings.HasPrefix(f.Sort, "-") {
+		return "DESC"
+	}
+
+	return "ASC"
+}
@@ -0,0 +1,293 @@
+package model
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"itfinder.adrianescat.com/internal/geo"
+	"itfinder.adrianescat.com/internal/validator"
+	"time"
+)
+
+type LocationModel struct {
+	DB *sql.DB
+}
+
+// Seed upserts the countries and subdivisions of the embedded reference.
+func (m LocationModel) Seed() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO countries (code, name)
+		VALUES ($1, $2)
+		ON CONFLICT (code) DO UPDATE SET name = EXCLUDED.name
+	`
+
+	for _, c := range geo.Countries() {
+		_, err = tx.ExecContext(ctx, query, c.Code, c.Name)
+		if err != nil {
+			return err
+		}
+
+		for _, s := range geo.Subdivisions(c.Code) {
+			_, err = tx.ExecContext(ctx, `
+				INSERT INTO subdivisions (code, country_code, name)
+				VALUES ($1, $2, $3)
+				ON CONFLICT (code) DO UPDATE SET country_code = EXCLUDED.country_code, name = EXCLUDED.name
+			`, s.Code, s.CountryCode, s.Name)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return tx.Commit()
+}
+
+// NormalizeProfiles rewrites the country and state of the existing profiles to their
+// ISO 3166 codes and resolves their coordinates, and returns how many profiles were
+// updated. Values that can't be matched with the reference are left untouched.
+func (m LocationModel) NormalizeProfiles() (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `SELECT id, country, state, city, latitude, longitude FROM profiles FOR UPDATE`)
+	if err != nil {
+		return 0, err
+	}
+
+	type location struct {
+		id        int64
+		country   string
+		state     string
+		city      string
+		latitude  *float64
+		longitude *float64
+	}
+
+	var changed []location
+
+	for rows.Next() {
+		var l location
+
+		err := rows.Scan(&l.id, &l.country, &l.state, &l.city, &l.latitude, &l.longitude)
+		if err != nil {
+			rows.Close()
+			return 0, err
+		}
+
+		n := l
+		n.country = geo.NormalizeCountry(l.country)
+		n.state = geo.NormalizeSubdivision(n.country, l.state)
+		n.latitude, n.longitude = Geocode(n.country, n.state, n.city)
+
+		if n.country != l.country || n.state != l.state || !sameCoordinate(n.latitude, l.latitude) || !sameCoordinate(n.longitude, l.longitude) {
+			changed = append(changed, n)
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		rows.Close()
+		return 0, err
+	}
+
+	rows.Close()
+
+	query := `
+		UPDATE profiles
+		SET country = $1, state = $2, latitude = $3, longitude = $4, version = version + 1
+		WHERE id = $5
+	`
+
+	for _, l := range changed {
+		_, err = tx.ExecContext(ctx, query, l.country, l.state, l.latitude, l.longitude, l.id)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 0, err
+	}
+
+	return len(changed), nil
+}
+
+// GeocodeOffers resolves the coordinates of the existing offers from their location,
+// and returns how many offers were updated.
+func (m LocationModel) GeocodeOffers() (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, COALESCE(country, ''), COALESCE(state, ''), COALESCE(city, ''), latitude, longitude
+		FROM offers
+		FOR UPDATE
+	`)
+	if err != nil {
+		return 0, err
+	}
+
+	type coordinates struct {
+		id        int64
+		latitude  *float64
+		longitude *float64
+	}
+
+	var changed []coordinates
+
+	for rows.Next() {
+		var id int64
+		var country, state, city string
+		var latitude, longitude *float64
+
+		err := rows.Scan(&id, &country, &state, &city, &latitude, &longitude)
+		if err != nil {
+			rows.Close()
+			return 0, err
+		}
+
+		lat, lon := Geocode(country, state, city)
+
+		if !sameCoordinate(lat, latitude) || !sameCoordinate(lon, longitude) {
+			changed = append(changed, coordinates{id: id, latitude: lat, longitude: lon})
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		rows.Close()
+		return 0, err
+	}
+
+	rows.Close()
+
+	for _, c := range changed {
+		_, err = tx.ExecContext(ctx, `UPDATE offers SET latitude = $1, longitude = $2 WHERE id = $3`, c.latitude, c.longitude, c.id)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 0, err
+	}
+
+	return len(changed), nil
+}
+
+// Geocode returns the coordinates of the city from the embedded gazetteer, or nil when
+// the city is unknown.
+func Geocode(country string, state string, city string) (*float64, *float64) {
+	if country == "" || city == "" {
+		return nil, nil
+	}
+
+	c, ok := geo.LookupCity(country, state, city)
+	if !ok {
+		return nil, nil
+	}
+
+	return &c.Latitude, &c.Longitude
+}
+
+func sameCoordinate(a, b *float64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return *a == *b
+}
+
+// GeoFilter narrows a listing to the rows within RadiusKm of a point.
+type GeoFilter struct {
+	Latitude  float64
+	Longitude float64
+	RadiusKm  float64
+}
+
+// MaxRadiusKm is the largest radius of a proximity search.
+const MaxRadiusKm = 1000
+
+func ValidateGeoFilter(v *validator.Validator, f *GeoFilter) {
+	v.Check(f.RadiusKm > 0, "near.radiusKm", "must be greater than zero")
+	v.Check(f.RadiusKm <= MaxRadiusKm, "near.radiusKm", fmt.Sprintf("must not be greater than %d", MaxRadiusKm))
+}
+
+// args returns the query arguments of the filter: the centre, the radius and the
+// bounding box. They are all nil without a filter, which disables it in the query.
+func (f *GeoFilter) args() []any {
+	if f == nil {
+		return []any{nil, nil, nil, nil, nil, nil, nil}
+	}
+
+	minLat, maxLat, minLon, maxLon := geo.BoundingBox(f.Latitude, f.Longitude, f.RadiusKm)
+
+	return []any{f.Latitude, f.Longitude, f.RadiusKm, minLat, maxLat, minLon, maxLon}
+}
+
+// geoFilterSQL returns the lateral join that computes the haversine distance, as d.km,
+// and the condition that keeps the rows within the radius. The bounding box is checked
+// first so the coordinates index can be used. The filter arguments start at $first.
+func geoFilterSQL(alias string, first int) (join string, where string) {
+	p := func(i int) string { return fmt.Sprintf("$%d", first+i) }
+
+	join = fmt.Sprintf(`LEFT JOIN LATERAL (
+			SELECT %[7]g * 2 * asin(least(1, sqrt(
+				power(sin(radians(%[1]s.latitude - %[2]s) / 2), 2)
+				+ cos(radians(%[2]s)) * cos(radians(%[1]s.latitude)) * power(sin(radians(%[1]s.longitude - %[3]s) / 2), 2)
+			))) AS km
+			WHERE %[2]s::float8 IS NOT NULL AND %[1]s.latitude IS NOT NULL
+		) d ON true`, alias, p(0), p(1), p(2), p(3), p(4), geo.EarthRadiusKm)
+
+	where = fmt.Sprintf(`(%[2]s::float8 IS NULL OR (
+			%[1]s.latitude BETWEEN %[4]s AND %[5]s AND %[1]s.longitude BETWEEN %[6]s AND %[7]s AND d.km <= %[3]s
+		))`, alias, p(0), p(2), p(3), p(4), p(5), p(6))
+
+	return join, where
+}
+
+// ValidateLocation checks the country against the ISO 3166-1 codes and, for the
+// countries whose subdivisions are known, the state against the ISO 3166-2 codes.
+// The state and the city can be left empty when optional is set.
+func ValidateLocation(v *validator.Validator, country string, state string, city string, optional bool) {
+	v.Check(country != "", "country", "must be provided")
+	v.Check(geo.ValidCountry(country), "country", "must be an ISO 3166-1 alpha-2 country code")
+
+	if !optional || state != "" {
+		v.Check(state != "", "state", "must be provided")
+		v.Check(len(state) <= 50, "state", "must not be more than 50 bytes long")
+
+		if geo.HasSubdivisions(country) {
+			v.Check(geo.ValidSubdivision(country, state), "state", "must be an ISO 3166-2 subdivision code of the country")
+		}
+	}
+
+	if !optional || city != "" {
+		v.Check(city != "", "city", "must be provided")
+		v.Check(len(city) <= 100, "city", "must not be more than 100 bytes long")
+	}
+}
@@ -11,17 +11,39 @@ var (
 )
 
 type Models struct {
-	Users    UserModel
-	Offers   OfferModel
-	Profiles ProfileModel
-	Tokens   TokenModel
+	Users          UserModel
+	Offers         OfferModel
+	Profiles       ProfileModel
+	Tokens         TokenModel
+	Applications   ApplicationModel
+	Bookmarks      BookmarkModel
+	SavedSearches  SavedSearchModel
+	ExchangeRates  ExchangeRateModel
+	SalaryInsights SalaryInsightModel
+	Skills         SkillModel
+	Experiences    ExperienceModel
+	Educations     EducationModel
+	Resumes        ResumeModel
+	Companies      CompanyModel
+	Moderation     ModerationModel
 }
 
 func NewModels(db *sql.DB) Models {
 	return Models{
-		Users:    UserModel{DB: db},
-		Offers:   OfferModel{DB: db},
-		Profiles: ProfileModel{DB: db},
-		Tokens:   TokenModel{DB: db},
+		Users:          UserModel{DB: db},
+		Offers:         OfferModel{DB: db},
+		Profiles:       ProfileModel{DB: db},
+		Tokens:         TokenModel{DB: db},
+		Applications:   ApplicationModel{DB: db},
+		Bookmarks:      BookmarkModel{DB: db},
+		SavedSearches:  SavedSearchModel{DB: db},
+		ExchangeRates:  ExchangeRateModel{DB: db},
+		SalaryInsights: SalaryInsightModel{DB: db, cache: newSalaryInsightsCache()},
+		Skills:         SkillModel{DB: db},
+		Experiences:    ExperienceModel{DB: db},
+		Educations:     EducationModel{DB: db},
+		Resumes:        ResumeModel{DB: db},
+		Companies:      CompanyModel{DB: db},
+		Moderation:     ModerationModel{DB: db},
 	}
 }
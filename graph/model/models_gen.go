@@ -3,11 +3,20 @@
 package model
 
 import (
+	"fmt"
+	"io"
+	"strconv"
 	"time"
 )
 
+type ApplicationAnswerInput struct {
+	QuestionID string `json:"questionId"`
+	Answer     string `json:"answer"`
+}
+
 type ApplyResponse struct {
-	Success bool `json:"success"`
+	Success     bool         `json:"success"`
+	Application *Application `json:"application"`
 }
 
 type AuthToken struct {
@@ -28,16 +37,86 @@ type BookmarkResponse struct {
 	Success bool `json:"success"`
 }
 
+type CompanyInput struct {
+	Name        string                  `json:"name"`
+	WebsiteURL  *string                 `json:"websiteUrl"`
+	LogoURL     *string                 `json:"logoUrl"`
+	Description *string                 `json:"description"`
+	Size        *CompanySize            `json:"size"`
+	Industry    *string                 `json:"industry"`
+	Locations   []*CompanyLocationInput `json:"locations"`
+}
+
+type CompanyLocationInput struct {
+	Country string  `json:"country"`
+	State   *string `json:"state"`
+	City    *string `json:"city"`
+}
+
+type CompanyResponse struct {
+	Success bool `json:"success"`
+}
+
+type Country struct {
+	Code string `json:"code"`
+	Name string `json:"name"`
+}
+
+type DeleteAccountResponse struct {
+	Success bool      `json:"success"`
+	PurgeAt time.Time `json:"purgeAt"`
+}
+
+type EducationInput struct {
+	Institution  string     `json:"institution"`
+	Degree       string     `json:"degree"`
+	FieldOfStudy *string    `json:"fieldOfStudy"`
+	StartDate    time.Time  `json:"startDate"`
+	EndDate      *time.Time `json:"endDate"`
+	Current      bool       `json:"current"`
+	Description  *string    `json:"description"`
+}
+
+type ExchangeRateInput struct {
+	Currency string  `json:"currency"`
+	Rate     float64 `json:"rate"`
+}
+
+type ExperienceInput struct {
+	Company     string     `json:"company"`
+	Role        string     `json:"role"`
+	StartDate   time.Time  `json:"startDate"`
+	EndDate     *time.Time `json:"endDate"`
+	Current     bool       `json:"current"`
+	Description *string    `json:"description"`
+}
+
 type LogoutResponse struct {
 	Success bool `json:"success"`
 }
 
+type NearInput struct {
+	Country  string  `json:"country"`
+	State    *string `json:"state"`
+	City     string  `json:"city"`
+	RadiusKm float64 `json:"radiusKm"`
+}
+
 type NewOfferInput struct {
-	UserID      string          `json:"userId"`
-	Title       string          `json:"title"`
-	Description string          `json:"description"`
-	Salary      []*SalaryByRole `json:"salary"`
-	PictureURL  string          `json:"pictureUrl"`
+	UserID      string                `json:"userId"`
+	Title       string                `json:"title"`
+	Description string                `json:"description"`
+	Salary      []*SalaryByRole       `json:"salary"`
+	PictureURL  *string               `json:"pictureUrl"`
+	Questions   []*OfferQuestionInput `json:"questions"`
+	Country     *string               `json:"country"`
+	State       *string               `json:"state"`
+	City        *string               `json:"city"`
+	WorkMode    WorkMode              `json:"workMode"`
+	CompanyID   *string               `json:"companyId"`
+	PublishAt   *time.Time            `json:"publishAt"`
+	ExpiresAt   *time.Time            `json:"expiresAt"`
+	Status      OfferStatus           `json:"status"`
 }
 
 type NewProfileInput struct {
@@ -48,7 +127,7 @@ type NewProfileInput struct {
 	Country    string          `json:"country"`
 	State      string          `json:"state"`
 	City       string          `json:"city"`
-	PictureURL string          `json:"pictureUrl"`
+	PictureURL *string         `json:"pictureUrl"`
 	WebsiteURL string          `json:"websiteUrl"`
 	Salary     []*SalaryByRole `json:"salary"`
 }
@@ -61,16 +140,532 @@ type NewUserInput struct {
 	Role     string `json:"role"`
 }
 
+type OfferFieldChange struct {
+	Field string  `json:"field"`
+	From  *string `json:"from"`
+	To    *string `json:"to"`
+}
+
+type OfferQuestionInput struct {
+	Question string   `json:"question"`
+	Kind     string   `json:"kind"`
+	Options  []string `json:"options"`
+	Required *bool    `json:"required"`
+}
+
+type OfferRecommendation struct {
+	Offer *Offer `json:"offer"`
+	Score int    `json:"score"`
+}
+
+type OfferSkillInput struct {
+	Skill    string `json:"skill"`
+	Required bool   `json:"required"`
+}
+
+type ProfileHistoryResponse struct {
+	Success bool `json:"success"`
+}
+
+type ProfilePrivacyInput struct {
+	Visibility       ProfileVisibility `json:"visibility"`
+	HiddenCompanyIds []string          `json:"hiddenCompanyIds"`
+}
+
+type ProfileRecommendation struct {
+	Profile *Profile `json:"profile"`
+	Score   int      `json:"score"`
+}
+
+type ProfileSkillInput struct {
+	Skill string `json:"skill"`
+	Years int    `json:"years"`
+	Level string `json:"level"`
+}
+
+type ReportResponse struct {
+	Success bool `json:"success"`
+}
+
 type SalaryByRole struct {
-	Title    string  `json:"title"`
-	Min      float64 `json:"min"`
-	Max      float64 `json:"max"`
-	Currency string  `json:"currency"`
+	Title    string       `json:"title"`
+	Min      float64      `json:"min"`
+	Max      float64      `json:"max"`
+	Currency string       `json:"currency"`
+	Period   SalaryPeriod `json:"period"`
+	Type     SalaryType   `json:"type"`
 }
 
 type SalaryByRoleResult struct {
-	Title    string  `json:"title"`
-	Min      float64 `json:"min"`
-	Max      float64 `json:"max"`
-	Currency string  `json:"currency"`
+	Title    string              `json:"title"`
+	Min      float64             `json:"min"`
+	Max      float64             `json:"max"`
+	Currency string              `json:"currency"`
+	Period   SalaryPeriod        `json:"period"`
+	Type     SalaryType          `json:"type"`
+	SalaryIn *SalaryByRoleResult `json:"salaryIn"`
+}
+
+type SavedSearchInput struct {
+	Name      string   `json:"name"`
+	Keywords  *string  `json:"keywords"`
+	MinSalary *float64 `json:"minSalary"`
+	MaxSalary *float64 `json:"maxSalary"`
+	Currency  *string  `json:"currency"`
+	Location  *string  `json:"location"`
+	Frequency string   `json:"frequency"`
+}
+
+type SavedSearchResponse struct {
+	Success bool `json:"success"`
+}
+
+type Subdivision struct {
+	Code        string `json:"code"`
+	CountryCode string `json:"countryCode"`
+	Name        string `json:"name"`
+}
+
+type UpdateOfferInput struct {
+	Title       *string         `json:"title"`
+	Description *string         `json:"description"`
+	Salary      []*SalaryByRole `json:"salary"`
+}
+
+type CompanyRole string
+
+const (
+	CompanyRoleOwner     CompanyRole = "OWNER"
+	CompanyRoleAdmin     CompanyRole = "ADMIN"
+	CompanyRoleRecruiter CompanyRole = "RECRUITER"
+)
+
+var AllCompanyRole = []CompanyRole{
+	CompanyRoleOwner,
+	CompanyRoleAdmin,
+	CompanyRoleRecruiter,
+}
+
+func (e CompanyRole) IsValid() bool {
+	switch e {
+	case CompanyRoleOwner, CompanyRoleAdmin, CompanyRoleRecruiter:
+		return true
+	}
+	return false
+}
+
+func (e CompanyRole) String() string {
+	return string(e)
+}
+
+func (e *CompanyRole) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = CompanyRole(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid CompanyRole", str)
+	}
+	return nil
+}
+
+func (e CompanyRole) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type CompanySize string
+
+const (
+	CompanySizeTiny       CompanySize = "TINY"
+	CompanySizeSmall      CompanySize = "SMALL"
+	CompanySizeMedium     CompanySize = "MEDIUM"
+	CompanySizeLarge      CompanySize = "LARGE"
+	CompanySizeEnterprise CompanySize = "ENTERPRISE"
+)
+
+var AllCompanySize = []CompanySize{
+	CompanySizeTiny,
+	CompanySizeSmall,
+	CompanySizeMedium,
+	CompanySizeLarge,
+	CompanySizeEnterprise,
+}
+
+func (e CompanySize) IsValid() bool {
+	switch e {
+	case CompanySizeTiny, CompanySizeSmall, CompanySizeMedium, CompanySizeLarge, CompanySizeEnterprise:
+		return true
+	}
+	return false
+}
+
+func (e CompanySize) String() string {
+	return string(e)
+}
+
+func (e *CompanySize) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = CompanySize(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid CompanySize", str)
+	}
+	return nil
+}
+
+func (e CompanySize) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type ModerationAction string
+
+const (
+	ModerationActionApprove ModerationAction = "APPROVE"
+	ModerationActionReject  ModerationAction = "REJECT"
+	ModerationActionBan     ModerationAction = "BAN"
+)
+
+var AllModerationAction = []ModerationAction{
+	ModerationActionApprove,
+	ModerationActionReject,
+	ModerationActionBan,
+}
+
+func (e ModerationAction) IsValid() bool {
+	switch e {
+	case ModerationActionApprove, ModerationActionReject, ModerationActionBan:
+		return true
+	}
+	return false
+}
+
+func (e ModerationAction) String() string {
+	return string(e)
+}
+
+func (e *ModerationAction) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ModerationAction(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ModerationAction", str)
+	}
+	return nil
+}
+
+func (e ModerationAction) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type ModerationStatus string
+
+const (
+	ModerationStatusApproved ModerationStatus = "APPROVED"
+	ModerationStatusPending  ModerationStatus = "PENDING"
+	ModerationStatusRejected ModerationStatus = "REJECTED"
+	ModerationStatusBanned   ModerationStatus = "BANNED"
+)
+
+var AllModerationStatus = []ModerationStatus{
+	ModerationStatusApproved,
+	ModerationStatusPending,
+	ModerationStatusRejected,
+	ModerationStatusBanned,
+}
+
+func (e ModerationStatus) IsValid() bool {
+	switch e {
+	case ModerationStatusApproved, ModerationStatusPending, ModerationStatusRejected, ModerationStatusBanned:
+		return true
+	}
+	return false
+}
+
+func (e ModerationStatus) String() string {
+	return string(e)
+}
+
+func (e *ModerationStatus) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ModerationStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ModerationStatus", str)
+	}
+	return nil
+}
+
+func (e ModerationStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type OfferStatus string
+
+const (
+	OfferStatusDraft     OfferStatus = "DRAFT"
+	OfferStatusPublished OfferStatus = "PUBLISHED"
+	OfferStatusClosed    OfferStatus = "CLOSED"
+	OfferStatusArchived  OfferStatus = "ARCHIVED"
+)
+
+var AllOfferStatus = []OfferStatus{
+	OfferStatusDraft,
+	OfferStatusPublished,
+	OfferStatusClosed,
+	OfferStatusArchived,
+}
+
+func (e OfferStatus) IsValid() bool {
+	switch e {
+	case OfferStatusDraft, OfferStatusPublished, OfferStatusClosed, OfferStatusArchived:
+		return true
+	}
+	return false
+}
+
+func (e OfferStatus) String() string {
+	return string(e)
+}
+
+func (e *OfferStatus) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = OfferStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid OfferStatus", str)
+	}
+	return nil
+}
+
+func (e OfferStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type ProfileVisibility string
+
+const (
+	ProfileVisibilityPublic     ProfileVisibility = "PUBLIC"
+	ProfileVisibilityRecruiters ProfileVisibility = "RECRUITERS"
+	ProfileVisibilityPrivate    ProfileVisibility = "PRIVATE"
+)
+
+var AllProfileVisibility = []ProfileVisibility{
+	ProfileVisibilityPublic,
+	ProfileVisibilityRecruiters,
+	ProfileVisibilityPrivate,
+}
+
+func (e ProfileVisibility) IsValid() bool {
+	switch e {
+	case ProfileVisibilityPublic, ProfileVisibilityRecruiters, ProfileVisibilityPrivate:
+		return true
+	}
+	return false
+}
+
+func (e ProfileVisibility) String() string {
+	return string(e)
+}
+
+func (e *ProfileVisibility) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ProfileVisibility(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ProfileVisibility", str)
+	}
+	return nil
+}
+
+func (e ProfileVisibility) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type ReportReason string
+
+const (
+	ReportReasonSpam          ReportReason = "SPAM"
+	ReportReasonScam          ReportReason = "SCAM"
+	ReportReasonInappropriate ReportReason = "INAPPROPRIATE"
+	ReportReasonOther         ReportReason = "OTHER"
+)
+
+var AllReportReason = []ReportReason{
+	ReportReasonSpam,
+	ReportReasonScam,
+	ReportReasonInappropriate,
+	ReportReasonOther,
+}
+
+func (e ReportReason) IsValid() bool {
+	switch e {
+	case ReportReasonSpam, ReportReasonScam, ReportReasonInappropriate, ReportReasonOther:
+		return true
+	}
+	return false
+}
+
+func (e ReportReason) String() string {
+	return string(e)
+}
+
+func (e *ReportReason) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ReportReason(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ReportReason", str)
+	}
+	return nil
+}
+
+func (e ReportReason) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type SalaryPeriod string
+
+const (
+	SalaryPeriodHourly  SalaryPeriod = "HOURLY"
+	SalaryPeriodMonthly SalaryPeriod = "MONTHLY"
+	SalaryPeriodYearly  SalaryPeriod = "YEARLY"
+)
+
+var AllSalaryPeriod = []SalaryPeriod{
+	SalaryPeriodHourly,
+	SalaryPeriodMonthly,
+	SalaryPeriodYearly,
+}
+
+func (e SalaryPeriod) IsValid() bool {
+	switch e {
+	case SalaryPeriodHourly, SalaryPeriodMonthly, SalaryPeriodYearly:
+		return true
+	}
+	return false
+}
+
+func (e SalaryPeriod) String() string {
+	return string(e)
+}
+
+func (e *SalaryPeriod) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = SalaryPeriod(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid SalaryPeriod", str)
+	}
+	return nil
+}
+
+func (e SalaryPeriod) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type SalaryType string
+
+const (
+	SalaryTypeGross SalaryType = "GROSS"
+	SalaryTypeNet   SalaryType = "NET"
+)
+
+var AllSalaryType = []SalaryType{
+	SalaryTypeGross,
+	SalaryTypeNet,
+}
+
+func (e SalaryType) IsValid() bool {
+	switch e {
+	case SalaryTypeGross, SalaryTypeNet:
+		return true
+	}
+	return false
+}
+
+func (e SalaryType) String() string {
+	return string(e)
+}
+
+func (e *SalaryType) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = SalaryType(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid SalaryType", str)
+	}
+	return nil
+}
+
+func (e SalaryType) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type WorkMode string
+
+const (
+	WorkModeRemote WorkMode = "REMOTE"
+	WorkModeHybrid WorkMode = "HYBRID"
+	WorkModeOnsite WorkMode = "ONSITE"
+)
+
+var AllWorkMode = []WorkMode{
+	WorkModeRemote,
+	WorkModeHybrid,
+	WorkModeOnsite,
+}
+
+func (e WorkMode) IsValid() bool {
+	switch e {
+	case WorkModeRemote, WorkModeHybrid, WorkModeOnsite:
+		return true
+	}
+	return false
+}
+
+func (e WorkMode) String() string {
+	return string(e)
+}
+
+func (e *WorkMode) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = WorkMode(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid WorkMode", str)
+	}
+	return nil
+}
+
+func (e WorkMode) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
 }
@@ -0,0 +1,313 @@
+package model
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"itfinder.adrianescat.com/internal/validator"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+var ErrDuplicateReport = errors.New("duplicate report")
+
+// listedModerationSQL is the condition on the moderation status of the offers and the
+// profiles shown in the listings.
+const listedModerationSQL = "moderation_status IN ('APPROVED', 'PENDING')"
+
+// moderationActionStatuses is the moderation status each decision leaves the content in.
+var moderationActionStatuses = map[ModerationAction]ModerationStatus{
+	ModerationActionApprove: ModerationStatusApproved,
+	ModerationActionReject:  ModerationStatusRejected,
+	ModerationActionBan:     ModerationStatusBanned,
+}
+
+// Report is the complaint of a user about an offer or a profile, only one of OfferId and
+// ProfileId is set.
+type Report struct {
+	ID        int64        `json:"id"`
+	UserId    int64        `json:"user_id"`
+	OfferId   *int64       `json:"offer_id"`
+	ProfileId *int64       `json:"profile_id"`
+	Reason    ReportReason `json:"reason"`
+	Details   string       `json:"details"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+// ModerationItem is an offer or a profile with open reports.
+type ModerationItem struct {
+	OfferId      *int64           `json:"offer_id"`
+	ProfileId    *int64           `json:"profile_id"`
+	Status       ModerationStatus `json:"status"`
+	ReportsCount int              `json:"reports_count"`
+	Reports      []*Report        `json:"reports"`
+}
+
+// ModerationDecision is the action of a moderator on an offer or a profile.
+type ModerationDecision struct {
+	ID          int64            `json:"id"`
+	ModeratorId *int64           `json:"moderator_id"`
+	OfferId     *int64           `json:"offer_id"`
+	ProfileId   *int64           `json:"profile_id"`
+	Action      ModerationAction `json:"action"`
+	Note        string           `json:"note"`
+	CreatedAt   time.Time        `json:"created_at"`
+}
+
+type ModerationModel struct {
+	DB *sql.DB
+}
+
+// IsListed reports whether the content with the moderation status is shown in the
+// listings and to the users that don't manage it.
+func (s ModerationStatus) IsListed() bool {
+	return s == ModerationStatusApproved || s == ModerationStatusPending
+}
+
+func ValidateReport(v *validator.Validator, report *Report) {
+	v.Check(report.Reason.IsValid(), "reason", "must be a valid report reason")
+	v.Check(len(report.Details) <= 1000, "details", "must not be more than 1000 bytes long")
+}
+
+// ValidateModerationDecision checks the decision on content currently in the status. A ban
+// is for good, so banned content can't be decided on again.
+func ValidateModerationDecision(v *validator.Validator, decision *ModerationDecision, status ModerationStatus) {
+	v.Check(decision.ProfileId != nil || decision.OfferId != nil, "decision", "a profileId or an offerId must be provided")
+	v.Check(decision.ProfileId == nil || decision.OfferId == nil, "decision", "only one of profileId and offerId must be provided")
+	v.Check(decision.Action.IsValid(), "action", "must be a valid moderation action")
+	v.Check(len(decision.Note) <= 1000, "note", "must not be more than 1000 bytes long")
+	v.Check(status != ModerationStatusBanned, "decision", "the content is banned")
+}
+
+// moderationTarget returns the table of the offer or the profile, the column of the
+// reports pointing to it and its id.
+func moderationTarget(offerId *int64, profileId *int64) (string, string, int64) {
+	if offerId != nil {
+		return "offers", "offer_id", *offerId
+	}
+
+	return "profiles", "profile_id", *profileId
+}
+
+// InsertReport records the report and puts the approved content back in the queue.
+func (m ModerationModel) InsertReport(report *Report) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO reports (user_id, offer_id, profile_id, reason, details)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`
+
+	args := []any{report.UserId, report.OfferId, report.ProfileId, report.Reason, report.Details}
+
+	err = tx.QueryRowContext(ctx, query, args...).Scan(&report.ID, &report.CreatedAt)
+	if err != nil {
+		switch {
+		case err.Error() == `pq: duplicate key value violates unique constraint "reports_open_user_id_offer_id_idx"`,
+			err.Error() == `pq: duplicate key value violates unique constraint "reports_open_user_id_profile_id_idx"`:
+			return ErrDuplicateReport
+		default:
+			return err
+		}
+	}
+
+	table, _, id := moderationTarget(report.OfferId, report.ProfileId)
+
+	_, err = tx.ExecContext(ctx, `UPDATE `+table+` SET moderation_status = 'PENDING' WHERE id = $1 AND moderation_status = 'APPROVED'`, id)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetStatus returns the moderation status of the offer or the profile.
+func (m ModerationModel) GetStatus(offerId *int64, profileId *int64) (ModerationStatus, error) {
+	table, _, id := moderationTarget(offerId, profileId)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var status ModerationStatus
+
+	err := m.DB.QueryRowContext(ctx, `SELECT moderation_status FROM `+table+` WHERE id = $1`, id).Scan(&status)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return "", ErrRecordNotFound
+		default:
+			return "", err
+		}
+	}
+
+	return status, nil
+}
+
+// Decide applies the decision, which must have been validated with
+// ValidateModerationDecision, records it and resolves the open reports of the content.
+func (m ModerationModel) Decide(decision *ModerationDecision) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	table, column, id := moderationTarget(decision.OfferId, decision.ProfileId)
+
+	result, err := tx.ExecContext(ctx, `UPDATE `+table+` SET moderation_status = $1 WHERE id = $2 AND moderation_status <> 'BANNED'`,
+		moderationActionStatuses[decision.Action], id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	// The content was deleted or banned in the meantime.
+	if rows == 0 {
+		return ErrEditConflict
+	}
+
+	query := `
+		INSERT INTO moderation_decisions (moderator_id, offer_id, profile_id, action, note)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`
+
+	args := []any{decision.ModeratorId, decision.OfferId, decision.ProfileId, decision.Action, decision.Note}
+
+	err = tx.QueryRowContext(ctx, query, args...).Scan(&decision.ID, &decision.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE reports SET resolved_at = NOW() WHERE `+column+` = $1 AND resolved_at IS NULL`, id)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetQueue returns up to limit offers and profiles with open reports, the most reported
+// first, then the ones reported first, with their open reports.
+func (m ModerationModel) GetQueue(limit int) ([]*ModerationItem, error) {
+	query := `
+		SELECT r.offer_id, r.profile_id, COALESCE(o.moderation_status, p.moderation_status), count(*)
+		FROM reports r
+		LEFT JOIN offers o ON o.id = r.offer_id
+		LEFT JOIN profiles p ON p.id = r.profile_id
+		WHERE r.resolved_at IS NULL
+		GROUP BY r.offer_id, r.profile_id, o.moderation_status, p.moderation_status
+		ORDER BY count(*) DESC, min(r.created_at), min(r.id)
+		LIMIT $1
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, limit)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	items := []*ModerationItem{}
+	byTarget := make(map[string]*ModerationItem)
+
+	var offerIds, profileIds []int64
+
+	for rows.Next() {
+		var item ModerationItem
+
+		err := rows.Scan(&item.OfferId, &item.ProfileId, &item.Status, &item.ReportsCount)
+		if err != nil {
+			return nil, err
+		}
+
+		if item.OfferId != nil {
+			offerIds = append(offerIds, *item.OfferId)
+		} else {
+			profileIds = append(profileIds, *item.ProfileId)
+		}
+
+		byTarget[moderationKey(item.OfferId, item.ProfileId)] = &item
+		items = append(items, &item)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(items) == 0 {
+		return items, nil
+	}
+
+	query = `
+		SELECT id, user_id, offer_id, profile_id, reason, details, created_at
+		FROM reports
+		WHERE resolved_at IS NULL AND (offer_id = ANY($1) OR profile_id = ANY($2))
+		ORDER BY created_at, id
+	`
+
+	reportRows, err := m.DB.QueryContext(ctx, query, pq.Array(offerIds), pq.Array(profileIds))
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer reportRows.Close()
+
+	for reportRows.Next() {
+		var report Report
+
+		err := reportRows.Scan(
+			&report.ID,
+			&report.UserId,
+			&report.OfferId,
+			&report.ProfileId,
+			&report.Reason,
+			&report.Details,
+			&report.CreatedAt,
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		item := byTarget[moderationKey(report.OfferId, report.ProfileId)]
+		item.Reports = append(item.Reports, &report)
+	}
+
+	if err = reportRows.Err(); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// moderationKey identifies the offer or the profile among the items of the queue.
+func moderationKey(offerId *int64, profileId *int64) string {
+	table, _, id := moderationTarget(offerId, profileId)
+
+	return fmt.Sprintf("%s:%d", table, id)
+}
@@ -4,32 +4,102 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"itfinder.adrianescat.com/internal/validator"
 	"time"
+
+	"github.com/lib/pq"
 )
 
 type Salaries []*SalaryByRole
 
 type Offer struct {
-	ID          int64     `json:"id"`
-	UserId      int64     `json:"user_id"`
-	User        *User     `json:"user"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"-"`
-	Title       string    `json:"title"`
-	PictureUrl  string    `json:"picture_url"`
-	Description string    `json:"description"`
-	Salary      Salaries  `json:"salary"`
-	Active      bool      `json:"-"`
-	Version     int       `json:"-"`
+	ID               int64            `json:"id"`
+	UserId           int64            `json:"user_id"`
+	User             *User            `json:"user"`
+	CompanyId        *int64           `json:"company_id"`
+	CreatedAt        time.Time        `json:"created_at"`
+	UpdatedAt        time.Time        `json:"-"`
+	Title            string           `json:"title"`
+	PictureUrl       string           `json:"picture_url"`
+	Description      string           `json:"description"`
+	Salary           Salaries         `json:"salary"`
+	Questions        []*OfferQuestion `json:"questions"`
+	Country          string           `json:"country"`
+	State            string           `json:"state"`
+	City             string           `json:"city"`
+	WorkMode         WorkMode         `json:"work_mode"`
+	Latitude         *float64         `json:"-"`
+	Longitude        *float64         `json:"-"`
+	DistanceKm       *float64         `json:"-"`
+	Active           bool             `json:"-"`
+	PublishAt        *time.Time       `json:"publish_at"`
+	ExpiresAt        *time.Time       `json:"expires_at"`
+	ClosedAt         *time.Time       `json:"closed_at"`
+	Status           OfferStatus      `json:"status"`
+	ModerationStatus ModerationStatus `json:"moderation_status"`
+	Version          int              `json:"-"`
+	ApplicantsCount  *int             `json:"-"`
+	// Duplicates are set by createOffer, which looks for them before saving the offer.
+	Duplicates []*OfferDuplicate `json:"-"`
 }
 
 type OfferModel struct {
 	DB *sql.DB
 }
 
+// OfferFilters are the filters of the offers listing. The salary bounds and the salary
+// sorting are applied to the salaries converted to Currency. Offers must have all the
+// SkillIds, and be within the Near radius when it's set.
+type OfferFilters struct {
+	MinSalary *float64
+	MaxSalary *float64
+	Currency  string
+	SkillIds  []int64
+	Near      *GeoFilter
+	// ActiveOnly leaves out the inactive offers, for the listings open to anyone.
+	// Otherwise the inactive offers are only listed to ManagerId, when they manage them.
+	ActiveOnly bool
+	ManagerId  int64
+	Filters
+}
+
+var OfferSortSafelist = []string{"id", "created_at", "salary", "distance", "-id", "-created_at", "-salary", "-distance"}
+
+func ValidateOfferFilters(v *validator.Validator, f OfferFilters) {
+	v.Check(validator.ValidCurrency(f.Currency), "currency", "must be an ISO 4217 currency code")
+
+	if f.MinSalary != nil && f.MaxSalary != nil {
+		v.Check(*f.MinSalary <= *f.MaxSalary, "minSalary", "must not be greater than maxSalary")
+	}
+
+	if f.Near != nil {
+		ValidateGeoFilter(v, f.Near)
+	}
+
+	ValidateFilters(v, f.Filters)
+
+	if f.Filters.sortColumn() == "distance" {
+		v.Check(f.Near != nil, "sort", "sorting by distance requires near")
+	}
+}
+
+// sortColumn maps the sort value to the column of the listing query.
+func (f OfferFilters) sortColumn() string {
+	switch f.Filters.sortColumn() {
+	case "salary":
+		return "s.max_salary"
+	case "created_at":
+		return "o.created_at"
+	case "distance":
+		return "d.km"
+	default:
+		return "o.id"
+	}
+}
+
 func (s Salaries) MarshalGQL(w io.Writer) {
 	err := json.NewEncoder(w).Encode(s)
 	if err != nil {
@@ -55,14 +125,25 @@ func ValidateOffer(v *validator.Validator, offer *Offer) {
 	validator.ValidatePictureUrl(v, offer.PictureUrl)
 
 	v.Check(offer.Description != "", "description", "must be provided")
-	v.Check(offer.Salary != nil, "salary", "must be provided")
+
+	ValidateSalaries(v, offer.Salary)
+
+	ValidateOfferQuestions(v, offer.Questions)
+
+	v.Check(offer.WorkMode.IsValid(), "workMode", fmt.Sprintf("%s is not a permitted work mode", offer.WorkMode))
+
+	// Remote offers may be open to any country, the others must say where the job is.
+	if offer.WorkMode != WorkModeRemote || offer.Country != "" {
+		ValidateLocation(v, offer.Country, offer.State, offer.City, offer.WorkMode == WorkModeRemote)
+	}
 }
 
 func (m OfferModel) Insert(offer *Offer) error {
 	query := `
-		INSERT INTO offers (user_id, title, picture_url, description, salary)
-		VALUES ($1, $2, $3, $4, $5::jsonb)
-		RETURNING id, created_at, version
+		INSERT INTO offers (user_id, title, picture_url, description, salary, country, state, city, work_mode, latitude, longitude, company_id,
+			publish_at, expires_at, status, active)
+		VALUES ($1, $2, $3, $4, $5::jsonb, NULLIF($6, ''), NULLIF($7, ''), NULLIF($8, ''), $9, $10, $11, $12, $13, $14, $15, $15::text = 'PUBLISHED')
+		RETURNING id, created_at, version, active, moderation_status
 	`
 
 	salariesJSON, err := json.Marshal(offer.Salary)
@@ -70,27 +151,144 @@ func (m OfferModel) Insert(offer *Offer) error {
 		return err
 	}
 
-	args := []any{offer.UserId, offer.Title, offer.PictureUrl, offer.Description, salariesJSON}
+	args := []any{
+		offer.UserId,
+		offer.Title,
+		offer.PictureUrl,
+		offer.Description,
+		salariesJSON,
+		offer.Country,
+		offer.State,
+		offer.City,
+		offer.WorkMode,
+		offer.Latitude,
+		offer.Longitude,
+		offer.CompanyId,
+		offer.PublishAt,
+		offer.ExpiresAt,
+		offer.Status,
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 
 	defer cancel()
 
-	err = m.DB.QueryRowContext(ctx, query, args...).Scan(&offer.ID, &offer.CreatedAt, &offer.Version)
+	// The offer and its screening questions are created in the same transaction, so an
+	// offer is never published without the questions the recruiter asked for.
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(ctx, query, args...).Scan(&offer.ID, &offer.CreatedAt, &offer.Version, &offer.Active, &offer.ModerationStatus)
 
 	if err != nil {
 		return err
 	}
 
-	return nil
+	err = insertOfferQuestions(ctx, tx, offer.ID, offer.Questions)
+	if err != nil {
+		return err
+	}
+
+	err = insertOfferRevision(ctx, tx, offer.ID, &offer.UserId)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
-func (m OfferModel) GetAll() ([]*Offer, error) {
-	query := `SELECT id, created_at, title, description, salary, picture_url, user_id, active FROM offers`
+// Update saves the title, the description and the salary of the offer, recording the
+// change in its history on behalf of the user.
+func (m OfferModel) Update(offer *Offer, userId int64) error {
+	query := `
+		UPDATE offers
+		SET title = $1, description = $2, salary = $3::jsonb, updated_at = NOW(), version = version + 1
+		WHERE id = $4 AND version = $5
+		RETURNING updated_at, version
+	`
+
+	salariesJSON, err := json.Marshal(offer.Salary)
+	if err != nil {
+		return err
+	}
+
+	args := []any{
+		offer.Title,
+		offer.Description,
+		salariesJSON,
+		offer.ID,
+		offer.Version,
+	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	var args []any
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(ctx, query, args...).Scan(&offer.UpdatedAt, &offer.Version)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrEditConflict
+		default:
+			return err
+		}
+	}
+
+	err = insertOfferRevision(ctx, tx, offer.ID, &userId)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetAll lists the offers. Every salary entry is converted to a monthly amount in the
+// filters currency with the exchange rates, entries in a currency without rate are
+// ignored by the salary filters and sorting. Offers without coordinates, like most
+// remote ones, are left out of proximity searches, and so are the offers rejected or
+// banned by the moderators and the ones of suspended or deleted users.
+func (m OfferModel) GetAll(filters OfferFilters) ([]*Offer, error) {
+	geoJoin, geoWhere := geoFilterSQL("o", 7)
+
+	query := fmt.Sprintf(`
+		SELECT o.id, o.created_at, o.title, o.description, o.salary, o.picture_url, o.user_id, o.active,
+			COALESCE(o.country, ''), COALESCE(o.state, ''), COALESCE(o.city, ''), o.work_mode, o.company_id,
+			o.publish_at, o.expires_at, o.closed_at, o.status, o.moderation_status, d.km
+		FROM offers o
+		LEFT JOIN LATERAL (
+			SELECT min((e->>'min')::numeric * %[1]s / fr.rate * tr.rate) AS min_salary,
+				max((e->>'max')::numeric * %[1]s / fr.rate * tr.rate) AS max_salary
+			FROM jsonb_array_elements(CASE WHEN jsonb_typeof(o.salary) = 'array' THEN o.salary ELSE '[]'::jsonb END) e
+			INNER JOIN exchange_rates fr ON fr.currency = upper(e->>'currency')
+			INNER JOIN exchange_rates tr ON tr.currency = $1
+		) s ON true
+		%[4]s
+		WHERE ($2::numeric IS NULL OR s.max_salary >= $2)
+		AND ($3::numeric IS NULL OR s.min_salary <= $3)
+		AND (cardinality($4::bigint[]) = 0 OR (
+			SELECT count(*) FROM offer_skills os WHERE os.offer_id = o.id AND os.skill_id = ANY($4)
+		) = cardinality($4::bigint[]))
+		AND (o.active OR NOT $5::boolean AND `+offerManagerSQL("o", "$6")+`)
+		AND o.`+listedModerationSQL+`
+		AND `+listedOwnerSQL("o")+`
+		AND %[5]s
+		ORDER BY %[2]s %[3]s NULLS LAST, o.id ASC`, monthlySalaryFactorSQL, filters.sortColumn(), filters.sortDirection(), geoJoin, geoWhere)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	args := []any{filters.Currency, filters.MinSalary, filters.MaxSalary, pq.Array(filters.SkillIds), filters.ActiveOnly, filters.ManagerId}
+	args = append(args, filters.Near.args()...)
 
 	rows, err := m.DB.QueryContext(ctx, query, args...)
 
@@ -114,6 +312,17 @@ func (m OfferModel) GetAll() ([]*Offer, error) {
 			&offer.PictureUrl,
 			&offer.UserId,
 			&offer.Active,
+			&offer.Country,
+			&offer.State,
+			&offer.City,
+			&offer.WorkMode,
+			&offer.CompanyId,
+			&offer.PublishAt,
+			&offer.ExpiresAt,
+			&offer.ClosedAt,
+			&offer.Status,
+			&offer.ModerationStatus,
+			&offer.DistanceKm,
 		)
 
 		if err != nil {
@@ -136,29 +345,242 @@ func (m OfferModel) GetAll() ([]*Offer, error) {
 	return offers, nil
 }
 
-func (m OfferModel) CreateApplicant(offerId int64, profileId int64) error {
+// GetRecommendationCandidates returns the listed published offers worth recommending to
+// the profile.
+func (m OfferModel) GetRecommendationCandidates(profileId int64) ([]*Offer, error) {
+	query := `
+		SELECT o.id, o.created_at, o.title, o.description, o.salary, o.picture_url, o.user_id, o.active,
+			COALESCE(o.country, ''), COALESCE(o.state, ''), COALESCE(o.city, ''), o.work_mode
+		FROM offers o
+		INNER JOIN profiles p ON p.id = $1
+		WHERE o.active
+		AND o.` + listedModerationSQL + `
+		AND ` + listedOwnerSQL("o") + `
+		AND ` + recommendationCandidateSQL + `
+		ORDER BY ` + sharedTitleWordsSQL + ` DESC, o.id
+		LIMIT $2
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, profileId, maxRecommendationCandidates)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var offers []*Offer
+
+	for rows.Next() {
+		var offer Offer
+		var salaries []byte
+		err := rows.Scan(
+			&offer.ID,
+			&offer.CreatedAt,
+			&offer.Title,
+			&offer.Description,
+			&salaries,
+			&offer.PictureUrl,
+			&offer.UserId,
+			&offer.Active,
+			&offer.Country,
+			&offer.State,
+			&offer.City,
+			&offer.WorkMode,
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		var salariesObj Salaries
+		err = json.Unmarshal(salaries, &salariesObj)
+		offer.Salary = salariesObj
+
+		if err != nil {
+			return nil, err
+		}
+
+		offers = append(offers, &offer)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return offers, nil
+}
+
+func (m OfferModel) GetById(id int64) (*Offer, error) {
+	if id < 1 {
+		return nil, ErrRecordNotFound
+	}
+
 	query := `
-		INSERT INTO offers_applicants (offer_id, profile_id)
-		VALUES ($1, $2)
-		RETURNING offer_id, profile_id 
+		SELECT id, created_at, title, description, salary, picture_url, user_id, active, version,
+			COALESCE(country, ''), COALESCE(state, ''), COALESCE(city, ''), work_mode, company_id,
+			publish_at, expires_at, closed_at, status, moderation_status
+		FROM offers
+		WHERE id = $1
 	`
 
-	args := []any{offerId, profileId}
+	var offer Offer
 
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	_, err := m.DB.ExecContext(ctx, query, args...)
+	var salaries []byte
+	err := m.DB.QueryRowContext(ctx, query, id).Scan(
+		&offer.ID,
+		&offer.CreatedAt,
+		&offer.Title,
+		&offer.Description,
+		&salaries,
+		&offer.PictureUrl,
+		&offer.UserId,
+		&offer.Active,
+		&offer.Version,
+		&offer.Country,
+		&offer.State,
+		&offer.City,
+		&offer.WorkMode,
+		&offer.CompanyId,
+		&offer.PublishAt,
+		&offer.ExpiresAt,
+		&offer.ClosedAt,
+		&offer.Status,
+		&offer.ModerationStatus,
+	)
+
 	if err != nil {
-		return err
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
 	}
 
-	return nil
+	var salariesObj Salaries
+	err = json.Unmarshal(salaries, &salariesObj)
+	offer.Salary = salariesObj
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &offer, nil
+}
+
+// GetAllByUserId returns the offers created by the user together with the number of
+// applicants each one received.
+func (m OfferModel) GetAllByUserId(userId int64) ([]*Offer, error) {
+	return m.getAllWithApplicantsCount("o.user_id = $1", userId)
+}
+
+// GetAllByCompanyId returns the offers of the company together with the number of
+// applicants each one received. The public company page only lists the active ones that
+// were not rejected by the moderators, of users that were not suspended nor deleted.
+func (m OfferModel) GetAllByCompanyId(companyId int64, activeOnly bool) ([]*Offer, error) {
+	return m.getAllWithApplicantsCount("o.company_id = $1 AND ((o.active AND o."+listedModerationSQL+" AND "+listedOwnerSQL("o")+") OR NOT $2)", companyId, activeOnly)
+}
+
+func (m OfferModel) getAllWithApplicantsCount(where string, args ...any) ([]*Offer, error) {
+	query := `
+		SELECT o.id, o.created_at, o.title, o.description, o.salary, o.picture_url, o.user_id, o.active, o.version,
+			COALESCE(o.country, ''), COALESCE(o.state, ''), COALESCE(o.city, ''), o.work_mode, o.company_id,
+			o.publish_at, o.expires_at, o.closed_at, o.status, o.moderation_status,
+			(SELECT count(*) FROM offers_applicants oa WHERE oa.offer_id = o.id)
+		FROM offers o
+		WHERE ` + where + `
+		ORDER BY o.created_at DESC
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, args...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var offers []*Offer
+
+	for rows.Next() {
+		var offer Offer
+		var salaries []byte
+		var applicantsCount int
+		err := rows.Scan(
+			&offer.ID,
+			&offer.CreatedAt,
+			&offer.Title,
+			&offer.Description,
+			&salaries,
+			&offer.PictureUrl,
+			&offer.UserId,
+			&offer.Active,
+			&offer.Version,
+			&offer.Country,
+			&offer.State,
+			&offer.City,
+			&offer.WorkMode,
+			&offer.CompanyId,
+			&offer.PublishAt,
+			&offer.ExpiresAt,
+			&offer.ClosedAt,
+			&offer.Status,
+			&offer.ModerationStatus,
+			&applicantsCount,
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		var salariesObj Salaries
+		err = json.Unmarshal(salaries, &salariesObj)
+		offer.Salary = salariesObj
+
+		if err != nil {
+			return nil, err
+		}
+
+		offer.ApplicantsCount = &applicantsCount
+
+		offers = append(offers, &offer)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return offers, nil
+}
+
+func (m OfferModel) CountApplicants(id int64) (int, error) {
+	query := `SELECT count(*) FROM offers_applicants WHERE offer_id = $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var count int
+	err := m.DB.QueryRowContext(ctx, query, id).Scan(&count)
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
 }
 
 func (m OfferModel) GetAllApplicants(id int64) ([]*Profile, error) {
 	query := `
-		SELECT p.id, p.user_id, p.created_at, p.title, p.about, p.status, p.country, p.state, p.city, p.picture_url, p.website_url, p.salary, p.version
+		SELECT p.id, p.user_id, p.created_at, p.title, p.about, p.status, p.country, p.state, p.city, p.picture_url, p.website_url, p.salary, p.version, p.visibility, p.moderation_status
 		FROM profiles p
 		INNER JOIN offers_applicants oa on p.id = oa.profile_id
 		INNER JOIN offers o on o.id = oa.offer_id
@@ -195,6 +617,8 @@ func (m OfferModel) GetAllApplicants(id int64) ([]*Profile, error) {
 			&profile.WebsiteUrl,
 			&salaries,
 			&profile.Version,
+			&profile.Visibility,
+			&profile.ModerationStatus,
 		)
 
 		if err != nil {
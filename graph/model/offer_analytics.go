@@ -0,0 +1,179 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"itfinder.adrianescat.com/internal/validator"
+	"time"
+)
+
+const (
+	// DefaultAnalyticsPeriod is the period of the analytics when no start is given.
+	DefaultAnalyticsPeriod = 30 * 24 * time.Hour
+	maxAnalyticsDays       = 366
+)
+
+// OfferAnalytics is the performance of an offer between two days, both included, in UTC.
+// Views count every viewer once a day, and the applications are the ones received in the
+// period.
+type OfferAnalytics struct {
+	OfferId        int64                     `json:"offer_id"`
+	From           time.Time                 `json:"from"`
+	To             time.Time                 `json:"to"`
+	Views          int                       `json:"views"`
+	UniqueViewers  int                       `json:"unique_viewers"`
+	Applications   int                       `json:"applications"`
+	ConversionRate float64                   `json:"conversion_rate"`
+	Daily          []*OfferAnalyticsDay      `json:"daily"`
+	Funnel         []*ApplicationStatusCount `json:"funnel"`
+}
+
+type OfferAnalyticsDay struct {
+	Date         time.Time `json:"date"`
+	Views        int       `json:"views"`
+	Applications int       `json:"applications"`
+}
+
+type ApplicationStatusCount struct {
+	Status string `json:"status"`
+	Count  int    `json:"count"`
+}
+
+// AnalyticsDay truncates the time to its day in UTC.
+func AnalyticsDay(t time.Time) time.Time {
+	return t.UTC().Truncate(24 * time.Hour)
+}
+
+func ValidateAnalyticsPeriod(v *validator.Validator, from time.Time, to time.Time) {
+	v.Check(!from.After(to), "from", "must not be after to")
+	v.Check(to.Sub(from) < maxAnalyticsDays*24*time.Hour, "to", fmt.Sprintf("must not be more than %d days after from", maxAnalyticsDays))
+}
+
+// RecordView counts the view of the offer, only once a day for the same viewer. userId is
+// nil for the anonymous viewers.
+func (m OfferModel) RecordView(offerId int64, viewerKey string, userId *int64) error {
+	query := `
+		INSERT INTO offer_views (offer_id, viewer_key, day, user_id)
+		VALUES ($1, $2, (NOW() AT TIME ZONE 'UTC')::date, $3)
+		ON CONFLICT DO NOTHING
+	`
+
+	_, err := m.exec(query, offerId, viewerKey, userId)
+
+	return err
+}
+
+// GetAnalytics returns the analytics of the offer between the days of from and to.
+func (m OfferModel) GetAnalytics(offerId int64, from time.Time, to time.Time) (*OfferAnalytics, error) {
+	analytics := &OfferAnalytics{
+		OfferId: offerId,
+		From:    AnalyticsDay(from),
+		To:      AnalyticsDay(to),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `
+		SELECT d.day,
+			(SELECT count(*) FROM offer_views v WHERE v.offer_id = $1 AND v.day = d.day),
+			(SELECT count(*) FROM offers_applicants a
+				WHERE a.offer_id = $1 AND (a.created_at AT TIME ZONE 'UTC')::date = d.day)
+		FROM generate_series($2::date, $3::date, interval '1 day') AS d(day)
+		ORDER BY d.day
+	`
+
+	first, last := analytics.From.Format("2006-01-02"), analytics.To.Format("2006-01-02")
+
+	rows, err := m.DB.QueryContext(ctx, query, offerId, first, last)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var day OfferAnalyticsDay
+
+		err := rows.Scan(&day.Date, &day.Views, &day.Applications)
+		if err != nil {
+			return nil, err
+		}
+
+		analytics.Views += day.Views
+		analytics.Applications += day.Applications
+		analytics.Daily = append(analytics.Daily, &day)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	query = `
+		SELECT count(DISTINCT viewer_key)
+		FROM offer_views
+		WHERE offer_id = $1 AND day BETWEEN $2::date AND $3::date
+	`
+
+	err = m.DB.QueryRowContext(ctx, query, offerId, first, last).Scan(&analytics.UniqueViewers)
+	if err != nil {
+		return nil, err
+	}
+
+	if analytics.UniqueViewers > 0 {
+		analytics.ConversionRate = float64(analytics.Applications) / float64(analytics.UniqueViewers)
+	}
+
+	analytics.Funnel, err = m.getFunnel(ctx, offerId, first, last)
+	if err != nil {
+		return nil, err
+	}
+
+	return analytics, nil
+}
+
+// getFunnel counts the applications received in the period by their current status, in
+// the order of the hiring pipeline.
+func (m OfferModel) getFunnel(ctx context.Context, offerId int64, first string, last string) ([]*ApplicationStatusCount, error) {
+	query := `
+		SELECT status, count(*)
+		FROM offers_applicants
+		WHERE offer_id = $1 AND (created_at AT TIME ZONE 'UTC')::date BETWEEN $2::date AND $3::date
+		GROUP BY status
+	`
+
+	rows, err := m.DB.QueryContext(ctx, query, offerId, first, last)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	counts := make(map[string]int)
+
+	for rows.Next() {
+		var status string
+		var count int
+
+		err := rows.Scan(&status, &count)
+		if err != nil {
+			return nil, err
+		}
+
+		counts[status] = count
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	funnel := make([]*ApplicationStatusCount, 0, len(ApplicationStatuses))
+
+	for _, status := range ApplicationStatuses {
+		funnel = append(funnel, &ApplicationStatusCount{Status: status, Count: counts[status]})
+	}
+
+	return funnel, nil
+}
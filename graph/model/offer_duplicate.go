@@ -0,0 +1,193 @@
+package model
+
+import (
+	"context"
+	"itfinder.adrianescat.com/internal/matching"
+	"sort"
+	"time"
+)
+
+// An offer is a near-duplicate of another one of the same owner or company when both its
+// title and its description are at least this similar.
+const (
+	DuplicateTitleThreshold       = 0.85
+	DuplicateDescriptionThreshold = 0.6
+	maxDuplicateCandidates        = 500
+	maxDuplicatePeers             = 50
+)
+
+// OfferDuplicate is a pair of offers that look the same, DuplicateOf is the older one.
+type OfferDuplicate struct {
+	Offer                 *Offer  `json:"offer"`
+	DuplicateOf           *Offer  `json:"duplicate_of"`
+	TitleSimilarity       float64 `json:"title_similarity"`
+	DescriptionSimilarity float64 `json:"description_similarity"`
+}
+
+// compareOffers returns the pair when the offer is a near-duplicate of the original.
+func compareOffers(offer *Offer, original *Offer) (*OfferDuplicate, bool) {
+	title := matching.TitleSimilarity(offer.Title, original.Title)
+	if title < DuplicateTitleThreshold {
+		return nil, false
+	}
+
+	description := matching.DescriptionSimilarity(offer.Description, original.Description)
+	if description < DuplicateDescriptionThreshold {
+		return nil, false
+	}
+
+	return &OfferDuplicate{
+		Offer:                 offer,
+		DuplicateOf:           original,
+		TitleSimilarity:       title,
+		DescriptionSimilarity: description,
+	}, true
+}
+
+// FindDuplicates compares the offer, saved or not, with the latest offers of its owner and
+// its company that are not archived, and returns the near-duplicates, most similar first.
+func (m OfferModel) FindDuplicates(offer *Offer) ([]*OfferDuplicate, error) {
+	query := `
+		SELECT id, title, description
+		FROM offers
+		WHERE id <> $1
+		AND status <> 'ARCHIVED'
+		AND (user_id = $2 OR company_id = $3)
+		ORDER BY created_at DESC
+		LIMIT $4
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, offer.ID, offer.UserId, offer.CompanyId, maxDuplicateCandidates)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	duplicates := []*OfferDuplicate{}
+
+	for rows.Next() {
+		var candidate Offer
+
+		err := rows.Scan(&candidate.ID, &candidate.Title, &candidate.Description)
+		if err != nil {
+			return nil, err
+		}
+
+		duplicate, ok := compareOffers(offer, &candidate)
+		if ok {
+			duplicates = append(duplicates, duplicate)
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// The candidates were only scanned with the compared fields.
+	for _, duplicate := range duplicates {
+		duplicate.DuplicateOf, err = m.GetById(duplicate.DuplicateOf.ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sortDuplicates(duplicates)
+
+	return duplicates, nil
+}
+
+// GetAllDuplicates compares the latest offers, not archived, with the offers of their owner
+// or their company published before them, and returns up to limit near-duplicates, most
+// similar first. Both sides are capped so the report doesn't compare every pair of offers.
+func (m OfferModel) GetAllDuplicates(limit int) ([]*OfferDuplicate, error) {
+	query := `
+		SELECT a.id, a.title, a.description, b.id, b.title, b.description
+		FROM (
+			SELECT id, user_id, company_id, title, description
+			FROM offers
+			WHERE status <> 'ARCHIVED'
+			ORDER BY id DESC
+			LIMIT $1
+		) b
+		CROSS JOIN LATERAL (
+			SELECT id, title, description
+			FROM offers
+			WHERE id < b.id
+			AND status <> 'ARCHIVED'
+			AND (user_id = b.user_id OR company_id = b.company_id)
+			ORDER BY id DESC
+			LIMIT $2
+		) a
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, maxDuplicateCandidates, maxDuplicatePeers)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	duplicates := []*OfferDuplicate{}
+
+	for rows.Next() {
+		var original, offer Offer
+
+		err := rows.Scan(&original.ID, &original.Title, &original.Description, &offer.ID, &offer.Title, &offer.Description)
+		if err != nil {
+			return nil, err
+		}
+
+		duplicate, ok := compareOffers(&offer, &original)
+		if ok {
+			duplicates = append(duplicates, duplicate)
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	sortDuplicates(duplicates)
+
+	if len(duplicates) > limit {
+		duplicates = duplicates[:limit]
+	}
+
+	// Only the reported offers are loaded in full, an offer can be in several pairs.
+	offers := make(map[int64]*Offer)
+
+	for _, duplicate := range duplicates {
+		for _, o := range []**Offer{&duplicate.Offer, &duplicate.DuplicateOf} {
+			full, ok := offers[(*o).ID]
+			if !ok {
+				full, err = m.GetById((*o).ID)
+				if err != nil {
+					return nil, err
+				}
+
+				offers[full.ID] = full
+			}
+
+			*o = full
+		}
+	}
+
+	return duplicates, nil
+}
+
+func sortDuplicates(duplicates []*OfferDuplicate) {
+	sort.SliceStable(duplicates, func(i, j int) bool {
+		a, b := duplicates[i], duplicates[j]
+
+		return a.TitleSimilarity+a.DescriptionSimilarity > b.TitleSimilarity+b.DescriptionSimilarity
+	})
+}
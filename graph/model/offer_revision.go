@@ -0,0 +1,156 @@
+package model
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+)
+
+// OfferRevision is a snapshot of the offer taken after each change.
+type OfferRevision struct {
+	ID          int64               `json:"id"`
+	OfferId     int64               `json:"offer_id"`
+	Version     int                 `json:"version"`
+	UserId      *int64              `json:"user_id"`
+	CreatedAt   time.Time           `json:"created_at"`
+	Title       string              `json:"title"`
+	Description string              `json:"description"`
+	Salary      Salaries            `json:"salary"`
+	Status      OfferStatus         `json:"status"`
+	Changes     []*OfferFieldChange `json:"changes"`
+}
+
+// insertOfferRevisionsSQL records the revisions of the rows returned by a data-modifying
+// query, the statement is completed with its FROM clause.
+const insertOfferRevisionsSQL = `
+	INSERT INTO offer_revisions (offer_id, version, title, description, salary, status)
+	SELECT id, version, title, description, COALESCE(salary, '[]'), status`
+
+// insertOfferRevision records the current state of the offer as a revision made by the
+// user, nil when the change wasn't made by anyone.
+func insertOfferRevision(ctx context.Context, tx *sql.Tx, offerId int64, userId *int64) error {
+	query := `
+		INSERT INTO offer_revisions (offer_id, version, user_id, title, description, salary, status)
+		SELECT id, version, $2, title, description, COALESCE(salary, '[]'), status
+		FROM offers
+		WHERE id = $1
+	`
+
+	_, err := tx.ExecContext(ctx, query, offerId, userId)
+
+	return err
+}
+
+// GetRevisions returns the history of the offer, newest first, with the fields changed by
+// each revision. The first revision shows every field as changed.
+func (m OfferModel) GetRevisions(offerId int64) ([]*OfferRevision, error) {
+	query := `
+		SELECT id, offer_id, version, user_id, created_at, title, description, salary, status
+		FROM offer_revisions
+		WHERE offer_id = $1
+		ORDER BY version ASC
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, offerId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var revisions []*OfferRevision
+	var previous *OfferRevision
+
+	for rows.Next() {
+		var revision OfferRevision
+		var salaries []byte
+		err := rows.Scan(
+			&revision.ID,
+			&revision.OfferId,
+			&revision.Version,
+			&revision.UserId,
+			&revision.CreatedAt,
+			&revision.Title,
+			&revision.Description,
+			&salaries,
+			&revision.Status,
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		err = json.Unmarshal(salaries, &revision.Salary)
+		if err != nil {
+			return nil, err
+		}
+
+		revision.Changes, err = diffOfferRevisions(previous, &revision)
+		if err != nil {
+			return nil, err
+		}
+
+		previous = &revision
+		revisions = append(revisions, &revision)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i, j := 0, len(revisions)-1; i < j; i, j = i+1, j-1 {
+		revisions[i], revisions[j] = revisions[j], revisions[i]
+	}
+
+	return revisions, nil
+}
+
+// diffOfferRevisions lists the fields that changed from the previous revision, which is
+// nil for the first one. The salaries are compared by their JSON encoding.
+func diffOfferRevisions(previous *OfferRevision, current *OfferRevision) ([]*OfferFieldChange, error) {
+	currentSalary, err := json.Marshal(current.Salary)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := []struct {
+		name string
+		to   string
+		from func(*OfferRevision) (string, error)
+	}{
+		{"title", current.Title, func(r *OfferRevision) (string, error) { return r.Title, nil }},
+		{"description", current.Description, func(r *OfferRevision) (string, error) { return r.Description, nil }},
+		{"salary", string(currentSalary), func(r *OfferRevision) (string, error) {
+			salary, err := json.Marshal(r.Salary)
+			return string(salary), err
+		}},
+		{"status", string(current.Status), func(r *OfferRevision) (string, error) { return string(r.Status), nil }},
+	}
+
+	changes := []*OfferFieldChange{}
+
+	for _, field := range fields {
+		to := field.to
+
+		if previous == nil {
+			changes = append(changes, &OfferFieldChange{Field: field.name, To: &to})
+			continue
+		}
+
+		from, err := field.from(previous)
+		if err != nil {
+			return nil, err
+		}
+
+		if from != to {
+			changes = append(changes, &OfferFieldChange{Field: field.name, From: &from, To: &to})
+		}
+	}
+
+	return changes, nil
+}
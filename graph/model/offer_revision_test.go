@@ -0,0 +1,100 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffOfferRevisions(t *testing.T) {
+	str := func(s string) *string { return &s }
+
+	salary := Salaries{{Title: "Go Developer", Min: 3000, Max: 4000, Currency: "USD", Period: SalaryPeriodMonthly, Type: SalaryTypeGross}}
+	raised := Salaries{{Title: "Go Developer", Min: 3500, Max: 4500, Currency: "USD", Period: SalaryPeriodMonthly, Type: SalaryTypeGross}}
+
+	base := &OfferRevision{
+		Title:       "Go Developer",
+		Description: "Build APIs",
+		Salary:      salary,
+		Status:      OfferStatusDraft,
+	}
+
+	tests := []struct {
+		name     string
+		previous *OfferRevision
+		current  *OfferRevision
+		want     []*OfferFieldChange
+	}{
+		{
+			name:    "First revision",
+			current: base,
+			want: []*OfferFieldChange{
+				{Field: "title", To: str("Go Developer")},
+				{Field: "description", To: str("Build APIs")},
+				{Field: "salary", To: str(`[{"title":"Go Developer","min":3000,"max":4000,"currency":"USD","period":"MONTHLY","type":"GROSS"}]`)},
+				{Field: "status", To: str("DRAFT")},
+			},
+		},
+		{
+			name:     "Unchanged fields",
+			previous: base,
+			current:  &OfferRevision{Title: base.Title, Description: base.Description, Salary: salary, Status: base.Status},
+			want:     []*OfferFieldChange{},
+		},
+		{
+			name:     "Salary change",
+			previous: base,
+			current:  &OfferRevision{Title: base.Title, Description: base.Description, Salary: raised, Status: base.Status},
+			want: []*OfferFieldChange{
+				{
+					Field: "salary",
+					From:  str(`[{"title":"Go Developer","min":3000,"max":4000,"currency":"USD","period":"MONTHLY","type":"GROSS"}]`),
+					To:    str(`[{"title":"Go Developer","min":3500,"max":4500,"currency":"USD","period":"MONTHLY","type":"GROSS"}]`),
+				},
+			},
+		},
+		{
+			name:     "Status change",
+			previous: base,
+			current:  &OfferRevision{Title: base.Title, Description: base.Description, Salary: salary, Status: OfferStatusPublished},
+			want:     []*OfferFieldChange{{Field: "status", From: str("DRAFT"), To: str("PUBLISHED")}},
+		},
+		{
+			name:     "Title and description changes",
+			previous: base,
+			current:  &OfferRevision{Title: "Senior Go Developer", Description: "Build and run APIs", Salary: salary, Status: base.Status},
+			want: []*OfferFieldChange{
+				{Field: "title", From: str("Go Developer"), To: str("Senior Go Developer")},
+				{Field: "description", From: str("Build APIs"), To: str("Build and run APIs")},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := diffOfferRevisions(tt.previous, tt.current)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %s; want %s", formatChanges(got), formatChanges(tt.want))
+			}
+		})
+	}
+}
+
+func formatChanges(changes []*OfferFieldChange) string {
+	s := "["
+	for _, c := range changes {
+		from, to := "<nil>", "<nil>"
+		if c.From != nil {
+			from = *c.From
+		}
+		if c.To != nil {
+			to = *c.To
+		}
+		s += " " + c.Field + ": " + from + " -> " + to
+	}
+
+	return s + " ]"
+}
@@ -0,0 +1,325 @@
+package model
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"itfinder.adrianescat.com/internal/validator"
+	"time"
+)
+
+const (
+	// MaxOfferDuration is how long an offer can stay published before it must be renewed.
+	MaxOfferDuration = 180 * 24 * time.Hour
+	// MaxOfferScheduleAhead is how far in the future an offer can be scheduled.
+	MaxOfferScheduleAhead = 365 * 24 * time.Hour
+)
+
+// OfferClosure is the notification owed to an applicant of a closed offer.
+type OfferClosure struct {
+	OfferId    int64
+	OfferTitle string
+	ProfileId  int64
+	Email      string
+	Name       string
+}
+
+// ValidateOfferSchedule checks the publication and expiration dates of an offer. The
+// offers without publishAt are published right away, so the expiration is counted from
+// now.
+func ValidateOfferSchedule(v *validator.Validator, publishAt *time.Time, expiresAt *time.Time) {
+	now := time.Now()
+	start := now
+
+	if publishAt != nil {
+		v.Check(publishAt.Before(now.Add(MaxOfferScheduleAhead)), "publishAt", "must not be more than a year in the future")
+
+		if publishAt.After(now) {
+			start = *publishAt
+		}
+	}
+
+	if expiresAt != nil {
+		v.Check(expiresAt.After(start), "expiresAt", "must be after the publication")
+		v.Check(!expiresAt.After(start.Add(MaxOfferDuration)), "expiresAt", "must not be more than 180 days after the publication")
+	}
+}
+
+// offerStatusTransitions lists the statuses each status can be changed to by hand.
+var offerStatusTransitions = map[OfferStatus][]OfferStatus{
+	OfferStatusDraft:     {OfferStatusPublished, OfferStatusArchived},
+	OfferStatusPublished: {OfferStatusClosed, OfferStatusArchived},
+	OfferStatusClosed:    {OfferStatusPublished, OfferStatusArchived},
+}
+
+// ValidateOfferPublication checks the status and the schedule of a new offer, which is
+// either a draft or published right away.
+func ValidateOfferPublication(v *validator.Validator, offer *Offer) {
+	v.Check(validator.PermittedValue(offer.Status, OfferStatusDraft, OfferStatusPublished), "status", "must be DRAFT or PUBLISHED")
+
+	if offer.Status == OfferStatusPublished {
+		v.Check(offer.PublishAt == nil, "publishAt", "must not be provided for a published offer")
+	}
+
+	ValidateOfferSchedule(v, offer.PublishAt, offer.ExpiresAt)
+}
+
+// ValidateOfferStatusChange checks that the offer can move to the status. An expired offer
+// must be renewed before being published again.
+func ValidateOfferStatusChange(v *validator.Validator, offer *Offer, status OfferStatus) {
+	v.Check(status.IsValid(), "status", "must be a valid offer status")
+	v.Check(validator.PermittedValue(status, offerStatusTransitions[offer.Status]...), "status", fmt.Sprintf("an offer can't go from %s to %s", offer.Status, status))
+
+	if status == OfferStatusPublished && offer.ExpiresAt != nil {
+		v.Check(offer.ExpiresAt.After(time.Now()), "status", "the offer expired, renew it to publish it again")
+	}
+}
+
+// PublishDue publishes the draft offers whose publication date was reached and returns
+// their ids. Offers that expired before being published are left to CloseExpired.
+func (m OfferModel) PublishDue() ([]int64, error) {
+	query := `
+		WITH published AS (
+			UPDATE offers
+			SET status = 'PUBLISHED', active = true, publish_at = NULL, updated_at = NOW(), version = version + 1
+			WHERE status = 'DRAFT'
+			AND publish_at <= NOW()
+			AND (expires_at IS NULL OR expires_at > NOW())
+			RETURNING id, version, title, description, salary, status
+		)
+		` + insertOfferRevisionsSQL + ` FROM published
+		RETURNING offer_id
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var ids []int64
+
+	for rows.Next() {
+		var id int64
+
+		err := rows.Scan(&id)
+		if err != nil {
+			return nil, err
+		}
+
+		ids = append(ids, id)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// CloseExpired closes the offers, published or scheduled, whose expiration date was
+// reached and returns how many there were.
+func (m OfferModel) CloseExpired() (int64, error) {
+	query := `
+		WITH closed AS (
+			UPDATE offers
+			SET status = 'CLOSED', active = false, publish_at = NULL, closed_at = NOW(), updated_at = NOW(), version = version + 1
+			WHERE expires_at <= NOW()
+			AND (status = 'PUBLISHED' OR (status = 'DRAFT' AND publish_at IS NOT NULL))
+			RETURNING id, version, title, description, salary, status
+		)
+		` + insertOfferRevisionsSQL + ` FROM closed
+	`
+
+	return m.exec(query)
+}
+
+// SetStatus moves the offer to the status, which must have been validated with
+// ValidateOfferStatusChange, on behalf of the user. Closing or archiving the offer
+// notifies its applicants, archiving keeps the date it was closed at if it was closed
+// first, and any scheduled publication is cancelled.
+func (m OfferModel) SetStatus(offer *Offer, status OfferStatus, userId int64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	query := `
+		UPDATE offers
+		SET status = $1, active = $1::text = 'PUBLISHED', publish_at = NULL,
+			closed_at = CASE $1::text WHEN 'PUBLISHED' THEN NULL WHEN 'CLOSED' THEN NOW() ELSE COALESCE(closed_at, NOW()) END,
+			updated_at = NOW(), version = version + 1
+		WHERE id = $2 AND version = $3
+		RETURNING status, active, publish_at, closed_at, updated_at, version
+	`
+
+	err = tx.QueryRowContext(ctx, query, status, offer.ID, offer.Version).Scan(
+		&offer.Status,
+		&offer.Active,
+		&offer.PublishAt,
+		&offer.ClosedAt,
+		&offer.UpdatedAt,
+		&offer.Version,
+	)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrEditConflict
+		default:
+			return err
+		}
+	}
+
+	if status == OfferStatusPublished {
+		_, err = tx.ExecContext(ctx, `UPDATE offers_applicants SET closure_notified_at = NULL WHERE offer_id = $1`, offer.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = insertOfferRevision(ctx, tx, offer.ID, &userId)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (m OfferModel) exec(query string, args ...any) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// Renew sets a new expiration date on the offer on behalf of the user. A closed offer is
+// published again and its applicants will be notified again when it closes.
+func (m OfferModel) Renew(offer *Offer, expiresAt time.Time, userId int64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	query := `
+		UPDATE offers
+		SET expires_at = $1, status = CASE status WHEN 'CLOSED' THEN 'PUBLISHED' ELSE status END,
+			active = status IN ('PUBLISHED', 'CLOSED'), closed_at = NULL, updated_at = NOW(), version = version + 1
+		WHERE id = $2 AND version = $3
+		RETURNING status, active, expires_at, closed_at, updated_at, version
+	`
+
+	err = tx.QueryRowContext(ctx, query, expiresAt, offer.ID, offer.Version).Scan(
+		&offer.Status,
+		&offer.Active,
+		&offer.ExpiresAt,
+		&offer.ClosedAt,
+		&offer.UpdatedAt,
+		&offer.Version,
+	)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrEditConflict
+		default:
+			return err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE offers_applicants SET closure_notified_at = NULL WHERE offer_id = $1`, offer.ID)
+	if err != nil {
+		return err
+	}
+
+	err = insertOfferRevision(ctx, tx, offer.ID, &userId)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetPendingClosures returns the applicants of the closed offers that were not told about
+// the closing yet. Suspended and deleted applicants are not told.
+func (m OfferModel) GetPendingClosures() ([]*OfferClosure, error) {
+	query := `
+		SELECT o.id, o.title, p.id, u.email, u.name
+		FROM offers_applicants a
+		INNER JOIN offers o ON o.id = a.offer_id
+		INNER JOIN profiles p ON p.id = a.profile_id
+		INNER JOIN users u ON u.id = p.user_id AND u.suspended_at IS NULL AND u.deleted_at IS NULL
+		WHERE o.closed_at IS NOT NULL
+		AND a.closure_notified_at IS NULL
+		ORDER BY o.id, p.id
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var closures []*OfferClosure
+
+	for rows.Next() {
+		var closure OfferClosure
+		err := rows.Scan(
+			&closure.OfferId,
+			&closure.OfferTitle,
+			&closure.ProfileId,
+			&closure.Email,
+			&closure.Name,
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		closures = append(closures, &closure)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return closures, nil
+}
+
+// MarkClosureNotified flags the applicant as told about the closing of the offer.
+func (m OfferModel) MarkClosureNotified(closure *OfferClosure) error {
+	query := `
+		UPDATE offers_applicants
+		SET closure_notified_at = NOW()
+		WHERE offer_id = $1 AND profile_id = $2
+	`
+
+	_, err := m.exec(query, closure.OfferId, closure.ProfileId)
+
+	return err
+}
@@ -0,0 +1,145 @@
+package model
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"itfinder.adrianescat.com/internal/imaging"
+	"itfinder.adrianescat.com/internal/validator"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// MaxPictureSize is the biggest picture accepted, in bytes.
+const MaxPictureSize = 5 << 20
+
+// PictureSizes are the square thumbnails generated for every picture, by name. The
+// pictureUrl of profiles and offers points to the "medium" one.
+var PictureSizes = map[string]int{
+	"small":  128,
+	"medium": 512,
+}
+
+const PictureDefaultSize = "medium"
+
+// Picture is an uploaded picture, stored once for every size below the ID.
+type Picture struct {
+	ID        string `json:"id"`
+	Format    string `json:"format"`
+	URL       string `json:"url"`
+	SmallURL  string `json:"small_url"`
+	MediumURL string `json:"medium_url"`
+}
+
+var (
+	PictureIDRX   = regexp.MustCompile("^[0-9a-f]{32}$")
+	pictureFileRX = regexp.MustCompile(`^([a-z]+)\.(jpg|png)$`)
+)
+
+func ValidatePictureUpload(v *validator.Validator, size int64, contentType string) {
+	v.Check(size > 0, "file", "must not be empty")
+	v.Check(size <= MaxPictureSize, "file", "must not be more than 5MB")
+	v.Check(validator.PermittedValue(contentType, "image/png", "image/jpeg"), "file", "must be a PNG or JPEG image")
+}
+
+// PictureExtension returns the file extension of the images encoded in the format.
+func PictureExtension(format string) string {
+	if format == imaging.FormatPNG {
+		return ".png"
+	}
+
+	return ".jpg"
+}
+
+// PictureKey returns the storage key of a size of the picture.
+func PictureKey(id string, size string, format string) string {
+	return "pictures/" + id + "/" + size + PictureExtension(format)
+}
+
+// NewPicture builds the picture URLs, relative to the public URL of the API.
+func NewPicture(publicURL string, id string, format string) *Picture {
+	url := func(size string) string {
+		return strings.TrimSuffix(publicURL, "/") + "/" + PictureKey(id, size, format)
+	}
+
+	return &Picture{
+		ID:        id,
+		Format:    format,
+		URL:       url(PictureDefaultSize),
+		SmallURL:  url("small"),
+		MediumURL: url("medium"),
+	}
+}
+
+// ParsePictureFile checks the file name requested to the pictures route and returns
+// its content type.
+func ParsePictureFile(id string, file string) (string, bool) {
+	if !PictureIDRX.MatchString(id) {
+		return "", false
+	}
+
+	m := pictureFileRX.FindStringSubmatch(file)
+	if m == nil {
+		return "", false
+	}
+
+	if _, ok := PictureSizes[m[1]]; !ok {
+		return "", false
+	}
+
+	if m[2] == "png" {
+		return "image/png", true
+	}
+
+	return "image/jpeg", true
+}
+
+// PictureFromURL returns the picture behind a pictureUrl, when it was uploaded to the
+// API rather than set to an external URL.
+func PictureFromURL(publicURL string, url string) (*Picture, bool) {
+	prefix := strings.TrimSuffix(publicURL, "/") + "/pictures/"
+	if !strings.HasPrefix(url, prefix) {
+		return nil, false
+	}
+
+	id, file, found := strings.Cut(strings.TrimPrefix(url, prefix), "/")
+	if !found {
+		return nil, false
+	}
+
+	contentType, ok := ParsePictureFile(id, file)
+	if !ok {
+		return nil, false
+	}
+
+	format := imaging.FormatJPEG
+	if contentType == "image/png" {
+		format = imaging.FormatPNG
+	}
+
+	return NewPicture(publicURL, id, format), true
+}
+
+func (m ProfileModel) UpdatePicture(profile *Profile) error {
+	return updatePicture("profiles", m.DB, profile.ID, profile.PictureUrl, &profile.Version)
+}
+
+func (m OfferModel) UpdatePicture(offer *Offer) error {
+	return updatePicture("offers", m.DB, offer.ID, offer.PictureUrl, &offer.Version)
+}
+
+// updatePicture sets the picture URL of the profile or offer, bumping its version.
+func updatePicture(table string, db *sql.DB, id int64, url string, version *int) error {
+	query := fmt.Sprintf(`
+		UPDATE %s
+		SET picture_url = $1, updated_at = NOW(), version = version + 1
+		WHERE id = $2
+		RETURNING version
+	`, table)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	return db.QueryRowContext(ctx, query, url, id).Scan(version)
+}
@@ -7,24 +7,47 @@ import (
 	"errors"
 	"itfinder.adrianescat.com/internal/validator"
 	"time"
+
+	"github.com/lib/pq"
 )
 
 type Profile struct {
-	ID         int64     `json:"id"`
-	UserId     int64     `json:"user_id"`
-	User       *User     `json:"user"`
-	CreatedAt  time.Time `json:"created_at"`
-	UpdatedAt  time.Time `json:"-"`
-	Title      string    `json:"title"`
-	About      string    `json:"about"`
-	Status     string    `json:"status"`
-	Country    string    `json:"country"`
-	State      string    `json:"state"`
-	City       string    `json:"city"`
-	PictureUrl string    `json:"picture_url"`
-	WebsiteUrl string    `json:"website_url"`
-	Salary     Salaries  `json:"salary"`
-	Version    int       `json:"-"`
+	ID               int64             `json:"id"`
+	UserId           int64             `json:"user_id"`
+	User             *User             `json:"user"`
+	CreatedAt        time.Time         `json:"created_at"`
+	UpdatedAt        time.Time         `json:"-"`
+	Title            string            `json:"title"`
+	About            string            `json:"about"`
+	Status           string            `json:"status"`
+	Country          string            `json:"country"`
+	State            string            `json:"state"`
+	City             string            `json:"city"`
+	PictureUrl       string            `json:"picture_url"`
+	WebsiteUrl       string            `json:"website_url"`
+	Salary           Salaries          `json:"salary"`
+	Latitude         *float64          `json:"-"`
+	Longitude        *float64          `json:"-"`
+	DistanceKm       *float64          `json:"-"`
+	Version          int               `json:"-"`
+	Visibility       ProfileVisibility `json:"visibility"`
+	ModerationStatus ModerationStatus  `json:"moderation_status"`
+}
+
+// ProfileFilters are the filters of the profiles listing. Profiles must have all the
+// SkillIds, and be within the Near radius when it's set.
+type ProfileFilters struct {
+	SkillIds []int64
+	Near     *GeoFilter
+	// ViewerId is the user the profiles are listed to, the ones hidden from their
+	// companies are left out.
+	ViewerId int64
+}
+
+func ValidateProfileFilters(v *validator.Validator, f ProfileFilters) {
+	if f.Near != nil {
+		ValidateGeoFilter(v, f.Near)
+	}
 }
 
 type ProfileModel struct {
@@ -41,26 +64,19 @@ func ValidateProfile(v *validator.Validator, profile *Profile) {
 	v.Check(profile.Status != "", "status", "must be provided")
 	v.Check(len(profile.Status) <= 20, "status", "must not be more than 20 bytes long")
 
-	v.Check(profile.Country != "", "country", "must be provided")
-	v.Check(len(profile.Country) <= 50, "country", "must not be more than 50 bytes long")
-
-	v.Check(profile.State != "", "state", "must be provided")
-	v.Check(len(profile.State) <= 50, "state", "must not be more than 50 bytes long")
-
-	v.Check(profile.City != "", "city", "must be provided")
-	v.Check(len(profile.City) <= 100, "city", "must not be more than 100 bytes long")
+	ValidateLocation(v, profile.Country, profile.State, profile.City, false)
 
 	validator.ValidatePictureUrl(v, profile.PictureUrl)
 	validator.ValidateWebsiteUrl(v, profile.WebsiteUrl)
 
-	v.Check(profile.Salary != nil, "salary", "must be provided")
+	ValidateSalaries(v, profile.Salary)
 }
 
 func (p ProfileModel) Insert(profile *Profile) error {
 	query := `
-		INSERT INTO profiles (user_id, title, about, status, country, state, city, picture_url, website_url, salary)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10::jsonb)
-		RETURNING id, created_at, version
+		INSERT INTO profiles (user_id, title, about, status, country, state, city, picture_url, website_url, salary, latitude, longitude)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10::jsonb, $11, $12)
+		RETURNING id, created_at, version, visibility, moderation_status
 	`
 
 	salariesJSON, err := json.Marshal(profile.Salary)
@@ -79,12 +95,14 @@ func (p ProfileModel) Insert(profile *Profile) error {
 		profile.PictureUrl,
 		profile.WebsiteUrl,
 		salariesJSON,
+		profile.Latitude,
+		profile.Longitude,
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	err = p.DB.QueryRowContext(ctx, query, args...).Scan(&profile.ID, &profile.CreatedAt, &profile.Version)
+	err = p.DB.QueryRowContext(ctx, query, args...).Scan(&profile.ID, &profile.CreatedAt, &profile.Version, &profile.Visibility, &profile.ModerationStatus)
 
 	if err != nil {
 		return err
@@ -98,7 +116,7 @@ func (p ProfileModel) GetProfileById(id int64) (*Profile, error) {
 		return nil, ErrRecordNotFound
 	}
 
-	query := `SELECT id, user_id, created_at, title, about, status, country, state, city, picture_url, website_url, salary, version FROM profiles WHERE id = $1`
+	query := `SELECT id, user_id, created_at, title, about, status, country, state, city, picture_url, website_url, salary, version, visibility, moderation_status FROM profiles WHERE id = $1`
 
 	var profile Profile
 
@@ -121,6 +139,8 @@ func (p ProfileModel) GetProfileById(id int64) (*Profile, error) {
 		&profile.WebsiteUrl,
 		&salaries,
 		&profile.Version,
+		&profile.Visibility,
+		&profile.ModerationStatus,
 	)
 
 	if err != nil {
@@ -148,7 +168,7 @@ func (p ProfileModel) GetProfileByUserId(id int64) (*Profile, error) {
 		return nil, ErrRecordNotFound
 	}
 
-	query := `SELECT id, user_id, created_at, title, about, status, country, state, city, picture_url, website_url, salary, version FROM profiles WHERE user_id = $1`
+	query := `SELECT id, user_id, created_at, title, about, status, country, state, city, picture_url, website_url, salary, version, visibility, moderation_status FROM profiles WHERE user_id = $1`
 
 	var profile Profile
 
@@ -171,6 +191,8 @@ func (p ProfileModel) GetProfileByUserId(id int64) (*Profile, error) {
 		&profile.WebsiteUrl,
 		&salaries,
 		&profile.Version,
+		&profile.Visibility,
+		&profile.ModerationStatus,
 	)
 
 	if err != nil {
@@ -192,3 +214,157 @@ func (p ProfileModel) GetProfileByUserId(id int64) (*Profile, error) {
 
 	return &profile, nil
 }
+
+// GetAll lists the profiles that have all the given skills and, when a proximity filter
+// is set, are within its radius, nearest first. Profiles hidden from the viewer, rejected
+// or banned are left out, and so are the ones of suspended or deleted users.
+func (p ProfileModel) GetAll(filters ProfileFilters) ([]*Profile, error) {
+	geoJoin, geoWhere := geoFilterSQL("p", 3)
+
+	query := `
+		SELECT p.id, p.user_id, p.created_at, p.title, p.about, p.status, p.country, p.state, p.city,
+			p.picture_url, p.website_url, p.salary, p.version, p.visibility, p.moderation_status, d.km
+		FROM profiles p
+		` + geoJoin + `
+		WHERE (cardinality($1::bigint[]) = 0 OR (
+			SELECT count(*) FROM profile_skills ps WHERE ps.profile_id = p.id AND ps.skill_id = ANY($1)
+		) = cardinality($1::bigint[]))
+		AND ` + notHiddenFromSQL("p", 2) + `
+		AND p.` + listedModerationSQL + `
+		AND ` + listedOwnerSQL("p") + `
+		AND ` + geoWhere + `
+		ORDER BY d.km ASC NULLS LAST, p.id ASC
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	args := append([]any{pq.Array(filters.SkillIds), filters.ViewerId}, filters.Near.args()...)
+
+	rows, err := p.DB.QueryContext(ctx, query, args...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var profiles []*Profile
+
+	for rows.Next() {
+		var profile Profile
+		var salaries []byte
+		err := rows.Scan(
+			&profile.ID,
+			&profile.UserId,
+			&profile.CreatedAt,
+			&profile.Title,
+			&profile.About,
+			&profile.Status,
+			&profile.Country,
+			&profile.State,
+			&profile.City,
+			&profile.PictureUrl,
+			&profile.WebsiteUrl,
+			&salaries,
+			&profile.Version,
+			&profile.Visibility,
+			&profile.ModerationStatus,
+			&profile.DistanceKm,
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		var salariesObj Salaries
+		err = json.Unmarshal(salaries, &salariesObj)
+		profile.Salary = salariesObj
+
+		if err != nil {
+			return nil, err
+		}
+
+		profiles = append(profiles, &profile)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return profiles, nil
+}
+
+// GetAllSearching returns the listed profiles worth recommending for the offer, of
+// candidates that are not closed to new opportunities, leaving out the ones hidden from
+// the viewer.
+func (p ProfileModel) GetAllSearching(offerId int64, viewerId int64) ([]*Profile, error) {
+	query := `
+		SELECT p.id, p.user_id, p.created_at, p.title, p.about, p.status, p.country, p.state, p.city,
+			p.picture_url, p.website_url, p.salary, p.version, p.visibility, p.moderation_status
+		FROM profiles p
+		INNER JOIN offers o ON o.id = $1
+		WHERE p.status <> 'close'
+		AND p.` + listedModerationSQL + `
+		AND ` + listedOwnerSQL("p") + `
+		AND ` + notHiddenFromSQL("p", 3) + `
+		AND ` + recommendationCandidateSQL + `
+		ORDER BY ` + sharedTitleWordsSQL + ` DESC, p.id
+		LIMIT $2
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := p.DB.QueryContext(ctx, query, offerId, maxRecommendationCandidates, viewerId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var profiles []*Profile
+
+	for rows.Next() {
+		var profile Profile
+		var salaries []byte
+		err := rows.Scan(
+			&profile.ID,
+			&profile.UserId,
+			&profile.CreatedAt,
+			&profile.Title,
+			&profile.About,
+			&profile.Status,
+			&profile.Country,
+			&profile.State,
+			&profile.City,
+			&profile.PictureUrl,
+			&profile.WebsiteUrl,
+			&salaries,
+			&profile.Version,
+			&profile.Visibility,
+			&profile.ModerationStatus,
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		var salariesObj Salaries
+		err = json.Unmarshal(salaries, &salariesObj)
+		profile.Salary = salariesObj
+
+		if err != nil {
+			return nil, err
+		}
+
+		profiles = append(profiles, &profile)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return profiles, nil
+}
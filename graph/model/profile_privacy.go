@@ -0,0 +1,264 @@
+package model
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"itfinder.adrianescat.com/internal/validator"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// ProfileViewer is the relationship between the user looking at a profile and the
+// candidate, from the closest to the farthest.
+type ProfileViewer int
+
+const (
+	ViewerAnonymous ProfileViewer = iota
+	// ViewerUser is any other authenticated user.
+	ViewerUser
+	// ViewerHiddenCompany is a member of a company the candidate hid the profile from.
+	ViewerHiddenCompany
+	// ViewerRecruiter has the recruiter role. Anyone can create a company, so being a
+	// member of one doesn't make a recruiter.
+	ViewerRecruiter
+	// ViewerApplied manages an offer the candidate applied to.
+	ViewerApplied
+	// ViewerOwner is the candidate or an admin.
+	ViewerOwner
+)
+
+// profileDetailsPolicy tells, for each visibility, which viewers can see the contact
+// details of the profile.
+var profileDetailsPolicy = map[ProfileVisibility]map[ProfileViewer]bool{
+	ProfileVisibilityPublic: {
+		ViewerUser:      true,
+		ViewerRecruiter: true,
+		ViewerApplied:   true,
+		ViewerOwner:     true,
+	},
+	ProfileVisibilityRecruiters: {
+		ViewerRecruiter: true,
+		ViewerApplied:   true,
+		ViewerOwner:     true,
+	},
+	ProfileVisibilityPrivate: {
+		ViewerApplied: true,
+		ViewerOwner:   true,
+	},
+}
+
+// ProfileDetailsVisible reports whether the viewer can see the email, the website and the
+// salary of a profile with the given visibility.
+func ProfileDetailsVisible(visibility ProfileVisibility, viewer ProfileViewer) bool {
+	return profileDetailsPolicy[visibility][viewer]
+}
+
+// ProfileIdentityVisible reports whether the viewer can tell who the candidate is, from
+// their name, title, about, picture and career. The companies the profile is hidden from
+// only see the rest of it.
+func ProfileIdentityVisible(viewer ProfileViewer) bool {
+	return viewer != ViewerHiddenCompany
+}
+
+const maxHiddenCompanies = 100
+
+// notHiddenFromSQL is the condition on the profiles listed to the user whose id is the
+// numbered parameter: the ones hidden from the companies they are a member of are left out.
+func notHiddenFromSQL(alias string, param int) string {
+	return fmt.Sprintf(`NOT EXISTS (
+		SELECT 1
+		FROM profile_hidden_companies h
+		INNER JOIN company_members cm ON cm.company_id = h.company_id
+		WHERE h.profile_id = %s.id AND cm.user_id = $%d
+	)`, alias, param)
+}
+
+func ValidateProfilePrivacy(v *validator.Validator, visibility ProfileVisibility, hiddenCompanyIds []int64) {
+	v.Check(visibility.IsValid(), "visibility", "must be a valid profile visibility")
+
+	v.Check(len(hiddenCompanyIds) <= maxHiddenCompanies, "hiddenCompanyIds", "must not contain more than 100 companies")
+	v.Check(validator.Unique(hiddenCompanyIds), "hiddenCompanyIds", "must not contain duplicate values")
+}
+
+// Viewers returns the relationship of the user with the candidate of every profile, in
+// the same order, with a single query however many profiles are given. Applying to an
+// offer shares the profile with its managers, even from a hidden company, as long as the
+// candidate made the application themselves.
+func (p ProfileModel) Viewers(profiles []*Profile, user *User) ([]ProfileViewer, error) {
+	viewers := make([]ProfileViewer, len(profiles))
+
+	if user.IsAnonymous() {
+		return viewers, nil
+	}
+
+	var ids []int64
+
+	for i, profile := range profiles {
+		if profile.UserId == user.ID {
+			viewers[i] = ViewerOwner
+			continue
+		}
+
+		ids = append(ids, profile.ID)
+	}
+
+	if len(ids) == 0 {
+		return viewers, nil
+	}
+
+	query := `
+		SELECT p.id,
+			EXISTS (
+				SELECT 1 FROM users_roles ur
+				INNER JOIN roles r ON r.id = ur.role_id
+				WHERE ur.user_id = $2 AND r.code IN ('admin', 'superadmin')
+			),
+			EXISTS (
+				SELECT 1
+				FROM offers_applicants a
+				INNER JOIN offers o ON o.id = a.offer_id
+				WHERE a.profile_id = p.id AND a.applied_by = p.user_id
+				AND ` + offerManagerSQL("o", "$2") + `
+			),
+			EXISTS (
+				SELECT 1
+				FROM profile_hidden_companies h
+				INNER JOIN company_members cm ON cm.company_id = h.company_id
+				WHERE h.profile_id = p.id AND cm.user_id = $2
+			),
+			EXISTS (
+				SELECT 1 FROM users_roles ur
+				INNER JOIN roles r ON r.id = ur.role_id
+				WHERE ur.user_id = $2 AND r.code = 'recruiter'
+			)
+		FROM profiles p
+		WHERE p.id = ANY($1)
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := p.DB.QueryContext(ctx, query, pq.Array(ids), user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	relations := make(map[int64]ProfileViewer, len(ids))
+
+	for rows.Next() {
+		var id int64
+		var admin, applied, hidden, recruiter bool
+
+		err := rows.Scan(&id, &admin, &applied, &hidden, &recruiter)
+		if err != nil {
+			return nil, err
+		}
+
+		relations[id] = profileViewer(admin, applied, hidden, recruiter)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Profiles deleted meanwhile are left with the farthest relationship.
+	for i, profile := range profiles {
+		if profile.UserId != user.ID {
+			viewers[i] = relations[profile.ID]
+		}
+	}
+
+	return viewers, nil
+}
+
+// profileViewer picks the closest relationship of an authenticated user, other than the
+// candidate, from what they are to the profile.
+func profileViewer(admin, applied, hidden, recruiter bool) ProfileViewer {
+	switch {
+	case admin:
+		return ViewerOwner
+	case applied:
+		return ViewerApplied
+	case hidden:
+		return ViewerHiddenCompany
+	case recruiter:
+		return ViewerRecruiter
+	default:
+		return ViewerUser
+	}
+}
+
+// UpdatePrivacy sets the visibility of the profile and replaces the companies it's hidden
+// from.
+func (p ProfileModel) UpdatePrivacy(profile *Profile, hiddenCompanyIds []int64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := p.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	query := `
+		UPDATE profiles
+		SET visibility = $1, updated_at = NOW(), version = version + 1
+		WHERE id = $2 AND version = $3
+		RETURNING updated_at, version
+	`
+
+	err = tx.QueryRowContext(ctx, query, profile.Visibility, profile.ID, profile.Version).Scan(&profile.UpdatedAt, &profile.Version)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrEditConflict
+		default:
+			return err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM profile_hidden_companies WHERE profile_id = $1`, profile.ID)
+	if err != nil {
+		return err
+	}
+
+	query = `
+		INSERT INTO profile_hidden_companies (profile_id, company_id)
+		SELECT $1, c.id FROM companies c WHERE c.id = ANY($2)
+	`
+
+	result, err := tx.ExecContext(ctx, query, profile.ID, pq.Array(hiddenCompanyIds))
+	if err != nil {
+		return err
+	}
+
+	inserted, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if inserted != int64(len(hiddenCompanyIds)) {
+		return ErrRecordNotFound
+	}
+
+	return tx.Commit()
+}
+
+// GetHiddenCompanies returns the companies the profile is hidden from.
+func (p ProfileModel) GetHiddenCompanies(profileId int64) ([]*Company, error) {
+	query := `
+		SELECT ` + companyColumns + `
+		FROM companies c
+		INNER JOIN profile_hidden_companies h ON h.company_id = c.id
+		WHERE h.profile_id = $1
+		ORDER BY c.name, c.id
+	`
+
+	return CompanyModel{DB: p.DB}.query(query, profileId)
+}
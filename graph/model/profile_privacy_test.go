@@ -0,0 +1,117 @@
+package model
+
+import "testing"
+
+func TestProfileDetailsVisible(t *testing.T) {
+	tests := []struct {
+		name       string
+		visibility ProfileVisibility
+		viewer     ProfileViewer
+		want       bool
+	}{
+		{"Public to anonymous", ProfileVisibilityPublic, ViewerAnonymous, false},
+		{"Public to user", ProfileVisibilityPublic, ViewerUser, true},
+		{"Public to hidden company", ProfileVisibilityPublic, ViewerHiddenCompany, false},
+		{"Public to recruiter", ProfileVisibilityPublic, ViewerRecruiter, true},
+		{"Public to applied", ProfileVisibilityPublic, ViewerApplied, true},
+		{"Public to owner", ProfileVisibilityPublic, ViewerOwner, true},
+		{"Recruiters to anonymous", ProfileVisibilityRecruiters, ViewerAnonymous, false},
+		{"Recruiters to user", ProfileVisibilityRecruiters, ViewerUser, false},
+		{"Recruiters to hidden company", ProfileVisibilityRecruiters, ViewerHiddenCompany, false},
+		{"Recruiters to recruiter", ProfileVisibilityRecruiters, ViewerRecruiter, true},
+		{"Recruiters to applied", ProfileVisibilityRecruiters, ViewerApplied, true},
+		{"Recruiters to owner", ProfileVisibilityRecruiters, ViewerOwner, true},
+		{"Private to anonymous", ProfileVisibilityPrivate, ViewerAnonymous, false},
+		{"Private to user", ProfileVisibilityPrivate, ViewerUser, false},
+		{"Private to hidden company", ProfileVisibilityPrivate, ViewerHiddenCompany, false},
+		{"Private to recruiter", ProfileVisibilityPrivate, ViewerRecruiter, false},
+		{"Private to applied", ProfileVisibilityPrivate, ViewerApplied, true},
+		{"Private to owner", ProfileVisibilityPrivate, ViewerOwner, true},
+		{"Unknown visibility", ProfileVisibility("SECRET"), ViewerOwner, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ProfileDetailsVisible(tt.visibility, tt.viewer); got != tt.want {
+				t.Errorf("got %v; want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProfileIdentityVisible(t *testing.T) {
+	tests := []struct {
+		name   string
+		viewer ProfileViewer
+		want   bool
+	}{
+		{"Anonymous", ViewerAnonymous, true},
+		{"User", ViewerUser, true},
+		{"Hidden company", ViewerHiddenCompany, false},
+		{"Recruiter", ViewerRecruiter, true},
+		{"Applied", ViewerApplied, true},
+		{"Owner", ViewerOwner, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ProfileIdentityVisible(tt.viewer); got != tt.want {
+				t.Errorf("got %v; want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProfileViewer(t *testing.T) {
+	tests := []struct {
+		name      string
+		admin     bool
+		applied   bool
+		hidden    bool
+		recruiter bool
+		want      ProfileViewer
+	}{
+		{name: "User", want: ViewerUser},
+		{name: "Recruiter", recruiter: true, want: ViewerRecruiter},
+		{name: "Hidden company member", hidden: true, recruiter: true, want: ViewerHiddenCompany},
+		{name: "Applied", applied: true, recruiter: true, want: ViewerApplied},
+		{name: "Applied from a hidden company", applied: true, hidden: true, recruiter: true, want: ViewerApplied},
+		{name: "Admin", admin: true, want: ViewerOwner},
+		{name: "Admin in a hidden company", admin: true, hidden: true, recruiter: true, want: ViewerOwner},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := profileViewer(tt.admin, tt.applied, tt.hidden, tt.recruiter); got != tt.want {
+				t.Errorf("got %v; want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// The candidate and the anonymous users are told apart without querying the database.
+func TestViewerOwnerAndAnonymous(t *testing.T) {
+	profile := &Profile{ID: 1, UserId: 7}
+
+	tests := []struct {
+		name string
+		user *User
+		want ProfileViewer
+	}{
+		{"Anonymous", AnonymousUser, ViewerAnonymous},
+		{"Owner", &User{ID: 7}, ViewerOwner},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ProfileModel{}.Viewers([]*Profile{profile}, tt.user)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got[0] != tt.want {
+				t.Errorf("got %v; want %v", got[0], tt.want)
+			}
+		})
+	}
+}
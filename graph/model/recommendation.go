@@ -0,0 +1,33 @@
+package model
+
+// maxRecommendationCandidates caps the offers or the profiles loaded and scored for a
+// recommendation. They are pre-filtered in SQL, the ones sharing the most title words
+// first, so large tables aren't scored on every request.
+const maxRecommendationCandidates = 500
+
+// titleWordsSQL splits a lower-cased title in the words the scorer compares, keeping the
+// characters of "c++", "c#" or "node.js".
+func titleWordsSQL(column string) string {
+	return `array_remove(regexp_split_to_array(lower(` + column + `), '[^[:alnum:]+#.]+'), '')`
+}
+
+// sharedTitleWordsSQL counts the title words shared by the offer o and the profile p.
+var sharedTitleWordsSQL = `cardinality(ARRAY(SELECT unnest(` + titleWordsSQL("o.title") + `) INTERSECT SELECT unnest(` + titleWordsSQL("p.title") + `)))`
+
+// recommendationCandidateSQL is the condition on the offer o and the profile p worth
+// scoring: the offer is remote or in the country of the candidate, and they share a
+// title word, a skill, or a monthly salary range in the same currency.
+var recommendationCandidateSQL = `(o.work_mode = 'REMOTE' OR o.country IS NULL OR o.country = p.country)
+	AND (` + sharedTitleWordsSQL + ` > 0 OR EXISTS (
+		SELECT 1
+		FROM offer_skills osk
+		INNER JOIN profile_skills psk ON psk.skill_id = osk.skill_id
+		WHERE osk.offer_id = o.id AND psk.profile_id = p.id
+	) OR EXISTS (
+		SELECT 1
+		FROM jsonb_array_elements(CASE WHEN jsonb_typeof(o.salary) = 'array' THEN o.salary ELSE '[]'::jsonb END) os
+		INNER JOIN jsonb_array_elements(CASE WHEN jsonb_typeof(p.salary) = 'array' THEN p.salary ELSE '[]'::jsonb END) ps
+			ON upper(os->>'currency') = upper(ps->>'currency')
+		WHERE (os->>'min')::numeric * ` + monthlyFactorSQL("os") + ` <= (ps->>'max')::numeric * ` + monthlyFactorSQL("ps") + `
+		AND (ps->>'min')::numeric * ` + monthlyFactorSQL("ps") + ` <= (os->>'max')::numeric * ` + monthlyFactorSQL("os") + `
+	))`
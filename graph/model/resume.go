@@ -0,0 +1,268 @@
+package model
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"itfinder.adrianescat.com/internal/validator"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+)
+
+// MaxResumeSize is the biggest resume accepted, in bytes.
+const MaxResumeSize = 5 << 20
+
+const (
+	ContentTypePDF  = "application/pdf"
+	ContentTypeDOC  = "application/msword"
+	ContentTypeDOCX = "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+)
+
+// Resume holds the metadata of a resume file, the content lives in the storage under
+// StorageKey.
+type Resume struct {
+	ID          int64     `json:"id"`
+	UserId      int64     `json:"user_id"`
+	CreatedAt   time.Time `json:"created_at"`
+	StorageKey  string    `json:"-"`
+	Filename    string    `json:"filename"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	Checksum    string    `json:"checksum"`
+}
+
+type ResumeModel struct {
+	DB *sql.DB
+}
+
+var (
+	pdfMagic = []byte("%PDF-")
+	zipMagic = []byte("PK\x03\x04")
+	oleMagic = []byte("\xD0\xCF\x11\xE0\xA1\xB1\x1A\xE1")
+)
+
+// DetectResumeContentType sniffs the first bytes of the file, the content type sent by
+// the client is never trusted. DOC and DOCX files are containers (OLE and ZIP) shared
+// with other formats, so their extension must match as well. It returns an empty
+// string for any other content.
+func DetectResumeContentType(head []byte, filename string) string {
+	ext := strings.ToLower(path.Ext(filename))
+
+	switch {
+	case http.DetectContentType(head) == ContentTypePDF || bytes.HasPrefix(head, pdfMagic):
+		return ContentTypePDF
+	case bytes.HasPrefix(head, zipMagic) && ext == ".docx":
+		return ContentTypeDOCX
+	case bytes.HasPrefix(head, oleMagic) && ext == ".doc":
+		return ContentTypeDOC
+	default:
+		return ""
+	}
+}
+
+// ResumeExtension returns the file extension used to store a resume of the content type.
+func ResumeExtension(contentType string) string {
+	switch contentType {
+	case ContentTypeDOC:
+		return ".doc"
+	case ContentTypeDOCX:
+		return ".docx"
+	default:
+		return ".pdf"
+	}
+}
+
+func ValidateResume(v *validator.Validator, resume *Resume) {
+	v.Check(resume.Filename != "", "filename", "must be provided")
+	v.Check(len(resume.Filename) <= 255, "filename", "must not be more than 255 bytes long")
+
+	v.Check(resume.Size > 0, "file", "must not be empty")
+	v.Check(resume.Size <= MaxResumeSize, "file", "must not be more than 5MB")
+
+	v.Check(
+		validator.PermittedValue(resume.ContentType, ContentTypePDF, ContentTypeDOC, ContentTypeDOCX),
+		"file",
+		"must be a PDF, DOC or DOCX document",
+	)
+}
+
+func (m ResumeModel) Insert(resume *Resume) error {
+	query := `
+		INSERT INTO resumes (user_id, storage_key, filename, content_type, size, checksum)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`
+
+	args := []any{
+		resume.UserId,
+		resume.StorageKey,
+		resume.Filename,
+		resume.ContentType,
+		resume.Size,
+		resume.Checksum,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	return m.DB.QueryRowContext(ctx, query, args...).Scan(&resume.ID, &resume.CreatedAt)
+}
+
+func (m ResumeModel) Get(id int64) (*Resume, error) {
+	if id < 1 {
+		return nil, ErrRecordNotFound
+	}
+
+	query := `
+		SELECT id, user_id, created_at, storage_key, filename, content_type, size, checksum
+		FROM resumes
+		WHERE id = $1
+	`
+
+	var resume Resume
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, id).Scan(
+		&resume.ID,
+		&resume.UserId,
+		&resume.CreatedAt,
+		&resume.StorageKey,
+		&resume.Filename,
+		&resume.ContentType,
+		&resume.Size,
+		&resume.Checksum,
+	)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &resume, nil
+}
+
+// GetAllByUserId returns the resumes of the user, newest first.
+func (m ResumeModel) GetAllByUserId(userId int64) ([]*Resume, error) {
+	query := `
+		SELECT id, user_id, created_at, storage_key, filename, content_type, size, checksum
+		FROM resumes
+		WHERE user_id = $1
+		ORDER BY created_at DESC, id DESC
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var resumes []*Resume
+
+	for rows.Next() {
+		var resume Resume
+		err := rows.Scan(
+			&resume.ID,
+			&resume.UserId,
+			&resume.CreatedAt,
+			&resume.StorageKey,
+			&resume.Filename,
+			&resume.ContentType,
+			&resume.Size,
+			&resume.Checksum,
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		resumes = append(resumes, &resume)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return resumes, nil
+}
+
+// GetLatestByUserId returns the last resume uploaded by the user.
+func (m ResumeModel) GetLatestByUserId(userId int64) (*Resume, error) {
+	query := `
+		SELECT id, user_id, created_at, storage_key, filename, content_type, size, checksum
+		FROM resumes
+		WHERE user_id = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT 1
+	`
+
+	var resume Resume
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, userId).Scan(
+		&resume.ID,
+		&resume.UserId,
+		&resume.CreatedAt,
+		&resume.StorageKey,
+		&resume.Filename,
+		&resume.ContentType,
+		&resume.Size,
+		&resume.Checksum,
+	)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &resume, nil
+}
+
+// CanDownload reports whether the user can download the resume: either it's their own
+// resume, or its owner applied themselves to an offer the user manages. Suspended and
+// deleted users can't download anything, and the offers and the candidates of suspended or
+// deleted users share nothing.
+func (m ResumeModel) CanDownload(resume *Resume, userId int64) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM users u WHERE u.id = $2 AND u.suspended_at IS NULL AND u.deleted_at IS NULL
+		) AND ($1 = $2 OR EXISTS (
+			SELECT 1
+			FROM offers_applicants a
+			INNER JOIN profiles p ON p.id = a.profile_id
+			INNER JOIN offers o ON o.id = a.offer_id
+			WHERE p.user_id = $1 AND a.applied_by = p.user_id
+			AND ` + offerManagerSQL("o", "$2") + `
+			AND ` + listedOwnerSQL("o") + `
+			AND ` + listedOwnerSQL("p") + `
+		))
+	`
+
+	var allowed bool
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, resume.UserId, userId).Scan(&allowed)
+
+	return allowed, err
+}
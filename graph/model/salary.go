@@ -0,0 +1,73 @@
+package model
+
+import (
+	"fmt"
+	"itfinder.adrianescat.com/internal/validator"
+)
+
+// HoursPerMonth is used to compare hourly salaries with monthly ones.
+const HoursPerMonth = 160
+
+// monthlySalaryFactorSQL converts the amounts of a salary entry, aliased as e in the
+// query, to a monthly amount.
+var monthlySalaryFactorSQL = monthlyFactorSQL("e")
+
+// monthlyFactorSQL converts the amounts of the salary entry with the given alias to a
+// monthly amount.
+func monthlyFactorSQL(alias string) string {
+	return fmt.Sprintf(`(CASE %s->>'period' WHEN 'HOURLY' THEN %d WHEN 'YEARLY' THEN 1.0 / 12 ELSE 1 END)`, alias, HoursPerMonth)
+}
+
+// MonthlyFactor returns the factor that converts an amount of the given period to a
+// monthly amount.
+func MonthlyFactor(period SalaryPeriod) float64 {
+	switch period {
+	case SalaryPeriodHourly:
+		return HoursPerMonth
+	case SalaryPeriodYearly:
+		return 1.0 / 12
+	default:
+		return 1
+	}
+}
+
+// ValidateSalaries checks every salary entry, the errors are keyed by the entry path,
+// for example salary[2].min.
+func ValidateSalaries(v *validator.Validator, salaries Salaries) {
+	v.Check(salaries != nil, "salary", "must be provided")
+
+	seen := make(map[string]bool, len(salaries))
+
+	for i, s := range salaries {
+		key := fmt.Sprintf("salary[%d]", i)
+
+		if s == nil {
+			v.AddError(key, "must be provided")
+			continue
+		}
+
+		v.Check(s.Title != "", key+".title", "must be provided")
+		v.Check(len(s.Title) <= 150, key+".title", "must not be more than 150 bytes long")
+
+		v.Check(s.Min >= 0, key+".min", "must not be negative")
+		v.Check(s.Max >= 0, key+".max", "must not be negative")
+		v.Check(s.Min <= s.Max, key+".min", "must not be greater than max")
+
+		v.Check(validator.ValidCurrency(s.Currency), key+".currency", "must be an ISO 4217 currency code")
+		v.Check(s.Period.IsValid(), key+".period", fmt.Sprintf("%s is not a permitted period", s.Period))
+		v.Check(s.Type.IsValid(), key+".type", fmt.Sprintf("%s is not a permitted type", s.Type))
+
+		entry := fmt.Sprintf("%s|%s|%s|%s", s.Title, s.Currency, s.Period, s.Type)
+		v.Check(!seen[entry], key, "must not repeat the title, currency, period and type of another entry")
+		seen[entry] = true
+	}
+}
+
+// NormalizeCurrencies upper-cases the currency codes of the salaries.
+func (s Salaries) NormalizeCurrencies() {
+	for _, salary := range s {
+		if salary != nil {
+			salary.Currency = validator.NormalizeCurrency(salary.Currency)
+		}
+	}
+}
@@ -0,0 +1,217 @@
+package model
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"itfinder.adrianescat.com/internal/geo"
+	"itfinder.adrianescat.com/internal/validator"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// MinInsightSamples is the minimum number of distinct offers or candidates a bucket
+	// must aggregate to be returned, so the salary of a single offer or candidate can't be
+	// inferred, however many salary entries it has.
+	MinInsightSamples = 5
+	// salaryInsightsTTL is how long the computed insights are served from the cache.
+	salaryInsightsTTL = 15 * time.Minute
+)
+
+// SalaryStats aggregates the monthly salaries, taken as the middle of every range, of a
+// bucket.
+type SalaryStats struct {
+	Count   int     `json:"count"`
+	P25     float64 `json:"p25"`
+	Median  float64 `json:"median"`
+	P75     float64 `json:"p75"`
+	P90     float64 `json:"p90"`
+	Average float64 `json:"average"`
+}
+
+// SalaryInsights compares what the offers pay with what the candidates expect for a
+// role. A nil bucket had fewer than MinInsightSamples offers or candidates.
+type SalaryInsights struct {
+	Title      string       `json:"title"`
+	Currency   string       `json:"currency"`
+	Country    string       `json:"country"`
+	MinSamples int          `json:"min_samples"`
+	Offers     *SalaryStats `json:"offers"`
+	Candidates *SalaryStats `json:"candidates"`
+}
+
+type salaryInsightsEntry struct {
+	insights  *SalaryInsights
+	expiresAt time.Time
+}
+
+type salaryInsightsCache struct {
+	mu      sync.Mutex
+	entries map[string]salaryInsightsEntry
+}
+
+type SalaryInsightModel struct {
+	DB    *sql.DB
+	cache *salaryInsightsCache
+}
+
+func ValidateSalaryInsightsQuery(v *validator.Validator, title string, currency string, country string) {
+	v.Check(strings.TrimSpace(title) != "", "title", "must be provided")
+	v.Check(len(title) <= 150, "title", "must not be more than 150 bytes long")
+	v.Check(validator.ValidCurrency(currency), "currency", "must be an ISO 4217 currency code")
+
+	if country != "" {
+		v.Check(geo.ValidCountry(country), "country", "must be an ISO 3166-1 alpha-2 country code")
+	}
+}
+
+// Get returns the salary insights for the role title in the given currency, narrowed to
+// the offers and candidates of the country when one is given. Private profiles keep their
+// salary out of the insights, and so do rejected or banned offers and profiles, and the
+// ones of suspended or deleted users. Results are cached for salaryInsightsTTL.
+func (m SalaryInsightModel) Get(title string, currency string, country string) (*SalaryInsights, error) {
+	key := strings.ToLower(strings.TrimSpace(title)) + "|" + currency + "|" + strings.ToLower(strings.TrimSpace(country))
+
+	if insights, ok := m.cache.get(key); ok {
+		return insights, nil
+	}
+
+	// Every salary entry whose role title, or the title of its offer or profile, contains
+	// the searched title is converted to a monthly amount in the requested currency.
+	query := fmt.Sprintf(`
+		WITH samples AS (
+			SELECT 'offers' AS bucket, o.id AS source_id,
+				((e->>'min')::numeric + (e->>'max')::numeric) / 2 * %[1]s / fr.rate * tr.rate AS amount
+			FROM offers o
+			CROSS JOIN LATERAL jsonb_array_elements(CASE WHEN jsonb_typeof(o.salary) = 'array' THEN o.salary ELSE '[]'::jsonb END) e
+			INNER JOIN exchange_rates fr ON fr.currency = upper(e->>'currency')
+			INNER JOIN exchange_rates tr ON tr.currency = $2
+			WHERE o.active AND (e->>'title' ILIKE $1 OR o.title ILIKE $1)
+			AND ($3 = '' OR o.country = $3)
+			AND o.%[2]s
+			AND %[3]s
+			UNION ALL
+			SELECT 'candidates' AS bucket, p.id AS source_id,
+				((e->>'min')::numeric + (e->>'max')::numeric) / 2 * %[1]s / fr.rate * tr.rate AS amount
+			FROM profiles p
+			CROSS JOIN LATERAL jsonb_array_elements(CASE WHEN jsonb_typeof(p.salary) = 'array' THEN p.salary ELSE '[]'::jsonb END) e
+			INNER JOIN exchange_rates fr ON fr.currency = upper(e->>'currency')
+			INNER JOIN exchange_rates tr ON tr.currency = $2
+			WHERE (e->>'title' ILIKE $1 OR p.title ILIKE $1)
+			AND ($3 = '' OR p.country = $3)
+			AND p.visibility <> 'PRIVATE'
+			AND p.%[2]s
+			AND %[4]s
+		)
+		SELECT bucket, count(DISTINCT source_id), count(*),
+			percentile_cont(0.25) WITHIN GROUP (ORDER BY amount),
+			percentile_cont(0.5) WITHIN GROUP (ORDER BY amount),
+			percentile_cont(0.75) WITHIN GROUP (ORDER BY amount),
+			percentile_cont(0.9) WITHIN GROUP (ORDER BY amount),
+			avg(amount)
+		FROM samples
+		GROUP BY bucket`, monthlySalaryFactorSQL, listedModerationSQL, listedOwnerSQL("o"), listedOwnerSQL("p"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	args := []any{"%" + escapeLike(strings.TrimSpace(title)) + "%", currency, strings.TrimSpace(country)}
+
+	rows, err := m.DB.QueryContext(ctx, query, args...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	insights := &SalaryInsights{
+		Title:      title,
+		Currency:   currency,
+		Country:    country,
+		MinSamples: MinInsightSamples,
+	}
+
+	for rows.Next() {
+		var bucket string
+		var sources int
+		var stats SalaryStats
+		err := rows.Scan(
+			&bucket,
+			&sources,
+			&stats.Count,
+			&stats.P25,
+			&stats.Median,
+			&stats.P75,
+			&stats.P90,
+			&stats.Average,
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if sources < MinInsightSamples {
+			continue
+		}
+
+		switch bucket {
+		case "offers":
+			insights.Offers = &stats
+		case "candidates":
+			insights.Candidates = &stats
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	m.cache.set(key, insights)
+
+	return insights, nil
+}
+
+func newSalaryInsightsCache() *salaryInsightsCache {
+	return &salaryInsightsCache{entries: make(map[string]salaryInsightsEntry)}
+}
+
+func (c *salaryInsightsCache) get(key string) (*SalaryInsights, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	if time.Now().After(entry.expiresAt) {
+		delete(c.entries, key)
+		return nil, false
+	}
+
+	return entry.insights, true
+}
+
+func (c *salaryInsightsCache) set(key string, insights *SalaryInsights) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Expired entries are dropped on every write so the cache doesn't grow with queries
+	// that are never repeated.
+	now := time.Now()
+	for k, entry := range c.entries {
+		if now.After(entry.expiresAt) {
+			delete(c.entries, k)
+		}
+	}
+
+	c.entries[key] = salaryInsightsEntry{insights: insights, expiresAt: now.Add(salaryInsightsTTL)}
+}
+
+// escapeLike escapes the LIKE wildcards of the text so it's matched literally.
+func escapeLike(text string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(text)
+}
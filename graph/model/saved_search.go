@@ -0,0 +1,412 @@
+package model
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"itfinder.adrianescat.com/internal/validator"
+	"time"
+)
+
+const (
+	FrequencyDaily  = "daily"
+	FrequencyWeekly = "weekly"
+)
+
+type SavedSearch struct {
+	ID             int64      `json:"id"`
+	UserId         int64      `json:"user_id"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"-"`
+	Name           string     `json:"name"`
+	Keywords       string     `json:"keywords"`
+	MinSalary      *float64   `json:"min_salary"`
+	MaxSalary      *float64   `json:"max_salary"`
+	Currency       string     `json:"currency"`
+	Location       string     `json:"location"`
+	Frequency      string     `json:"frequency"`
+	LastNotifiedAt *time.Time `json:"last_notified_at"`
+	Version        int        `json:"-"`
+}
+
+// SavedSearchDigest groups the offers matched by a saved search that were not notified
+// to its owner yet.
+type SavedSearchDigest struct {
+	SavedSearchId int64
+	SearchName    string
+	Email         string
+	Name          string
+	Offers        []*Offer
+}
+
+type SavedSearchModel struct {
+	DB *sql.DB
+}
+
+func ValidateSavedSearch(v *validator.Validator, search *SavedSearch) {
+	v.Check(search.Name != "", "name", "must be provided")
+	v.Check(len(search.Name) <= 100, "name", "must not be more than 100 bytes long")
+
+	v.Check(len(search.Keywords) <= 200, "keywords", "must not be more than 200 bytes long")
+	v.Check(len(search.Location) <= 100, "location", "must not be more than 100 bytes long")
+
+	if search.MinSalary != nil {
+		v.Check(*search.MinSalary >= 0, "minSalary", "must not be negative")
+	}
+
+	if search.MaxSalary != nil {
+		v.Check(*search.MaxSalary >= 0, "maxSalary", "must not be negative")
+	}
+
+	if search.MinSalary != nil && search.MaxSalary != nil {
+		v.Check(*search.MinSalary <= *search.MaxSalary, "minSalary", "must not be greater than maxSalary")
+	}
+
+	if search.Currency != "" {
+		v.Check(validator.ValidCurrency(search.Currency), "currency", "must be an ISO 4217 currency code")
+	}
+
+	v.Check(
+		search.Keywords != "" || search.MinSalary != nil || search.MaxSalary != nil || search.Currency != "" || search.Location != "",
+		"search",
+		"at least one search criteria must be provided",
+	)
+
+	v.Check(validator.PermittedValue(search.Frequency, FrequencyDaily, FrequencyWeekly), "frequency", fmt.Sprintf("%s is not a permitted frequency", search.Frequency))
+}
+
+func (m SavedSearchModel) Insert(search *SavedSearch) error {
+	query := `
+		INSERT INTO saved_searches (user_id, name, keywords, min_salary, max_salary, currency, location, frequency)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), NULLIF($7, ''), $8)
+		RETURNING id, created_at, updated_at, version
+	`
+
+	args := []any{
+		search.UserId,
+		search.Name,
+		search.Keywords,
+		search.MinSalary,
+		search.MaxSalary,
+		search.Currency,
+		search.Location,
+		search.Frequency,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	return m.DB.QueryRowContext(ctx, query, args...).Scan(&search.ID, &search.CreatedAt, &search.UpdatedAt, &search.Version)
+}
+
+func (m SavedSearchModel) Get(id int64, userId int64) (*SavedSearch, error) {
+	if id < 1 {
+		return nil, ErrRecordNotFound
+	}
+
+	query := `
+		SELECT id, user_id, created_at, updated_at, name, keywords, min_salary, max_salary,
+			COALESCE(currency, ''), COALESCE(location, ''), frequency, last_notified_at, version
+		FROM saved_searches
+		WHERE id = $1 AND user_id = $2
+	`
+
+	var search SavedSearch
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, id, userId).Scan(
+		&search.ID,
+		&search.UserId,
+		&search.CreatedAt,
+		&search.UpdatedAt,
+		&search.Name,
+		&search.Keywords,
+		&search.MinSalary,
+		&search.MaxSalary,
+		&search.Currency,
+		&search.Location,
+		&search.Frequency,
+		&search.LastNotifiedAt,
+		&search.Version,
+	)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &search, nil
+}
+
+func (m SavedSearchModel) GetAllByUserId(userId int64) ([]*SavedSearch, error) {
+	query := `
+		SELECT id, user_id, created_at, updated_at, name, keywords, min_salary, max_salary,
+			COALESCE(currency, ''), COALESCE(location, ''), frequency, last_notified_at, version
+		FROM saved_searches
+		WHERE user_id = $1
+		ORDER BY created_at
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var searches []*SavedSearch
+
+	for rows.Next() {
+		var search SavedSearch
+		err := rows.Scan(
+			&search.ID,
+			&search.UserId,
+			&search.CreatedAt,
+			&search.UpdatedAt,
+			&search.Name,
+			&search.Keywords,
+			&search.MinSalary,
+			&search.MaxSalary,
+			&search.Currency,
+			&search.Location,
+			&search.Frequency,
+			&search.LastNotifiedAt,
+			&search.Version,
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		searches = append(searches, &search)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return searches, nil
+}
+
+func (m SavedSearchModel) Update(search *SavedSearch) error {
+	query := `
+		UPDATE saved_searches
+		SET name = $1, keywords = $2, min_salary = $3, max_salary = $4, currency = NULLIF($5, ''),
+			location = NULLIF($6, ''), frequency = $7, updated_at = NOW(), version = version + 1
+		WHERE id = $8 AND user_id = $9 AND version = $10
+		RETURNING updated_at, version
+	`
+
+	args := []any{
+		search.Name,
+		search.Keywords,
+		search.MinSalary,
+		search.MaxSalary,
+		search.Currency,
+		search.Location,
+		search.Frequency,
+		search.ID,
+		search.UserId,
+		search.Version,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, args...).Scan(&search.UpdatedAt, &search.Version)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrEditConflict
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m SavedSearchModel) Delete(id int64, userId int64) error {
+	query := `
+		DELETE FROM saved_searches
+		WHERE id = $1 AND user_id = $2
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, id, userId)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// RecordMatches stores a match for every saved search, from another user neither
+// suspended nor deleted, that the offer satisfies while it's published and listed. Every
+// keyword must be found as a whole word in the offer title or description, so "go"
+// doesn't match "good", and at least one salary entry must overlap the monthly salary
+// range in the requested currency. Keywords are matched literally, "c++" included. The
+// location is looked up in the offer location, with the country and state names, its work
+// mode and its text.
+func (m SavedSearchModel) RecordMatches(offerId int64) (int64, error) {
+	query := `
+		INSERT INTO saved_search_matches (saved_search_id, offer_id)
+		SELECT s.id, o.id
+		FROM saved_searches s
+		INNER JOIN offers o ON o.id = $1 AND o.active AND o.status = 'PUBLISHED' AND o.` + listedModerationSQL + `
+			AND ` + listedOwnerSQL("o") + ` AND o.user_id <> s.user_id
+		LEFT JOIN countries c ON c.code = o.country
+		LEFT JOIN subdivisions sd ON sd.code = o.state
+		WHERE ` + listedOwnerSQL("s") + `
+		AND NOT EXISTS (
+			SELECT 1 FROM regexp_split_to_table(lower(s.keywords), '\s+') k
+			WHERE k <> '' AND lower(o.title || ' ' || o.description)
+				!~ ('(^|[^[:alnum:]])' || regexp_replace(k, '([^[:alnum:]])', '\\\1', 'g') || '($|[^[:alnum:]])')
+		)
+		AND (
+			(s.currency IS NULL AND s.min_salary IS NULL AND s.max_salary IS NULL)
+			OR EXISTS (
+				SELECT 1 FROM jsonb_array_elements(CASE WHEN jsonb_typeof(o.salary) = 'array' THEN o.salary ELSE '[]'::jsonb END) e
+				WHERE (s.currency IS NULL OR upper(e->>'currency') = upper(s.currency))
+				AND (s.min_salary IS NULL OR (e->>'max')::numeric * ` + monthlySalaryFactorSQL + ` >= s.min_salary)
+				AND (s.max_salary IS NULL OR (e->>'min')::numeric * ` + monthlySalaryFactorSQL + ` <= s.max_salary)
+			)
+		)
+		AND (s.location IS NULL OR position(lower(s.location) in lower(concat_ws(' ',
+			o.title, o.description, o.country, c.name, o.state, sd.name, o.city, o.work_mode
+		))) > 0)
+		ON CONFLICT DO NOTHING
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, offerId)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// GetDueDigests returns the pending matches of the saved searches whose frequency
+// period elapsed since the last notification. Offers closed, rejected or banned meanwhile
+// are left out, and so are the offers and the searches of suspended or deleted users.
+func (m SavedSearchModel) GetDueDigests() ([]*SavedSearchDigest, error) {
+	query := `
+		SELECT s.id, s.name, u.email, u.name, o.id, o.title
+		FROM saved_search_matches sm
+		INNER JOIN saved_searches s ON s.id = sm.saved_search_id
+		INNER JOIN users u ON u.id = s.user_id AND u.suspended_at IS NULL AND u.deleted_at IS NULL
+		INNER JOIN offers o ON o.id = sm.offer_id AND o.active AND o.status = 'PUBLISHED' AND o.` + listedModerationSQL + `
+			AND ` + listedOwnerSQL("o") + `
+		WHERE sm.notified_at IS NULL
+		AND (
+			s.last_notified_at IS NULL
+			OR s.last_notified_at <= NOW() - CASE s.frequency WHEN 'daily' THEN interval '1 day' ELSE interval '7 days' END
+		)
+		ORDER BY s.id, sm.created_at
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var digests []*SavedSearchDigest
+	var current *SavedSearchDigest
+
+	for rows.Next() {
+		var digest SavedSearchDigest
+		var offer Offer
+		err := rows.Scan(
+			&digest.SavedSearchId,
+			&digest.SearchName,
+			&digest.Email,
+			&digest.Name,
+			&offer.ID,
+			&offer.Title,
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if current == nil || current.SavedSearchId != digest.SavedSearchId {
+			current = &digest
+			digests = append(digests, current)
+		}
+
+		current.Offers = append(current.Offers, &offer)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return digests, nil
+}
+
+// MarkNotified flags the digest offers as notified and restarts the frequency period
+// of the saved search.
+func (m SavedSearchModel) MarkNotified(digest *SavedSearchDigest) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	query := `
+		UPDATE saved_search_matches
+		SET notified_at = NOW()
+		WHERE saved_search_id = $1 AND offer_id = $2
+	`
+
+	for _, offer := range digest.Offers {
+		_, err = tx.ExecContext(ctx, query, digest.SavedSearchId, offer.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	query = `UPDATE saved_searches SET last_notified_at = NOW() WHERE id = $1`
+
+	_, err = tx.ExecContext(ctx, query, digest.SavedSearchId)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
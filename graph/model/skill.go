@@ -0,0 +1,486 @@
+package model
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"itfinder.adrianescat.com/internal/validator"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+var (
+	ErrDuplicateSkill = errors.New("duplicate skill")
+	ErrDuplicateAlias = errors.New("duplicate alias")
+)
+
+const (
+	SkillLevelBeginner     = "beginner"
+	SkillLevelIntermediate = "intermediate"
+	SkillLevelAdvanced     = "advanced"
+	SkillLevelExpert       = "expert"
+)
+
+var SkillLevels = []string{SkillLevelBeginner, SkillLevelIntermediate, SkillLevelAdvanced, SkillLevelExpert}
+
+type Skill struct {
+	ID        int64     `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	Name      string    `json:"name"`
+	Aliases   []string  `json:"aliases"`
+}
+
+type ProfileSkill struct {
+	ProfileId int64  `json:"profile_id"`
+	SkillId   int64  `json:"skill_id"`
+	Skill     *Skill `json:"skill"`
+	Years     int    `json:"years"`
+	Level     string `json:"level"`
+}
+
+type OfferSkill struct {
+	OfferId  int64  `json:"offer_id"`
+	SkillId  int64  `json:"skill_id"`
+	Skill    *Skill `json:"skill"`
+	Required bool   `json:"required"`
+}
+
+type SkillModel struct {
+	DB *sql.DB
+}
+
+// skillColumns selects a skill, aliased as s, together with its aliases.
+const skillColumns = `s.id, s.created_at, s.name,
+	COALESCE((SELECT array_agg(a.alias ORDER BY a.alias) FROM skill_aliases a WHERE a.skill_id = s.id), '{}')`
+
+func ValidateSkill(v *validator.Validator, skill *Skill) {
+	v.Check(strings.TrimSpace(skill.Name) != "", "name", "must be provided")
+	v.Check(len(skill.Name) <= 50, "name", "must not be more than 50 bytes long")
+
+	ValidateSkillAliases(v, skill.Aliases)
+}
+
+func ValidateSkillAliases(v *validator.Validator, aliases []string) {
+	for i, alias := range aliases {
+		key := fmt.Sprintf("aliases[%d]", i)
+
+		v.Check(alias != "", key, "must be provided")
+		v.Check(len(alias) <= 50, key, "must not be more than 50 bytes long")
+	}
+
+	v.Check(validator.Unique(aliases), "aliases", "must not contain duplicate values")
+}
+
+func ValidateProfileSkills(v *validator.Validator, skills []*ProfileSkill) {
+	seen := make(map[int64]bool, len(skills))
+
+	for i, s := range skills {
+		key := fmt.Sprintf("skills[%d]", i)
+
+		v.Check(s.Years >= 0, key+".years", "must not be negative")
+		v.Check(s.Years <= 60, key+".years", "must not be greater than 60")
+		v.Check(validator.PermittedValue(s.Level, SkillLevels...), key+".level", fmt.Sprintf("%s is not a permitted level", s.Level))
+
+		v.Check(!seen[s.SkillId], key+".skill", "must not be repeated")
+		seen[s.SkillId] = true
+	}
+}
+
+func ValidateOfferSkills(v *validator.Validator, skills []*OfferSkill) {
+	seen := make(map[int64]bool, len(skills))
+
+	for i, s := range skills {
+		v.Check(!seen[s.SkillId], fmt.Sprintf("skills[%d].skill", i), "must not be repeated")
+		seen[s.SkillId] = true
+	}
+}
+
+// NormalizeSkillAliases trims and lower-cases the aliases, the way they are stored.
+func NormalizeSkillAliases(aliases []string) []string {
+	normalized := make([]string, 0, len(aliases))
+
+	for _, alias := range aliases {
+		normalized = append(normalized, strings.ToLower(strings.TrimSpace(alias)))
+	}
+
+	return normalized
+}
+
+// Insert creates the skill and its aliases in a single transaction.
+func (m SkillModel) Insert(skill *Skill) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO skills (name)
+		VALUES ($1)
+		RETURNING id, created_at
+	`
+
+	err = tx.QueryRowContext(ctx, query, skill.Name).Scan(&skill.ID, &skill.CreatedAt)
+	if err != nil {
+		return skillError(err)
+	}
+
+	err = insertSkillAliases(ctx, tx, skill.ID, skill.Aliases)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// AddAliases links new aliases to an existing skill.
+func (m SkillModel) AddAliases(skillId int64, aliases []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	err = insertSkillAliases(ctx, tx, skillId, aliases)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (m SkillModel) Get(id int64) (*Skill, error) {
+	if id < 1 {
+		return nil, ErrRecordNotFound
+	}
+
+	query := `SELECT ` + skillColumns + ` FROM skills s WHERE s.id = $1`
+
+	var skill Skill
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, id).Scan(
+		&skill.ID,
+		&skill.CreatedAt,
+		&skill.Name,
+		pq.Array(&skill.Aliases),
+	)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &skill, nil
+}
+
+// GetAll lists the skills whose name or any of its aliases contains the search text.
+func (m SkillModel) GetAll(search string) ([]*Skill, error) {
+	query := `
+		SELECT ` + skillColumns + `
+		FROM skills s
+		WHERE $1 = ''
+		OR s.name ILIKE '%' || $1 || '%'
+		OR EXISTS (SELECT 1 FROM skill_aliases a WHERE a.skill_id = s.id AND a.alias LIKE '%' || lower($1) || '%')
+		ORDER BY s.name
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, escapeLike(strings.TrimSpace(search)))
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var skills []*Skill
+
+	for rows.Next() {
+		var skill Skill
+		err := rows.Scan(
+			&skill.ID,
+			&skill.CreatedAt,
+			&skill.Name,
+			pq.Array(&skill.Aliases),
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		skills = append(skills, &skill)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return skills, nil
+}
+
+// ResolveNames maps every name, matched case-insensitively against the skill names and
+// aliases, to its skill id. Names of unknown skills are left out of the map.
+func (m SkillModel) ResolveNames(names []string) (map[string]int64, error) {
+	keys := make([]string, 0, len(names))
+	for _, name := range names {
+		keys = append(keys, strings.ToLower(strings.TrimSpace(name)))
+	}
+
+	query := `
+		SELECT lower(s.name), s.id FROM skills s WHERE lower(s.name) = ANY($1)
+		UNION
+		SELECT a.alias, a.skill_id FROM skill_aliases a WHERE a.alias = ANY($1)
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, pq.Array(keys))
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	resolved := make(map[string]int64, len(keys))
+
+	for rows.Next() {
+		var key string
+		var id int64
+
+		err := rows.Scan(&key, &id)
+		if err != nil {
+			return nil, err
+		}
+
+		resolved[key] = id
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return resolved, nil
+}
+
+// SetProfileSkills replaces the skills of the profile.
+func (m SkillModel) SetProfileSkills(profileId int64, skills []*ProfileSkill) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM profile_skills WHERE profile_id = $1`, profileId)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO profile_skills (profile_id, skill_id, years, level)
+		VALUES ($1, $2, $3, $4)
+	`
+
+	for _, s := range skills {
+		s.ProfileId = profileId
+
+		_, err = tx.ExecContext(ctx, query, s.ProfileId, s.SkillId, s.Years, s.Level)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// SetOfferSkills replaces the skills of the offer.
+func (m SkillModel) SetOfferSkills(offerId int64, skills []*OfferSkill) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM offer_skills WHERE offer_id = $1`, offerId)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO offer_skills (offer_id, skill_id, required)
+		VALUES ($1, $2, $3)
+	`
+
+	for _, s := range skills {
+		s.OfferId = offerId
+
+		_, err = tx.ExecContext(ctx, query, s.OfferId, s.SkillId, s.Required)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (m SkillModel) GetByProfileId(profileId int64) ([]*ProfileSkill, error) {
+	query := `
+		SELECT ps.profile_id, ps.years, ps.level, ` + skillColumns + `
+		FROM profile_skills ps
+		INNER JOIN skills s ON s.id = ps.skill_id
+		WHERE ps.profile_id = $1
+		ORDER BY ps.years DESC, s.name
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, profileId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var skills []*ProfileSkill
+
+	for rows.Next() {
+		var profileSkill ProfileSkill
+		var skill Skill
+		err := rows.Scan(
+			&profileSkill.ProfileId,
+			&profileSkill.Years,
+			&profileSkill.Level,
+			&skill.ID,
+			&skill.CreatedAt,
+			&skill.Name,
+			pq.Array(&skill.Aliases),
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		profileSkill.SkillId = skill.ID
+		profileSkill.Skill = &skill
+
+		skills = append(skills, &profileSkill)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return skills, nil
+}
+
+// GetByOfferId returns the skills of the offer, the required ones first.
+func (m SkillModel) GetByOfferId(offerId int64) ([]*OfferSkill, error) {
+	query := `
+		SELECT os.offer_id, os.required, ` + skillColumns + `
+		FROM offer_skills os
+		INNER JOIN skills s ON s.id = os.skill_id
+		WHERE os.offer_id = $1
+		ORDER BY os.required DESC, s.name
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, offerId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var skills []*OfferSkill
+
+	for rows.Next() {
+		var offerSkill OfferSkill
+		var skill Skill
+		err := rows.Scan(
+			&offerSkill.OfferId,
+			&offerSkill.Required,
+			&skill.ID,
+			&skill.CreatedAt,
+			&skill.Name,
+			pq.Array(&skill.Aliases),
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		offerSkill.SkillId = skill.ID
+		offerSkill.Skill = &skill
+
+		skills = append(skills, &offerSkill)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return skills, nil
+}
+
+func insertSkillAliases(ctx context.Context, tx *sql.Tx, skillId int64, aliases []string) error {
+	query := `
+		INSERT INTO skill_aliases (alias, skill_id)
+		VALUES ($1, $2)
+	`
+
+	for _, alias := range aliases {
+		_, err := tx.ExecContext(ctx, query, alias, skillId)
+		if err != nil {
+			return skillError(err)
+		}
+	}
+
+	return nil
+}
+
+func skillError(err error) error {
+	switch {
+	case err.Error() == `pq: duplicate key value violates unique constraint "skills_lower_name_idx"`:
+		return ErrDuplicateSkill
+	case err.Error() == `pq: duplicate key value violates unique constraint "skill_aliases_pkey"`:
+		return ErrDuplicateAlias
+	case err.Error() == `pq: insert or update on table "skill_aliases" violates foreign key constraint "skill_aliases_skill_id_fkey"`:
+		return ErrRecordNotFound
+	default:
+		return err
+	}
+}
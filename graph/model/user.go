@@ -30,6 +30,12 @@ type User struct {
 	Activated bool      `json:"activated"`
 	Version   int       `json:"-"`
 	Roles     []string  `json:"roles"`
+	// SuspendedAt is set while an admin keeps the user from logging in.
+	SuspendedAt *time.Time `json:"suspended_at"`
+	// DeletedAt is set once the user deleted their account, which is anonymized and
+	// purged after the grace period.
+	DeletedAt *time.Time `json:"deleted_at"`
+	public    bool
 }
 
 type Password struct {
@@ -176,7 +182,7 @@ func (m *UserModel) Insert(user *User) error {
 
 func (m *UserModel) GetAll() ([]*User, error) {
 	query := `
-		SELECT id, created_at, updated_at, name, lastname, email, activated, version
+		SELECT id, created_at, updated_at, name, lastname, email, activated, version, suspended_at, deleted_at
 		FROM users`
 
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
@@ -205,6 +211,8 @@ func (m *UserModel) GetAll() ([]*User, error) {
 			&user.Email,
 			&user.Activated,
 			&user.Version,
+			&user.SuspendedAt,
+			&user.DeletedAt,
 		)
 
 		if err != nil {
@@ -224,7 +232,7 @@ func (m *UserModel) GetAll() ([]*User, error) {
 func (m *UserModel) GetUsersByIds(ids []string) ([]*User, error) {
 	idString := strings.Join(ids, ",")
 
-	query := fmt.Sprintf("SELECT id, created_at, updated_at, name, lastname, email, activated, version FROM users WHERE id IN (%s)", idString)
+	query := fmt.Sprintf("SELECT id, created_at, updated_at, name, lastname, email, activated, version, suspended_at, deleted_at FROM users WHERE id IN (%s)", idString)
 
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
@@ -250,6 +258,8 @@ func (m *UserModel) GetUsersByIds(ids []string) ([]*User, error) {
 			&user.Email,
 			&user.Activated,
 			&user.Version,
+			&user.SuspendedAt,
+			&user.DeletedAt,
 		)
 
 		if err != nil {
@@ -268,7 +278,7 @@ func (m *UserModel) GetUsersByIds(ids []string) ([]*User, error) {
 
 func (m *UserModel) GetById(id int64) (*User, error) {
 	query := `
-		SELECT id, created_at, name, lastname, email, activated, version
+		SELECT id, created_at, name, lastname, email, activated, version, suspended_at, deleted_at
 		FROM users
 		WHERE id = $1
 	`
@@ -286,6 +296,8 @@ func (m *UserModel) GetById(id int64) (*User, error) {
 		&user.Email,
 		&user.Activated,
 		&user.Version,
+		&user.SuspendedAt,
+		&user.DeletedAt,
 	)
 
 	if err != nil {
@@ -347,13 +359,15 @@ func (m *UserModel) GetForToken(tokenScope, tokenPlaintext string) (*User, error
 
 	// Set up the SQL query.
 	query := `
-		SELECT id, created_at, name, lastname,  email, activated, version
+		SELECT id, created_at, name, lastname,  email, activated, version, suspended_at, deleted_at
 		FROM users
 		INNER JOIN tokens
 		ON id = tokens.user_id
 		WHERE tokens.hash = $1
 		AND tokens.scope = $2
 		AND tokens.expiry > $3
+		AND users.suspended_at IS NULL
+		AND users.deleted_at IS NULL
 	`
 
 	// Create a slice containing the query arguments. Notice how we use the [:] operator
@@ -377,6 +391,8 @@ func (m *UserModel) GetForToken(tokenScope, tokenPlaintext string) (*User, error
 		&user.Email,
 		&user.Activated,
 		&user.Version,
+		&user.SuspendedAt,
+		&user.DeletedAt,
 	)
 
 	if err != nil {
@@ -394,7 +410,7 @@ func (m *UserModel) GetForToken(tokenScope, tokenPlaintext string) (*User, error
 
 func (m *UserModel) GetByEmail(email string) (*User, error) {
 	query := `
-		SELECT id, created_at, updated_at, name, lastname, email, activated, password_hash, version
+		SELECT id, created_at, updated_at, name, lastname, email, activated, password_hash, version, suspended_at, deleted_at
 		FROM users
 		WHERE email = $1
 	`
@@ -413,6 +429,8 @@ func (m *UserModel) GetByEmail(email string) (*User, error) {
 		&user.Activated,
 		&user.Password.Hash,
 		&user.Version,
+		&user.SuspendedAt,
+		&user.DeletedAt,
 	)
 
 	if err != nil {
@@ -429,11 +447,13 @@ func (m *UserModel) GetByEmail(email string) (*User, error) {
 
 func (m *UserModel) GetAllBookmarksByUserId(id int64) ([]*Profile, error) {
 	query := `
-		SELECT p.id, p.user_id, p.created_at, p.title, p.about, p.status, p.country, p.state, p.city, p.picture_url, p.website_url, p.salary, p.version
+		SELECT p.id, p.user_id, p.created_at, p.title, p.about, p.status, p.country, p.state, p.city, p.picture_url, p.website_url, p.salary, p.version, p.visibility, p.moderation_status
 		FROM profiles p
 		INNER JOIN profile_bookmarks pb on p.id = pb.profile_id
 		LEFT JOIN users u on u.id = pb.user_id
 		WHERE u.id = $1
+		AND p.` + listedModerationSQL + `
+		AND ` + listedOwnerSQL("p") + `
 	`
 
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
@@ -466,6 +486,8 @@ func (m *UserModel) GetAllBookmarksByUserId(id int64) ([]*Profile, error) {
 			&profile.WebsiteUrl,
 			&salaries,
 			&profile.Version,
+			&profile.Visibility,
+			&profile.ModerationStatus,
 		)
 
 		if err != nil {
@@ -494,7 +516,7 @@ func (m *UserModel) CreateProfileBookmark(userId int64, profileId int64) error {
 	query := `
 		INSERT INTO profile_bookmarks (user_id, profile_id)
 		VALUES ($1, $2)
-		RETURNING user_id, profile_id 
+		ON CONFLICT (user_id, profile_id) DO NOTHING
 	`
 
 	args := []any{userId, profileId}
@@ -532,3 +554,20 @@ func (m *UserModel) DeleteProfileBookmark(userId int64, profileId int64) error {
 func (u *User) IsAnonymous() bool {
 	return u == AnonymousUser
 }
+
+// Public returns a copy of the user with only the fields anyone can see, the internal
+// ones are left for the resolvers to hide.
+func (u *User) Public() *User {
+	return &User{
+		ID:        u.ID,
+		CreatedAt: u.CreatedAt,
+		Name:      u.Name,
+		Lastname:  u.Lastname,
+		Activated: u.Activated,
+		public:    true,
+	}
+}
+
+func (u *User) IsPublic() bool {
+	return u.public
+}
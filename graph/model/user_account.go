@@ -0,0 +1,247 @@
+package model
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErasedFiles are the stored files of an account that must be deleted from the storage
+// once its rows are gone.
+type ErasedFiles struct {
+	ResumeKeys  []string
+	PictureUrls []string
+}
+
+// listedOwnerSQL is the condition on the owner of the offers and the profiles shown in
+// the listings: the content of suspended and deleted users is left out.
+func listedOwnerSQL(alias string) string {
+	return fmt.Sprintf(`NOT EXISTS (
+		SELECT 1 FROM users u WHERE u.id = %s.user_id AND (u.suspended_at IS NOT NULL OR u.deleted_at IS NOT NULL)
+	)`, alias)
+}
+
+// IsListed reports whether the content of the user is shown to others, it isn't while
+// they are suspended or once they deleted their account.
+func (u *User) IsListed() bool {
+	return u.SuspendedAt == nil && u.DeletedAt == nil
+}
+
+// Suspend keeps the user from logging in and revokes all their tokens. Suspending a
+// suspended user keeps the date of the first suspension.
+func (m *UserModel) Suspend(user *User) error {
+	return m.setSuspended(user, true)
+}
+
+// Unsuspend lets the user log in again.
+func (m *UserModel) Unsuspend(user *User) error {
+	return m.setSuspended(user, false)
+}
+
+func (m *UserModel) setSuspended(user *User, suspended bool) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	query := `
+		UPDATE users
+		SET suspended_at = CASE WHEN $2 THEN COALESCE(suspended_at, NOW()) END,
+			updated_at = NOW(), version = version + 1
+		WHERE id = $1 AND deleted_at IS NULL
+		RETURNING suspended_at, version
+	`
+
+	err = tx.QueryRowContext(ctx, query, user.ID, suspended).Scan(&user.SuspendedAt, &user.Version)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrRecordNotFound
+		default:
+			return err
+		}
+	}
+
+	if suspended {
+		_, err = tx.ExecContext(ctx, `DELETE FROM tokens WHERE user_id = $1`, user.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Anonymize deletes the account of the user: their tokens are revoked and their personal
+// data is erased, while the rows counted by the statistics, like their applications, are
+// kept until the account is purged. It returns the files to delete from the storage.
+func (m *UserModel) Anonymize(user *User) (*ErasedFiles, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	defer tx.Rollback()
+
+	// The email must stay unique, and an empty hash never matches a password.
+	query := `
+		UPDATE users
+		SET name = 'Deleted', lastname = 'user', email = 'deleted-' || id || '@users.invalid',
+			password_hash = '', activated = false, deleted_at = NOW(), updated_at = NOW(), version = version + 1
+		WHERE id = $1 AND deleted_at IS NULL
+		RETURNING name, lastname, email, activated, deleted_at, version
+	`
+
+	err = tx.QueryRowContext(ctx, query, user.ID).Scan(
+		&user.Name,
+		&user.Lastname,
+		&user.Email,
+		&user.Activated,
+		&user.DeletedAt,
+		&user.Version,
+	)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	files := &ErasedFiles{}
+
+	query = `
+		WITH previous AS (
+			SELECT id, picture_url FROM profiles WHERE user_id = $1 FOR UPDATE
+		)
+		UPDATE profiles p
+		SET about = '', city = '', state = '', picture_url = '', website_url = '', latitude = NULL, longitude = NULL,
+			visibility = 'PRIVATE', updated_at = NOW(), version = version + 1
+		FROM previous
+		WHERE p.id = previous.id
+		RETURNING previous.picture_url
+	`
+
+	files.PictureUrls, err = queryStrings(ctx, tx, query, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	files.ResumeKeys, err = queryStrings(ctx, tx, `DELETE FROM resumes WHERE user_id = $1 RETURNING storage_key`, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	statements := []string{
+		`DELETE FROM tokens WHERE user_id = $1`,
+		`DELETE FROM saved_searches WHERE user_id = $1`,
+		`DELETE FROM profile_experiences WHERE profile_id IN (SELECT id FROM profiles WHERE user_id = $1)`,
+		`DELETE FROM profile_educations WHERE profile_id IN (SELECT id FROM profiles WHERE user_id = $1)`,
+		`DELETE FROM application_answers WHERE profile_id IN (SELECT id FROM profiles WHERE user_id = $1)`,
+		`UPDATE offers_applicants SET cover_letter = NULL WHERE profile_id IN (SELECT id FROM profiles WHERE user_id = $1)`,
+	}
+
+	for _, statement := range statements {
+		_, err = tx.ExecContext(ctx, statement, user.ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return files, nil
+}
+
+// PurgeDeleted deletes the accounts deleted before the grace period, with everything that
+// belongs to them, and returns how many there were and the pictures of their offers.
+// Offer views and revisions are kept without their user.
+func (m *UserModel) PurgeDeleted(gracePeriod time.Duration) (int64, []string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	defer tx.Rollback()
+
+	before := time.Now().Add(-gracePeriod)
+
+	query := `
+		SELECT o.picture_url
+		FROM offers o
+		INNER JOIN users u ON u.id = o.user_id
+		WHERE u.deleted_at <= $1 AND COALESCE(o.picture_url, '') <> ''
+	`
+
+	pictures, err := queryStrings(ctx, tx, query, before)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM users WHERE deleted_at <= $1`, before)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	purged, err := result.RowsAffected()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return purged, pictures, nil
+}
+
+// queryStrings returns the single text column of the rows of the query, skipping the
+// empty values.
+func queryStrings(ctx context.Context, tx *sql.Tx, query string, args ...any) ([]string, error) {
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var values []string
+
+	for rows.Next() {
+		var value sql.NullString
+
+		err := rows.Scan(&value)
+		if err != nil {
+			return nil, err
+		}
+
+		if value.String != "" {
+			values = append(values, value.String)
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
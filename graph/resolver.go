@@ -3,8 +3,13 @@
 package graph
 
 import (
+	"time"
+
 	"itfinder.adrianescat.com/graph/model"
 	"itfinder.adrianescat.com/internal/jsonlog"
+	"itfinder.adrianescat.com/internal/mailer"
+	"itfinder.adrianescat.com/internal/signedurl"
+	"itfinder.adrianescat.com/internal/storage"
 )
 
 // This file will not be regenerated automatically.
@@ -14,4 +19,22 @@ import (
 type Resolver struct {
 	Models model.Models
 	Logger *jsonlog.Logger
+	Mailer mailer.Mailer
+	// Storage keeps the uploaded files.
+	Storage storage.Storage
+	// PublicURL is the URL the API is reachable at, used to build the links to the
+	// files it serves.
+	PublicURL string
+	// Signer signs the download URLs of the private files, which are valid for
+	// DownloadURLTTL.
+	Signer         *signedurl.Signer
+	DownloadURLTTL time.Duration
+	// Background runs the function in a goroutine tracked by the graceful shutdown.
+	Background func(fn func())
+	// BlockDuplicateOffers makes createOffer reject the near-duplicates of the existing
+	// offers instead of only listing them in Offer.duplicates.
+	BlockDuplicateOffers bool
+	// AccountDeletionGracePeriod is how long the deleted accounts are kept, anonymized,
+	// before the purge job deletes them.
+	AccountDeletionGracePeriod time.Duration
 }
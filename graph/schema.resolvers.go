@@ -9,14 +9,232 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"net/http"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/99designs/gqlgen/graphql"
 	"itfinder.adrianescat.com/graph/dataloaders"
 	"itfinder.adrianescat.com/graph/model"
+	"itfinder.adrianescat.com/internal/geo"
+	"itfinder.adrianescat.com/internal/imaging"
+	"itfinder.adrianescat.com/internal/matching"
 	"itfinder.adrianescat.com/internal/validator"
 )
 
+// Offer is the resolver for the offer field.
+func (r *applicationResolver) Offer(ctx context.Context, obj *model.Application) (*model.Offer, error) {
+	if obj.Offer != nil {
+		return obj.Offer, nil
+	}
+
+	offer, err := r.Models.Offers.GetById(obj.OfferId)
+
+	if err != nil {
+		r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+		return nil, err
+	}
+
+	return offer, nil
+}
+
+// Profile is the resolver for the profile field.
+func (r *applicationResolver) Profile(ctx context.Context, obj *model.Application) (*model.Profile, error) {
+	if obj.Profile != nil {
+		return obj.Profile, nil
+	}
+
+	profile, err := r.Models.Profiles.GetProfileById(obj.ProfileId)
+
+	if err != nil {
+		r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+		return nil, err
+	}
+
+	return profile, nil
+}
+
+// MatchScore is the resolver for the matchScore field.
+func (r *applicationResolver) MatchScore(ctx context.Context, obj *model.Application) (int, error) {
+	offer := obj.Offer
+	if offer == nil {
+		var err error
+		offer, err = r.Models.Offers.GetById(obj.OfferId)
+		if err != nil {
+			r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+			return 0, err
+		}
+	}
+
+	profile := obj.Profile
+	if profile == nil {
+		var err error
+		profile, err = r.Models.Profiles.GetProfileById(obj.ProfileId)
+		if err != nil {
+			r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+			return 0, err
+		}
+	}
+
+	return matching.Score(matchingOffer(offer), matchingCandidate(profile)).Total, nil
+}
+
+// Answers is the resolver for the answers field.
+func (r *applicationResolver) Answers(ctx context.Context, obj *model.Application) ([]*model.ApplicationAnswer, error) {
+	if obj.Answers != nil {
+		return obj.Answers, nil
+	}
+
+	answers, err := r.Models.Applications.GetAnswers(obj.OfferId, obj.ProfileId)
+
+	if err != nil {
+		r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+		return nil, err
+	}
+
+	return answers, nil
+}
+
+// Profile is the resolver for the profile field.
+func (r *bookmarkResolver) Profile(ctx context.Context, obj *model.Bookmark) (*model.Profile, error) {
+	if obj.ProfileId == nil {
+		return nil, nil
+	}
+
+	profile, err := r.Models.Profiles.GetProfileById(*obj.ProfileId)
+
+	if err != nil {
+		r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+		return nil, err
+	}
+
+	return profile, nil
+}
+
+// Offer is the resolver for the offer field.
+func (r *bookmarkResolver) Offer(ctx context.Context, obj *model.Bookmark) (*model.Offer, error) {
+	if obj.OfferId == nil {
+		return nil, nil
+	}
+
+	offer, err := r.Models.Offers.GetById(*obj.OfferId)
+
+	if err != nil {
+		r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+		return nil, err
+	}
+
+	return offer, nil
+}
+
+// Items is the resolver for the items field.
+func (r *bookmarkCollectionResolver) Items(ctx context.Context, obj *model.BookmarkCollection) ([]*model.Bookmark, error) {
+	bookmarks, err := r.Models.Bookmarks.GetItemsByCollectionId(obj.ID)
+
+	if err != nil {
+		r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+		return nil, err
+	}
+
+	return bookmarks, nil
+}
+
+// Members is the resolver for the members field.
+func (r *companyResolver) Members(ctx context.Context, obj *model.Company) ([]*model.CompanyMember, error) {
+	user, err := RequireAuthAndActivatedUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = r.requireCompanyMember(user, obj.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := r.Models.Companies.GetMembers(obj.ID)
+
+	if err != nil {
+		r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+		return nil, err
+	}
+
+	return members, nil
+}
+
+// Offers is the resolver for the offers field.
+func (r *companyResolver) Offers(ctx context.Context, obj *model.Company) ([]*model.Offer, error) {
+	member := false
+
+	if user := ctx.Value("user").(*model.User); user != nil && !user.IsAnonymous() {
+		_, err := r.Models.Companies.GetMember(obj.ID, user.ID)
+		switch {
+		case err == nil:
+			member = true
+		case !errors.Is(err, model.ErrRecordNotFound):
+			r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+			return nil, err
+		}
+	}
+
+	offers, err := r.Models.Offers.GetAllByCompanyId(obj.ID, !member)
+
+	if err != nil {
+		r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+		return nil, err
+	}
+
+	return offers, nil
+}
+
+// User is the resolver for the user field.
+func (r *companyMemberResolver) User(ctx context.Context, obj *model.CompanyMember) (*model.User, error) {
+	return dataloaders.For(ctx).GetUser(ctx, strconv.FormatInt(obj.UserId, 10))
+}
+
+// Moderator is the resolver for the moderator field.
+func (r *moderationDecisionResolver) Moderator(ctx context.Context, obj *model.ModerationDecision) (*model.User, error) {
+	if obj.ModeratorId == nil {
+		return nil, nil
+	}
+
+	return dataloaders.For(ctx).GetUser(ctx, strconv.FormatInt(*obj.ModeratorId, 10))
+}
+
+// Offer is the resolver for the offer field.
+func (r *moderationItemResolver) Offer(ctx context.Context, obj *model.ModerationItem) (*model.Offer, error) {
+	if obj.OfferId == nil {
+		return nil, nil
+	}
+
+	offer, err := r.Models.Offers.GetById(*obj.OfferId)
+
+	if err != nil {
+		r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+		return nil, err
+	}
+
+	return offer, nil
+}
+
+// Profile is the resolver for the profile field.
+func (r *moderationItemResolver) Profile(ctx context.Context, obj *model.ModerationItem) (*model.Profile, error) {
+	if obj.ProfileId == nil {
+		return nil, nil
+	}
+
+	profile, err := r.Models.Profiles.GetProfileById(*obj.ProfileId)
+
+	if err != nil {
+		r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+		return nil, err
+	}
+
+	return profile, nil
+}
+
 // CreateUser is the resolver for the createUser field.
 func (r *mutationResolver) CreateUser(ctx context.Context, input model.NewUserInput) (*model.User, error) {
 	_, err := RequireAuthAndActivatedUser(ctx)
@@ -40,7 +258,7 @@ func (r *mutationResolver) CreateUser(ctx context.Context, input model.NewUserIn
 	v := validator.New()
 
 	if model.ValidateUser(v, user); !v.Valid() {
-		return nil, errors.New("wrong inputs")
+		return nil, failedValidationError(v)
 	}
 
 	err = r.Models.Users.Insert(user)
@@ -55,7 +273,7 @@ func (r *mutationResolver) CreateUser(ctx context.Context, input model.NewUserIn
 
 // CreateOffer is the resolver for the createOffer field.
 func (r *mutationResolver) CreateOffer(ctx context.Context, input model.NewOfferInput) (*model.Offer, error) {
-	_, err := RequireAuthAndActivatedUser(ctx)
+	user, err := RequireAuthAndActivatedUser(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -68,15 +286,67 @@ func (r *mutationResolver) CreateOffer(ctx context.Context, input model.NewOffer
 	offer := &model.Offer{
 		Title:       input.Title,
 		UserId:      uId,
-		PictureUrl:  input.PictureURL,
 		Description: input.Description,
 		Salary:      input.Salary,
+		Questions:   offerQuestionsFromInput(input.Questions),
+		WorkMode:    input.WorkMode,
+	}
+
+	if input.Country != nil {
+		offer.Country = geo.NormalizeCountry(*input.Country)
+	}
+
+	if input.State != nil {
+		offer.State = geo.NormalizeSubdivision(offer.Country, *input.State)
+	}
+
+	if input.City != nil {
+		offer.City = strings.TrimSpace(*input.City)
+	}
+
+	if input.PictureURL != nil {
+		offer.PictureUrl = *input.PictureURL
+	}
+
+	offer.PublishAt = input.PublishAt
+	offer.ExpiresAt = input.ExpiresAt
+	offer.Status = input.Status
+
+	if input.CompanyID != nil {
+		cId, err := strconv.ParseInt(*input.CompanyID, 10, 64)
+		if err != nil {
+			return nil, errors.New("wrong company_id type")
+		}
+
+		_, err = r.requireCompanyMember(user, cId)
+		if err != nil {
+			return nil, err
+		}
+
+		offer.CompanyId = &cId
 	}
 
+	offer.Latitude, offer.Longitude = model.Geocode(offer.Country, offer.State, offer.City)
+
+	offer.Salary.NormalizeCurrencies()
+
 	v := validator.New()
 
-	if model.ValidateOffer(v, offer); !v.Valid() {
-		return nil, errors.New("wrong inputs")
+	model.ValidateOffer(v, offer)
+
+	if model.ValidateOfferPublication(v, offer); !v.Valid() {
+		return nil, failedValidationError(v)
+	}
+
+	offer.Duplicates, err = r.Models.Offers.FindDuplicates(offer)
+	if err != nil {
+		r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+		return nil, err
+	}
+
+	if len(offer.Duplicates) > 0 && r.BlockDuplicateOffers {
+		v.AddError("offer", fmt.Sprintf("looks like a duplicate of the offer %d", offer.Duplicates[0].DuplicateOf.ID))
+		return nil, failedValidationError(v)
 	}
 
 	err = r.Models.Offers.Insert(offer)
@@ -86,6 +356,11 @@ func (r *mutationResolver) CreateOffer(ctx context.Context, input model.NewOffer
 		return nil, err
 	}
 
+	// Scheduled offers are matched against the saved searches once they are published.
+	if offer.Active {
+		r.recordSavedSearchMatches(offer.ID)
+	}
+
 	return offer, nil
 }
 
@@ -106,18 +381,25 @@ func (r *mutationResolver) CreateProfile(ctx context.Context, input model.NewPro
 		Title:      input.Title,
 		About:      input.About,
 		Status:     input.Status,
-		Country:    input.Country,
-		State:      input.State,
-		City:       input.City,
-		PictureUrl: input.PictureURL,
+		Country:    geo.NormalizeCountry(input.Country),
+		City:       strings.TrimSpace(input.City),
 		WebsiteUrl: input.WebsiteURL,
 		Salary:     input.Salary,
 	}
 
+	if input.PictureURL != nil {
+		profile.PictureUrl = *input.PictureURL
+	}
+
+	profile.State = geo.NormalizeSubdivision(profile.Country, input.State)
+	profile.Latitude, profile.Longitude = model.Geocode(profile.Country, profile.State, profile.City)
+
+	profile.Salary.NormalizeCurrencies()
+
 	v := validator.New()
 
 	if model.ValidateProfile(v, profile); !v.Valid() {
-		return nil, errors.New("wrong inputs")
+		return nil, failedValidationError(v)
 	}
 
 	err = r.Models.Profiles.Insert(profile)
@@ -163,6 +445,10 @@ func (r *mutationResolver) CreateAuthToken(ctx context.Context, input model.Auth
 		return nil, errors.New("invalid credentials")
 	}
 
+	if user.SuspendedAt != nil {
+		return nil, errors.New("your account is suspended")
+	}
+
 	token, err := r.Models.Tokens.New(user.ID, 24*time.Hour, model.ScopeAuthentication)
 	if err != nil {
 		return nil, errors.New("server error")
@@ -266,207 +552,2902 @@ func (r *mutationResolver) DeleteBookmark(ctx context.Context, userID string, pr
 	}, nil
 }
 
-// ApplyToOffer is the resolver for the applyToOffer field.
-func (r *mutationResolver) ApplyToOffer(ctx context.Context, offerID string, profileID string) (*model.ApplyResponse, error) {
-	_, err := RequireAuthAndActivatedUser(ctx)
+// CreateOfferBookmark is the resolver for the createOfferBookmark field.
+func (r *mutationResolver) CreateOfferBookmark(ctx context.Context, userID string, offerID string) (*model.BookmarkResponse, error) {
+	user, err := RequireAuthAndActivatedUser(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	// TODO: check if the passed profile_id is from the user making the request
+	uId, err := strconv.ParseInt(userID, 10, 64)
+	if err != nil {
+		return nil, errors.New("wrong user_id type")
+	}
 
 	oId, err := strconv.ParseInt(offerID, 10, 64)
 	if err != nil {
 		return nil, errors.New("wrong offer_id type")
 	}
 
-	pId, err := strconv.ParseInt(profileID, 10, 64)
-	if err != nil {
-		return nil, errors.New("wrong profile_id type")
+	if user.ID != uId {
+		return nil, errors.New("you can create only a bookmark for you")
 	}
 
-	err = r.Models.Offers.CreateApplicant(oId, pId)
+	err = r.Models.Bookmarks.CreateOfferBookmark(uId, oId)
 
 	if err != nil {
 		r.Logger.PrintError(fmt.Errorf("%s", err), nil)
 		return nil, err
 	}
 
-	return &model.ApplyResponse{
+	return &model.BookmarkResponse{
 		Success: true,
 	}, nil
 }
 
-// Salary is the resolver for the salary field.
-func (r *offerResolver) Salary(ctx context.Context, obj *model.Offer) ([]*model.SalaryByRoleResult, error) {
-	// I receive the Offer golang object here. So I convert the Salary (salaries type or []*model.SalaryByRole) into
-	// []byte with Marshal
-	salariesJSON, err := json.Marshal(obj.Salary)
-
+// DeleteOfferBookmark is the resolver for the deleteOfferBookmark field.
+func (r *mutationResolver) DeleteOfferBookmark(ctx context.Context, userID string, offerID string) (*model.BookmarkResponse, error) {
+	user, err := RequireAuthAndActivatedUser(ctx)
 	if err != nil {
-		return nil, errors.New("Processing salary error")
+		return nil, err
 	}
 
-	// I convert the []byte to go object again but this time to the result that
-	// graphql is expecting, in this case is []*model.SalaryByRoleResult
-	var salaries []*model.SalaryByRoleResult
-	err = json.Unmarshal(salariesJSON, &salaries)
-
-	return salaries, nil
-}
+	uId, err := strconv.ParseInt(userID, 10, 64)
+	if err != nil {
+		return nil, errors.New("wrong user_id type")
+	}
 
-// User is the resolver for the user field.
-func (r *offerResolver) User(ctx context.Context, obj *model.Offer) (*model.User, error) {
-	return dataloaders.For(ctx).GetUser(ctx, strconv.FormatInt(obj.UserId, 10))
-}
+	oId, err := strconv.ParseInt(offerID, 10, 64)
+	if err != nil {
+		return nil, errors.New("wrong offer_id type")
+	}
 
-// User is the resolver for the user field.
-func (r *profileResolver) User(ctx context.Context, obj *model.Profile) (*model.User, error) {
-	return dataloaders.For(ctx).GetUser(ctx, strconv.FormatInt(obj.UserId, 10))
-}
+	if user.ID != uId {
+		return nil, errors.New("you can delete only your bookmarks")
+	}
 
-// Salary is the resolver for the salary field.
-func (r *profileResolver) Salary(ctx context.Context, obj *model.Profile) ([]*model.SalaryByRoleResult, error) {
-	salariesJSON, err := json.Marshal(obj.Salary)
+	err = r.Models.Bookmarks.DeleteOfferBookmark(uId, oId)
 
 	if err != nil {
-		return nil, errors.New("Processing salary error")
+		r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+		return nil, err
 	}
 
-	var salaries []*model.SalaryByRoleResult
-	err = json.Unmarshal(salariesJSON, &salaries)
-
-	return salaries, nil
+	return &model.BookmarkResponse{
+		Success: true,
+	}, nil
 }
 
-// Users is the resolver for the users field.
-func (r *queryResolver) Users(ctx context.Context) ([]*model.User, error) {
-	_, err := RequireAuthAndActivatedUser(ctx)
+// CreateCollection is the resolver for the createCollection field.
+func (r *mutationResolver) CreateCollection(ctx context.Context, name string) (*model.BookmarkCollection, error) {
+	user, err := RequireAuthAndActivatedUser(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	users, err := r.Models.Users.GetAll()
+	collection := &model.BookmarkCollection{
+		UserId: user.ID,
+		Name:   name,
+	}
+
+	v := validator.New()
+
+	if model.ValidateCollection(v, collection); !v.Valid() {
+		return nil, failedValidationError(v)
+	}
+
+	err = r.Models.Bookmarks.InsertCollection(collection)
 
 	if err != nil {
-		r.Logger.PrintError(fmt.Errorf("%s", err), nil)
-		return nil, err
+		switch {
+		case errors.Is(err, model.ErrDuplicateCollection):
+			return nil, errors.New("a collection with this name already exists")
+		default:
+			r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+			return nil, err
+		}
 	}
 
-	return users, nil
+	return collection, nil
 }
 
-// Offers is the resolver for the offers field.
-func (r *queryResolver) Offers(ctx context.Context) ([]*model.Offer, error) {
-	_, err := RequireAuthAndActivatedUser(ctx)
+// AddToCollection is the resolver for the addToCollection field.
+func (r *mutationResolver) AddToCollection(ctx context.Context, collectionID string, profileID *string, offerID *string, note *string) (*model.Bookmark, error) {
+	user, err := RequireAuthAndActivatedUser(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	offers, err := r.Models.Offers.GetAll()
+	cId, err := strconv.ParseInt(collectionID, 10, 64)
+	if err != nil {
+		return nil, errors.New("wrong collection_id type")
+	}
 
+	bookmark, err := bookmarkFromInput(user.ID, profileID, offerID)
 	if err != nil {
-		r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+		return nil, err
+	}
+
+	bookmark.CollectionId = &cId
+
+	if note != nil {
+		bookmark.Note = *note
+	}
+
+	v := validator.New()
+
+	if model.ValidateBookmark(v, bookmark); !v.Valid() {
+		return nil, failedValidationError(v)
+	}
+
+	err = r.Models.Bookmarks.Upsert(bookmark)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrRecordNotFound):
+			return nil, errors.New("collection not found")
+		default:
+			r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+			return nil, err
+		}
+	}
+
+	return bookmark, nil
+}
+
+// MoveBookmark is the resolver for the moveBookmark field.
+func (r *mutationResolver) MoveBookmark(ctx context.Context, profileID *string, offerID *string, collectionID *string) (*model.Bookmark, error) {
+	user, err := RequireAuthAndActivatedUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	bookmark, err := bookmarkFromInput(user.ID, profileID, offerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if collectionID != nil {
+		cId, err := strconv.ParseInt(*collectionID, 10, 64)
+		if err != nil {
+			return nil, errors.New("wrong collection_id type")
+		}
+
+		bookmark.CollectionId = &cId
+	}
+
+	v := validator.New()
+
+	if model.ValidateBookmark(v, bookmark); !v.Valid() {
+		return nil, failedValidationError(v)
+	}
+
+	err = r.Models.Bookmarks.Move(bookmark)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrRecordNotFound):
+			return nil, errors.New("bookmark or collection not found")
+		default:
+			r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+			return nil, err
+		}
+	}
+
+	return bookmark, nil
+}
+
+// CreateSavedSearch is the resolver for the createSavedSearch field.
+func (r *mutationResolver) CreateSavedSearch(ctx context.Context, input model.SavedSearchInput) (*model.SavedSearch, error) {
+	user, err := RequireAuthAndActivatedUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	search := savedSearchFromInput(input)
+	search.UserId = user.ID
+
+	v := validator.New()
+
+	if model.ValidateSavedSearch(v, search); !v.Valid() {
+		return nil, failedValidationError(v)
+	}
+
+	err = r.Models.SavedSearches.Insert(search)
+
+	if err != nil {
+		r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+		return nil, err
+	}
+
+	return search, nil
+}
+
+// UpdateSavedSearch is the resolver for the updateSavedSearch field.
+func (r *mutationResolver) UpdateSavedSearch(ctx context.Context, id string, version int, input model.SavedSearchInput) (*model.SavedSearch, error) {
+	user, err := RequireAuthAndActivatedUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sId, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return nil, errors.New("wrong saved_search_id type")
+	}
+
+	search, err := r.Models.SavedSearches.Get(sId, user.ID)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrRecordNotFound):
+			return nil, errors.New("saved search not found")
+		default:
+			r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+			return nil, err
+		}
+	}
+
+	if search.Version != version {
+		return nil, errors.New("the saved search was modified, please try again")
+	}
+
+	updated := savedSearchFromInput(input)
+	updated.ID = search.ID
+	updated.UserId = search.UserId
+	updated.CreatedAt = search.CreatedAt
+	updated.LastNotifiedAt = search.LastNotifiedAt
+	updated.Version = search.Version
+
+	v := validator.New()
+
+	if model.ValidateSavedSearch(v, updated); !v.Valid() {
+		return nil, failedValidationError(v)
+	}
+
+	err = r.Models.SavedSearches.Update(updated)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrEditConflict):
+			return nil, errors.New("the saved search was modified, please try again")
+		default:
+			r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+			return nil, err
+		}
+	}
+
+	return updated, nil
+}
+
+// DeleteSavedSearch is the resolver for the deleteSavedSearch field.
+func (r *mutationResolver) DeleteSavedSearch(ctx context.Context, id string) (*model.SavedSearchResponse, error) {
+	user, err := RequireAuthAndActivatedUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sId, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return nil, errors.New("wrong saved_search_id type")
+	}
+
+	err = r.Models.SavedSearches.Delete(sId, user.ID)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrRecordNotFound):
+			return nil, errors.New("saved search not found")
+		default:
+			r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+			return nil, err
+		}
+	}
+
+	return &model.SavedSearchResponse{
+		Success: true,
+	}, nil
+}
+
+// UploadExchangeRates is the resolver for the uploadExchangeRates field.
+func (r *mutationResolver) UploadExchangeRates(ctx context.Context, rates []*model.ExchangeRateInput) ([]*model.ExchangeRate, error) {
+	user, err := r.requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var exchangeRates []*model.ExchangeRate
+	for _, rate := range rates {
+		exchangeRates = append(exchangeRates, &model.ExchangeRate{
+			Currency: validator.NormalizeCurrency(rate.Currency),
+			Rate:     rate.Rate,
+		})
+	}
+
+	return r.saveExchangeRates(user, exchangeRates)
+}
+
+// ImportExchangeRates is the resolver for the importExchangeRates field.
+func (r *mutationResolver) ImportExchangeRates(ctx context.Context, csv string) ([]*model.ExchangeRate, error) {
+	user, err := r.requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	exchangeRates, err := model.ParseExchangeRatesCSV(strings.NewReader(csv))
+	if err != nil {
+		return nil, fmt.Errorf("invalid csv: %s", err)
+	}
+
+	return r.saveExchangeRates(user, exchangeRates)
+}
+
+// CreateSkill is the resolver for the createSkill field.
+func (r *mutationResolver) CreateSkill(ctx context.Context, name string, aliases []string) (*model.Skill, error) {
+	_, err := r.requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	skill := &model.Skill{
+		Name:    strings.TrimSpace(name),
+		Aliases: model.NormalizeSkillAliases(aliases),
+	}
+
+	v := validator.New()
+
+	if model.ValidateSkill(v, skill); !v.Valid() {
+		return nil, failedValidationError(v)
+	}
+
+	err = r.Models.Skills.Insert(skill)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrDuplicateSkill):
+			return nil, errors.New("a skill with this name already exists")
+		case errors.Is(err, model.ErrDuplicateAlias):
+			return nil, errors.New("an alias is already used by another skill")
+		default:
+			r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+			return nil, err
+		}
+	}
+
+	return skill, nil
+}
+
+// AddSkillAliases is the resolver for the addSkillAliases field.
+func (r *mutationResolver) AddSkillAliases(ctx context.Context, skillID string, aliases []string) (*model.Skill, error) {
+	_, err := r.requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sId, err := strconv.ParseInt(skillID, 10, 64)
+	if err != nil {
+		return nil, errors.New("wrong skill_id type")
+	}
+
+	aliases = model.NormalizeSkillAliases(aliases)
+
+	v := validator.New()
+
+	if model.ValidateSkillAliases(v, aliases); !v.Valid() {
+		return nil, failedValidationError(v)
+	}
+
+	err = r.Models.Skills.AddAliases(sId, aliases)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrRecordNotFound):
+			return nil, errors.New("skill not found")
+		case errors.Is(err, model.ErrDuplicateAlias):
+			return nil, errors.New("an alias is already used by another skill")
+		default:
+			r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+			return nil, err
+		}
+	}
+
+	skill, err := r.Models.Skills.Get(sId)
+
+	if err != nil {
+		r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+		return nil, err
+	}
+
+	return skill, nil
+}
+
+// SetProfileSkills is the resolver for the setProfileSkills field.
+func (r *mutationResolver) SetProfileSkills(ctx context.Context, profileID string, skills []*model.ProfileSkillInput) ([]*model.ProfileSkill, error) {
+	user, err := RequireAuthAndActivatedUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	pId, err := strconv.ParseInt(profileID, 10, 64)
+	if err != nil {
+		return nil, errors.New("wrong profile_id type")
+	}
+
+	_, err = r.requireProfileOwner(user, pId)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(skills))
+	for _, s := range skills {
+		names = append(names, s.Skill)
+	}
+
+	ids, err := r.resolveSkills(names)
+	if err != nil {
+		return nil, err
+	}
+
+	profileSkills := make([]*model.ProfileSkill, 0, len(skills))
+	for i, s := range skills {
+		profileSkills = append(profileSkills, &model.ProfileSkill{
+			SkillId: ids[i],
+			Years:   s.Years,
+			Level:   s.Level,
+		})
+	}
+
+	v := validator.New()
+
+	if model.ValidateProfileSkills(v, profileSkills); !v.Valid() {
+		return nil, failedValidationError(v)
+	}
+
+	err = r.Models.Skills.SetProfileSkills(pId, profileSkills)
+
+	if err != nil {
+		r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+		return nil, err
+	}
+
+	result, err := r.Models.Skills.GetByProfileId(pId)
+
+	if err != nil {
+		r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// SetOfferSkills is the resolver for the setOfferSkills field.
+func (r *mutationResolver) SetOfferSkills(ctx context.Context, offerID string, skills []*model.OfferSkillInput) ([]*model.OfferSkill, error) {
+	user, err := RequireAuthAndActivatedUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	oId, err := strconv.ParseInt(offerID, 10, 64)
+	if err != nil {
+		return nil, errors.New("wrong offer_id type")
+	}
+
+	_, err = r.requireOfferOwner(user, oId)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(skills))
+	for _, s := range skills {
+		names = append(names, s.Skill)
+	}
+
+	ids, err := r.resolveSkills(names)
+	if err != nil {
+		return nil, err
+	}
+
+	offerSkills := make([]*model.OfferSkill, 0, len(skills))
+	for i, s := range skills {
+		offerSkills = append(offerSkills, &model.OfferSkill{
+			SkillId:  ids[i],
+			Required: s.Required,
+		})
+	}
+
+	v := validator.New()
+
+	if model.ValidateOfferSkills(v, offerSkills); !v.Valid() {
+		return nil, failedValidationError(v)
+	}
+
+	err = r.Models.Skills.SetOfferSkills(oId, offerSkills)
+
+	if err != nil {
+		r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+		return nil, err
+	}
+
+	result, err := r.Models.Skills.GetByOfferId(oId)
+
+	if err != nil {
+		r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// CreateExperience is the resolver for the createExperience field.
+func (r *mutationResolver) CreateExperience(ctx context.Context, profileID string, input model.ExperienceInput) (*model.Experience, error) {
+	user, err := RequireAuthAndActivatedUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	pId, err := strconv.ParseInt(profileID, 10, 64)
+	if err != nil {
+		return nil, errors.New("wrong profile_id type")
+	}
+
+	_, err = r.requireProfileOwner(user, pId)
+	if err != nil {
+		return nil, err
+	}
+
+	experience := experienceFromInput(input)
+	experience.ProfileId = pId
+
+	v := validator.New()
+
+	if model.ValidateExperience(v, experience); !v.Valid() {
+		return nil, failedValidationError(v)
+	}
+
+	err = r.Models.Experiences.Insert(experience)
+
+	if err != nil {
+		r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+		return nil, err
+	}
+
+	return experience, nil
+}
+
+// UpdateExperience is the resolver for the updateExperience field.
+func (r *mutationResolver) UpdateExperience(ctx context.Context, id string, version int, input model.ExperienceInput) (*model.Experience, error) {
+	user, err := RequireAuthAndActivatedUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	eId, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return nil, errors.New("wrong experience_id type")
+	}
+
+	existing, err := r.requireExperienceOwner(user, eId)
+	if err != nil {
+		return nil, err
+	}
+
+	if existing.Version != version {
+		return nil, errors.New("the experience was modified, please try again")
+	}
+
+	experience := experienceFromInput(input)
+	experience.ID = existing.ID
+	experience.ProfileId = existing.ProfileId
+	experience.CreatedAt = existing.CreatedAt
+	experience.Position = existing.Position
+	experience.Version = existing.Version
+
+	v := validator.New()
+
+	if model.ValidateExperience(v, experience); !v.Valid() {
+		return nil, failedValidationError(v)
+	}
+
+	err = r.Models.Experiences.Update(experience)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrEditConflict):
+			return nil, errors.New("the experience was modified, please try again")
+		default:
+			r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+			return nil, err
+		}
+	}
+
+	return experience, nil
+}
+
+// DeleteExperience is the resolver for the deleteExperience field.
+func (r *mutationResolver) DeleteExperience(ctx context.Context, id string) (*model.ProfileHistoryResponse, error) {
+	user, err := RequireAuthAndActivatedUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	eId, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return nil, errors.New("wrong experience_id type")
+	}
+
+	_, err = r.requireExperienceOwner(user, eId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.Models.Experiences.Delete(eId)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrRecordNotFound):
+			return nil, errors.New("experience not found")
+		default:
+			r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+			return nil, err
+		}
+	}
+
+	return &model.ProfileHistoryResponse{
+		Success: true,
+	}, nil
+}
+
+// ReorderExperiences is the resolver for the reorderExperiences field.
+func (r *mutationResolver) ReorderExperiences(ctx context.Context, profileID string, ids []string) ([]*model.Experience, error) {
+	user, err := RequireAuthAndActivatedUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	pId, err := strconv.ParseInt(profileID, 10, 64)
+	if err != nil {
+		return nil, errors.New("wrong profile_id type")
+	}
+
+	order, err := parseIDs(ids, "experience_id")
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = r.requireProfileOwner(user, pId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.Models.Experiences.Reorder(pId, order)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrIncompleteOrder):
+			return nil, errors.New("ids must contain every experience of the profile exactly once")
+		default:
+			r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+			return nil, err
+		}
+	}
+
+	experiences, err := r.Models.Experiences.GetAllByProfileIds([]int64{pId})
+
+	if err != nil {
+		r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+		return nil, err
+	}
+
+	return experiences, nil
+}
+
+// CreateEducation is the resolver for the createEducation field.
+func (r *mutationResolver) CreateEducation(ctx context.Context, profileID string, input model.EducationInput) (*model.Education, error) {
+	user, err := RequireAuthAndActivatedUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	pId, err := strconv.ParseInt(profileID, 10, 64)
+	if err != nil {
+		return nil, errors.New("wrong profile_id type")
+	}
+
+	_, err = r.requireProfileOwner(user, pId)
+	if err != nil {
+		return nil, err
+	}
+
+	education := educationFromInput(input)
+	education.ProfileId = pId
+
+	v := validator.New()
+
+	if model.ValidateEducation(v, education); !v.Valid() {
+		return nil, failedValidationError(v)
+	}
+
+	err = r.Models.Educations.Insert(education)
+
+	if err != nil {
+		r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+		return nil, err
+	}
+
+	return education, nil
+}
+
+// UpdateEducation is the resolver for the updateEducation field.
+func (r *mutationResolver) UpdateEducation(ctx context.Context, id string, version int, input model.EducationInput) (*model.Education, error) {
+	user, err := RequireAuthAndActivatedUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	eId, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return nil, errors.New("wrong education_id type")
+	}
+
+	existing, err := r.requireEducationOwner(user, eId)
+	if err != nil {
+		return nil, err
+	}
+
+	if existing.Version != version {
+		return nil, errors.New("the education was modified, please try again")
+	}
+
+	education := educationFromInput(input)
+	education.ID = existing.ID
+	education.ProfileId = existing.ProfileId
+	education.CreatedAt = existing.CreatedAt
+	education.Position = existing.Position
+	education.Version = existing.Version
+
+	v := validator.New()
+
+	if model.ValidateEducation(v, education); !v.Valid() {
+		return nil, failedValidationError(v)
+	}
+
+	err = r.Models.Educations.Update(education)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrEditConflict):
+			return nil, errors.New("the education was modified, please try again")
+		default:
+			r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+			return nil, err
+		}
+	}
+
+	return education, nil
+}
+
+// DeleteEducation is the resolver for the deleteEducation field.
+func (r *mutationResolver) DeleteEducation(ctx context.Context, id string) (*model.ProfileHistoryResponse, error) {
+	user, err := RequireAuthAndActivatedUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	eId, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return nil, errors.New("wrong education_id type")
+	}
+
+	_, err = r.requireEducationOwner(user, eId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.Models.Educations.Delete(eId)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrRecordNotFound):
+			return nil, errors.New("education not found")
+		default:
+			r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+			return nil, err
+		}
+	}
+
+	return &model.ProfileHistoryResponse{
+		Success: true,
+	}, nil
+}
+
+// ReorderEducations is the resolver for the reorderEducations field.
+func (r *mutationResolver) ReorderEducations(ctx context.Context, profileID string, ids []string) ([]*model.Education, error) {
+	user, err := RequireAuthAndActivatedUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	pId, err := strconv.ParseInt(profileID, 10, 64)
+	if err != nil {
+		return nil, errors.New("wrong profile_id type")
+	}
+
+	order, err := parseIDs(ids, "education_id")
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = r.requireProfileOwner(user, pId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.Models.Educations.Reorder(pId, order)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrIncompleteOrder):
+			return nil, errors.New("ids must contain every education of the profile exactly once")
+		default:
+			r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+			return nil, err
+		}
+	}
+
+	educations, err := r.Models.Educations.GetAllByProfileIds([]int64{pId})
+
+	if err != nil {
+		r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+		return nil, err
+	}
+
+	return educations, nil
+}
+
+// ApplyToOffer is the resolver for the applyToOffer field.
+func (r *mutationResolver) ApplyToOffer(ctx context.Context, offerID string, profileID string, coverLetter *string, answers []*model.ApplicationAnswerInput) (*model.ApplyResponse, error) {
+	user, err := RequireAuthAndActivatedUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	oId, err := strconv.ParseInt(offerID, 10, 64)
+	if err != nil {
+		return nil, errors.New("wrong offer_id type")
+	}
+
+	pId, err := strconv.ParseInt(profileID, 10, 64)
+	if err != nil {
+		return nil, errors.New("wrong profile_id type")
+	}
+
+	_, err = r.requireProfileOwner(user, pId)
+	if err != nil {
+		return nil, err
+	}
+
+	offer, err := r.Models.Offers.GetById(oId)
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrRecordNotFound):
+			return nil, errors.New("offer not found")
+		default:
+			r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+			return nil, err
+		}
+	}
+
+	if !offer.Active || !offer.ModerationStatus.IsListed() {
+		return nil, errors.New("the offer is not open to applications")
+	}
+
+	owner, err := r.Models.Users.GetById(offer.UserId)
+	if err != nil {
+		r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+		return nil, err
+	}
+
+	if owner.SuspendedAt != nil || owner.DeletedAt != nil {
+		return nil, errors.New("the offer is not open to applications")
+	}
+
+	applicationAnswers, err := applicationAnswersFromInput(answers)
+	if err != nil {
+		return nil, err
+	}
+
+	application := &model.Application{
+		OfferId:   oId,
+		ProfileId: pId,
+		AppliedBy: user.ID,
+		Answers:   applicationAnswers,
+	}
+
+	if coverLetter != nil {
+		application.CoverLetter = *coverLetter
+	}
+
+	questions, err := r.Models.Applications.GetQuestionsByOfferId(oId)
+
+	if err != nil {
+		r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+		return nil, err
+	}
+
+	v := validator.New()
+
+	if model.ValidateApplication(v, application, questions); !v.Valid() {
+		return nil, failedValidationError(v)
+	}
+
+	err = r.Models.Applications.Insert(application)
+
+	if err != nil {
+		r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+		return nil, err
+	}
+
+	return &model.ApplyResponse{
+		Success:     true,
+		Application: application,
+	}, nil
+}
+
+// UploadResume is the resolver for the uploadResume field.
+func (r *mutationResolver) UploadResume(ctx context.Context, file graphql.Upload) (*model.Resume, error) {
+	user, err := RequireAuthAndActivatedUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	head, err := readHead(file.File)
+	if err != nil {
+		r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+		return nil, err
+	}
+
+	resume := &model.Resume{
+		UserId:      user.ID,
+		Filename:    uploadFilename(file.Filename),
+		ContentType: model.DetectResumeContentType(head, file.Filename),
+		Size:        file.Size,
+	}
+
+	v := validator.New()
+
+	if model.ValidateResume(v, resume); !v.Valid() {
+		return nil, failedValidationError(v)
+	}
+
+	resume.StorageKey, err = newStorageKey(fmt.Sprintf("resumes/%d", user.ID), model.ResumeExtension(resume.ContentType))
+	if err != nil {
+		r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+		return nil, err
+	}
+
+	resume.Checksum, err = r.storeUpload(ctx, resume.StorageKey, file.File, resume.Size, resume.ContentType)
+	if err != nil {
+		r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+		return nil, errors.New("the resume could not be stored, please try again")
+	}
+
+	err = r.Models.Resumes.Insert(resume)
+	if err != nil {
+		r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+
+		// Don't leave an orphan file behind.
+		if err := r.Storage.Delete(context.Background(), resume.StorageKey); err != nil {
+			r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+		}
+
+		return nil, err
+	}
+
+	return resume, nil
+}
+
+// UploadPicture is the resolver for the uploadPicture field.
+func (r *mutationResolver) UploadPicture(ctx context.Context, file graphql.Upload, profileID *string, offerID *string) (*model.Picture, error) {
+	user, err := RequireAuthAndActivatedUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	v := validator.New()
+
+	v.Check(profileID != nil || offerID != nil, "picture", "a profileId or an offerId must be provided")
+	v.Check(profileID == nil || offerID == nil, "picture", "only one of profileId and offerId must be provided")
+
+	if !v.Valid() {
+		return nil, failedValidationError(v)
+	}
+
+	var profile *model.Profile
+	var offer *model.Offer
+
+	if profileID != nil {
+		pId, err := strconv.ParseInt(*profileID, 10, 64)
+		if err != nil {
+			return nil, errors.New("wrong profile_id type")
+		}
+
+		profile, err = r.requireProfileOwner(user, pId)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		oId, err := strconv.ParseInt(*offerID, 10, 64)
+		if err != nil {
+			return nil, errors.New("wrong offer_id type")
+		}
+
+		offer, err = r.requireOfferOwner(user, oId)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Read one byte past the limit to tell files that are too big.
+	data, err := io.ReadAll(io.LimitReader(file.File, model.MaxPictureSize+1))
+	if err != nil {
+		r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+		return nil, err
+	}
+
+	if model.ValidatePictureUpload(v, int64(len(data)), http.DetectContentType(data)); !v.Valid() {
+		return nil, failedValidationError(v)
+	}
+
+	img, format, err := imaging.Decode(data)
+	if err != nil {
+		switch {
+		case errors.Is(err, imaging.ErrTooLarge):
+			v.AddError("file", "must not be more than 40 megapixels")
+		default:
+			v.AddError("file", "must be a valid PNG or JPEG image")
+		}
+		return nil, failedValidationError(v)
+	}
+
+	picture, err := r.storePicture(ctx, img, format)
+	if err != nil {
+		r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+		return nil, errors.New("the picture could not be stored, please try again")
+	}
+
+	var previousUrl string
+
+	if profile != nil {
+		previousUrl = profile.PictureUrl
+		profile.PictureUrl = picture.URL
+		err = r.Models.Profiles.UpdatePicture(profile)
+	} else {
+		previousUrl = offer.PictureUrl
+		offer.PictureUrl = picture.URL
+		err = r.Models.Offers.UpdatePicture(offer)
+	}
+
+	if err != nil {
+		r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+		r.deletePicture(picture)
+		return nil, err
+	}
+
+	r.replacePicture(previousUrl)
+
+	return picture, nil
+}
+
+// CreateCompany is the resolver for the createCompany field.
+func (r *mutationResolver) CreateCompany(ctx context.Context, input model.CompanyInput) (*model.Company, error) {
+	user, err := RequireAuthAndActivatedUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	company := &model.Company{}
+	companyFromInput(company, input)
+
+	v := validator.New()
+
+	if model.ValidateCompany(v, company); !v.Valid() {
+		return nil, failedValidationError(v)
+	}
+
+	err = r.Models.Companies.Insert(company, user.ID)
+
+	if err != nil {
+		r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+		return nil, err
+	}
+
+	return company, nil
+}
+
+// UpdateCompany is the resolver for the updateCompany field.
+func (r *mutationResolver) UpdateCompany(ctx context.Context, id string, version int, input model.CompanyInput) (*model.Company, error) {
+	user, err := RequireAuthAndActivatedUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	cId, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return nil, errors.New("wrong company_id type")
+	}
+
+	_, err = r.requireCompanyMember(user, cId, model.CompanyRoleOwner, model.CompanyRoleAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	company, err := r.Models.Companies.Get(cId)
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrRecordNotFound):
+			return nil, errors.New("company not found")
+		default:
+			r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+			return nil, err
+		}
+	}
+
+	if company.Version != version {
+		return nil, errors.New("the company was modified, please try again")
+	}
+
+	companyFromInput(company, input)
+
+	v := validator.New()
+
+	if model.ValidateCompany(v, company); !v.Valid() {
+		return nil, failedValidationError(v)
+	}
+
+	err = r.Models.Companies.Update(company)
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrEditConflict):
+			return nil, errors.New("the company was modified, please try again")
+		default:
+			r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+			return nil, err
+		}
+	}
+
+	return company, nil
+}
+
+// InviteCompanyMember is the resolver for the inviteCompanyMember field.
+func (r *mutationResolver) InviteCompanyMember(ctx context.Context, companyID string, email string, role model.CompanyRole) (*model.CompanyInvitation, error) {
+	user, err := RequireAuthAndActivatedUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	cId, err := strconv.ParseInt(companyID, 10, 64)
+	if err != nil {
+		return nil, errors.New("wrong company_id type")
+	}
+
+	member, err := r.requireCompanyMember(user, cId, model.CompanyRoleOwner, model.CompanyRoleAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	invitation := &model.CompanyInvitation{
+		CompanyId: cId,
+		Email:     strings.TrimSpace(email),
+		Role:      role,
+		InvitedBy: user.ID,
+	}
+
+	v := validator.New()
+
+	if model.ValidateCompanyInvitation(v, invitation); !v.Valid() {
+		return nil, failedValidationError(v)
+	}
+
+	// Only the owner hands out the admin role.
+	if invitation.Role == model.CompanyRoleAdmin && member.Role != model.CompanyRoleOwner {
+		return nil, errors.New("only the owner can invite admins")
+	}
+
+	company, err := r.Models.Companies.Get(cId)
+	if err != nil {
+		r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+		return nil, err
+	}
+
+	err = r.Models.Companies.NewInvitation(invitation)
+	if err != nil {
+		r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+		return nil, err
+	}
+
+	r.Background(func() {
+		data := map[string]any{
+			"CompanyName": company.Name,
+			"InviterName": user.Name + " " + user.Lastname,
+			"Role":        strings.ToLower(string(invitation.Role)),
+			"Token":       invitation.Plaintext,
+			"Expiry":      invitation.Expiry,
+		}
+
+		err := r.Mailer.Send(invitation.Email, "company_invitation.tmpl", data)
+		if err != nil {
+			r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+		}
+	})
+
+	return invitation, nil
+}
+
+// AcceptCompanyInvitation is the resolver for the acceptCompanyInvitation field.
+func (r *mutationResolver) AcceptCompanyInvitation(ctx context.Context, token string) (*model.Company, error) {
+	user, err := RequireAuthAndActivatedUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	v := validator.New()
+
+	if model.ValidateTokenPlaintext(v, token); !v.Valid() {
+		return nil, failedValidationError(v)
+	}
+
+	member, err := r.Models.Companies.AcceptInvitation(token, user)
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrRecordNotFound):
+			v.AddError("token", "invalid or expired invitation token")
+			return nil, failedValidationError(v)
+		case errors.Is(err, model.ErrAlreadyMember):
+			return nil, errors.New("you are already a member of this company")
+		default:
+			r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+			return nil, err
+		}
+	}
+
+	company, err := r.Models.Companies.Get(member.CompanyId)
+	if err != nil {
+		r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+		return nil, err
+	}
+
+	return company, nil
+}
+
+// UpdateCompanyMemberRole is the resolver for the updateCompanyMemberRole field.
+func (r *mutationResolver) UpdateCompanyMemberRole(ctx context.Context, companyID string, userID string, role model.CompanyRole) (*model.CompanyMember, error) {
+	user, err := RequireAuthAndActivatedUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	cId, err := strconv.ParseInt(companyID, 10, 64)
+	if err != nil {
+		return nil, errors.New("wrong company_id type")
+	}
+
+	uId, err := strconv.ParseInt(userID, 10, 64)
+	if err != nil {
+		return nil, errors.New("wrong user_id type")
+	}
+
+	// Roles are handed out by the owner, the ownership itself can't be transferred
+	// this way.
+	_, err = r.requireCompanyMember(user, cId, model.CompanyRoleOwner)
+	if err != nil {
+		return nil, err
+	}
+
+	v := validator.New()
+
+	v.Check(
+		validator.PermittedValue(role, model.CompanyRoleAdmin, model.CompanyRoleRecruiter),
+		"role",
+		fmt.Sprintf("%s is not a permitted role, members can be ADMIN or RECRUITER", role),
+	)
+
+	if !v.Valid() {
+		return nil, failedValidationError(v)
+	}
+
+	member := &model.CompanyMember{CompanyId: cId, UserId: uId, Role: role}
+
+	err = r.Models.Companies.UpdateMemberRole(member)
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrRecordNotFound):
+			return nil, errors.New("member not found")
+		default:
+			r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+			return nil, err
+		}
+	}
+
+	return member, nil
+}
+
+// RemoveCompanyMember is the resolver for the removeCompanyMember field.
+func (r *mutationResolver) RemoveCompanyMember(ctx context.Context, companyID string, userID string) (*model.CompanyResponse, error) {
+	user, err := RequireAuthAndActivatedUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	cId, err := strconv.ParseInt(companyID, 10, 64)
+	if err != nil {
+		return nil, errors.New("wrong company_id type")
+	}
+
+	uId, err := strconv.ParseInt(userID, 10, 64)
+	if err != nil {
+		return nil, errors.New("wrong user_id type")
+	}
+
+	// Members can leave the company, removing anyone else takes an owner or an admin,
+	// and admins can only remove recruiters.
+	if uId != user.ID {
+		member, err := r.requireCompanyMember(user, cId, model.CompanyRoleOwner, model.CompanyRoleAdmin)
+		if err != nil {
+			return nil, err
+		}
+
+		if member.Role == model.CompanyRoleAdmin {
+			target, err := r.Models.Companies.GetMember(cId, uId)
+			if err != nil {
+				switch {
+				case errors.Is(err, model.ErrRecordNotFound):
+					return nil, errors.New("member not found, the owner can't be removed")
+				default:
+					r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+					return nil, err
+				}
+			}
+
+			if target.Role != model.CompanyRoleRecruiter {
+				return nil, errors.New("only the owner can remove admins")
+			}
+		}
+	}
+
+	err = r.Models.Companies.DeleteMember(cId, uId)
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrRecordNotFound):
+			return nil, errors.New("member not found, the owner can't be removed")
+		default:
+			r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+			return nil, err
+		}
+	}
+
+	return &model.CompanyResponse{Success: true}, nil
+}
+
+// UpdateProfilePrivacy is the resolver for the updateProfilePrivacy field.
+func (r *mutationResolver) UpdateProfilePrivacy(ctx context.Context, profileID string, version int, input model.ProfilePrivacyInput) (*model.Profile, error) {
+	user, err := RequireAuthAndActivatedUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	pId, err := strconv.ParseInt(profileID, 10, 64)
+	if err != nil {
+		return nil, errors.New("wrong profile_id type")
+	}
+
+	hiddenCompanyIds, err := parseIDs(input.HiddenCompanyIds, "company_id")
+	if err != nil {
+		return nil, err
+	}
+
+	profile, err := r.requireProfileOwner(user, pId)
+	if err != nil {
+		return nil, err
+	}
+
+	if profile.Version != version {
+		return nil, errors.New("the profile was modified, please try again")
+	}
+
+	v := validator.New()
+
+	if model.ValidateProfilePrivacy(v, input.Visibility, hiddenCompanyIds); !v.Valid() {
+		return nil, failedValidationError(v)
+	}
+
+	profile.Visibility = input.Visibility
+
+	err = r.Models.Profiles.UpdatePrivacy(profile, hiddenCompanyIds)
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrEditConflict):
+			return nil, errors.New("the profile was modified, please try again")
+		case errors.Is(err, model.ErrRecordNotFound):
+			return nil, errors.New("company not found")
+		default:
+			r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+			return nil, err
+		}
+	}
+
+	return profile, nil
+}
+
+// RenewOffer is the resolver for the renewOffer field.
+func (r *mutationResolver) RenewOffer(ctx context.Context, id string, version int, expiresAt time.Time) (*model.Offer, error) {
+	user, err := RequireAuthAndActivatedUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	offerId, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return nil, errors.New("wrong offer_id type")
+	}
+
+	offer, err := r.requireOfferOwner(user, offerId)
+	if err != nil {
+		return nil, err
+	}
+
+	if offer.Version != version {
+		return nil, errors.New("the offer was modified, please try again")
+	}
+
+	if offer.Status == model.OfferStatusArchived {
+		return nil, errors.New("an archived offer can't be renewed")
+	}
+
+	v := validator.New()
+
+	if model.ValidateOfferSchedule(v, offer.PublishAt, &expiresAt); !v.Valid() {
+		return nil, failedValidationError(v)
+	}
+
+	wasActive := offer.Active
+
+	err = r.Models.Offers.Renew(offer, expiresAt, user.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrEditConflict):
+			return nil, errors.New("the offer was modified, please try again")
+		default:
+			r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+			return nil, err
+		}
+	}
+
+	if !wasActive && offer.Active {
+		r.recordSavedSearchMatches(offer.ID)
+	}
+
+	return offer, nil
+}
+
+// UpdateOffer is the resolver for the updateOffer field.
+func (r *mutationResolver) UpdateOffer(ctx context.Context, id string, version int, input model.UpdateOfferInput) (*model.Offer, error) {
+	user, err := RequireAuthAndActivatedUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	offerId, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return nil, errors.New("wrong offer_id type")
+	}
+
+	offer, err := r.requireOfferOwner(user, offerId)
+	if err != nil {
+		return nil, err
+	}
+
+	if offer.Version != version {
+		return nil, errors.New("the offer was modified, please try again")
+	}
+
+	if offer.Status == model.OfferStatusArchived {
+		return nil, errors.New("an archived offer can't be modified")
+	}
+
+	if input.Title != nil {
+		offer.Title = *input.Title
+	}
+
+	if input.Description != nil {
+		offer.Description = *input.Description
+	}
+
+	if input.Salary != nil {
+		offer.Salary = input.Salary
+		offer.Salary.NormalizeCurrencies()
+	}
+
+	v := validator.New()
+
+	if model.ValidateOffer(v, offer); !v.Valid() {
+		return nil, failedValidationError(v)
+	}
+
+	err = r.Models.Offers.Update(offer, user.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrEditConflict):
+			return nil, errors.New("the offer was modified, please try again")
+		default:
+			r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+			return nil, err
+		}
+	}
+
+	return offer, nil
+}
+
+// SetOfferStatus is the resolver for the setOfferStatus field.
+func (r *mutationResolver) SetOfferStatus(ctx context.Context, id string, version int, status model.OfferStatus) (*model.Offer, error) {
+	user, err := RequireAuthAndActivatedUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	offerId, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return nil, errors.New("wrong offer_id type")
+	}
+
+	offer, err := r.requireOfferOwner(user, offerId)
+	if err != nil {
+		return nil, err
+	}
+
+	if offer.Version != version {
+		return nil, errors.New("the offer was modified, please try again")
+	}
+
+	v := validator.New()
+
+	if model.ValidateOfferStatusChange(v, offer, status); !v.Valid() {
+		return nil, failedValidationError(v)
+	}
+
+	wasActive := offer.Active
+
+	err = r.Models.Offers.SetStatus(offer, status, user.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrEditConflict):
+			return nil, errors.New("the offer was modified, please try again")
+		default:
+			r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+			return nil, err
+		}
+	}
+
+	if !wasActive && offer.Active {
+		r.recordSavedSearchMatches(offer.ID)
+	}
+
+	return offer, nil
+}
+
+// ReportOffer is the resolver for the reportOffer field.
+func (r *mutationResolver) ReportOffer(ctx context.Context, offerID string, reason model.ReportReason, details *string) (*model.ReportResponse, error) {
+	user, err := RequireAuthAndActivatedUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	oId, err := strconv.ParseInt(offerID, 10, 64)
+	if err != nil {
+		return nil, errors.New("wrong offer_id type")
+	}
+
+	offer, err := r.Models.Offers.GetById(oId)
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrRecordNotFound):
+			return nil, errors.New("offer not found")
+		default:
+			r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+			return nil, err
+		}
+	}
+
+	// Only the offers the user can see can be reported.
+	if !offer.Active || !offer.ModerationStatus.IsListed() {
+		return nil, errors.New("offer not found")
+	}
+
+	if offer.UserId == user.ID {
+		return nil, errors.New("you can't report your own offer")
+	}
+
+	return r.reportContent(&model.Report{UserId: user.ID, OfferId: &offer.ID, Reason: reason}, details)
+}
+
+// ReportProfile is the resolver for the reportProfile field.
+func (r *mutationResolver) ReportProfile(ctx context.Context, profileID string, reason model.ReportReason, details *string) (*model.ReportResponse, error) {
+	user, err := RequireAuthAndActivatedUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	pId, err := strconv.ParseInt(profileID, 10, 64)
+	if err != nil {
+		return nil, errors.New("wrong profile_id type")
+	}
+
+	profile, err := r.Models.Profiles.GetProfileById(pId)
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrRecordNotFound):
+			return nil, errors.New("profile not found")
+		default:
+			r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+			return nil, err
+		}
+	}
+
+	if !profile.ModerationStatus.IsListed() {
+		return nil, errors.New("profile not found")
+	}
+
+	if profile.UserId == user.ID {
+		return nil, errors.New("you can't report your own profile")
+	}
+
+	return r.reportContent(&model.Report{UserId: user.ID, ProfileId: &profile.ID, Reason: reason}, details)
+}
+
+// Approve is the resolver for the approve field.
+func (r *mutationResolver) Approve(ctx context.Context, offerID *string, profileID *string, note *string) (*model.ModerationDecision, error) {
+	return r.moderate(ctx, model.ModerationActionApprove, offerID, profileID, note)
+}
+
+// Reject is the resolver for the reject field.
+func (r *mutationResolver) Reject(ctx context.Context, offerID *string, profileID *string, note *string) (*model.ModerationDecision, error) {
+	return r.moderate(ctx, model.ModerationActionReject, offerID, profileID, note)
+}
+
+// Ban is the resolver for the ban field.
+func (r *mutationResolver) Ban(ctx context.Context, offerID *string, profileID *string, note *string) (*model.ModerationDecision, error) {
+	return r.moderate(ctx, model.ModerationActionBan, offerID, profileID, note)
+}
+
+// SuspendUser is the resolver for the suspendUser field.
+func (r *mutationResolver) SuspendUser(ctx context.Context, id string) (*model.User, error) {
+	return r.setUserSuspended(ctx, id, true)
+}
+
+// UnsuspendUser is the resolver for the unsuspendUser field.
+func (r *mutationResolver) UnsuspendUser(ctx context.Context, id string) (*model.User, error) {
+	return r.setUserSuspended(ctx, id, false)
+}
+
+// DeleteMyAccount is the resolver for the deleteMyAccount field.
+func (r *mutationResolver) DeleteMyAccount(ctx context.Context, password string) (*model.DeleteAccountResponse, error) {
+	// Users that never activated their account can delete it too.
+	user := ctx.Value("user").(*model.User)
+	if user.IsAnonymous() {
+		return nil, errors.New("unauthorized")
+	}
+
+	v := validator.New()
+
+	if model.ValidatePasswordPlaintext(v, password); !v.Valid() {
+		return nil, failedValidationError(v)
+	}
+
+	// The user of the context is loaded without their password hash.
+	account, err := r.Models.Users.GetByEmail(user.Email)
+	if err != nil {
+		r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+		return nil, err
+	}
+
+	match, err := account.Password.Matches(password)
+	if err != nil {
+		r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+		return nil, err
+	}
+
+	if !match {
+		return nil, errors.New("invalid credentials")
+	}
+
+	files, err := r.Models.Users.Anonymize(account)
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrRecordNotFound):
+			return nil, errors.New("user not found")
+		default:
+			r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+			return nil, err
+		}
+	}
+
+	r.eraseFiles(files)
+
+	return &model.DeleteAccountResponse{
+		Success: true,
+		PurgeAt: account.DeletedAt.Add(r.AccountDeletionGracePeriod),
+	}, nil
+}
+
+// Salary is the resolver for the salary field.
+func (r *offerResolver) Salary(ctx context.Context, obj *model.Offer) ([]*model.SalaryByRoleResult, error) {
+	// I receive the Offer golang object here. So I convert the Salary (salaries type or []*model.SalaryByRole) into
+	// []byte with Marshal
+	salariesJSON, err := json.Marshal(obj.Salary)
+
+	if err != nil {
+		return nil, errors.New("Processing salary error")
+	}
+
+	// I convert the []byte to go object again but this time to the result that
+	// graphql is expecting, in this case is []*model.SalaryByRoleResult
+	var salaries []*model.SalaryByRoleResult
+	err = json.Unmarshal(salariesJSON, &salaries)
+
+	return salaries, nil
+}
+
+// Version is the resolver for the version field.
+func (r *offerResolver) Version(ctx context.Context, obj *model.Offer) (*int, error) {
+	manager, err := r.viewerManagesOffer(ctx, obj)
+	if err != nil || !manager {
+		return nil, err
+	}
+
+	return &obj.Version, nil
+}
+
+// User is the resolver for the user field.
+func (r *offerResolver) User(ctx context.Context, obj *model.Offer) (*model.User, error) {
+	user, err := dataloaders.For(ctx).GetUser(ctx, strconv.FormatInt(obj.UserId, 10))
+	if err != nil || user == nil {
+		return user, err
+	}
+
+	// The owner's internal fields are only shown to the ones managing the offer.
+	manager, err := r.viewerManagesOffer(ctx, obj)
+	if err != nil {
+		return nil, err
+	}
+
+	if !manager {
+		return user.Public(), nil
+	}
+
+	return user, nil
+}
+
+// Company is the resolver for the company field.
+func (r *offerResolver) Company(ctx context.Context, obj *model.Offer) (*model.Company, error) {
+	if obj.CompanyId == nil {
+		return nil, nil
+	}
+
+	company, err := r.Models.Companies.Get(*obj.CompanyId)
+
+	if err != nil {
+		r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+		return nil, err
+	}
+
+	return company, nil
+}
+
+// Questions is the resolver for the questions field.
+func (r *offerResolver) Questions(ctx context.Context, obj *model.Offer) ([]*model.OfferQuestion, error) {
+	if obj.Questions != nil {
+		return obj.Questions, nil
+	}
+
+	questions, err := r.Models.Applications.GetQuestionsByOfferId(obj.ID)
+
+	if err != nil {
+		r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+		return nil, err
+	}
+
+	return questions, nil
+}
+
+// ApplicantsCount is the resolver for the applicantsCount field.
+func (r *offerResolver) ApplicantsCount(ctx context.Context, obj *model.Offer) (*int, error) {
+	manager, err := r.viewerManagesOffer(ctx, obj)
+	if err != nil || !manager {
+		return nil, err
+	}
+
+	// Offers listed through myOffers come with the count already aggregated.
+	if obj.ApplicantsCount != nil {
+		return obj.ApplicantsCount, nil
+	}
+
+	count, err := r.Models.Offers.CountApplicants(obj.ID)
+
+	if err != nil {
+		r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+		return nil, err
+	}
+
+	return &count, nil
+}
+
+// Skills is the resolver for the skills field.
+func (r *offerResolver) Skills(ctx context.Context, obj *model.Offer) ([]*model.OfferSkill, error) {
+	skills, err := r.Models.Skills.GetByOfferId(obj.ID)
+
+	if err != nil {
+		r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+		return nil, err
+	}
+
+	return skills, nil
+}
+
+// Duplicates is the resolver for the duplicates field.
+func (r *offerResolver) Duplicates(ctx context.Context, obj *model.Offer) ([]*model.OfferDuplicate, error) {
+	manager, err := r.viewerManagesOffer(ctx, obj)
+	if err != nil {
+		return nil, err
+	}
+
+	if !manager {
+		return []*model.OfferDuplicate{}, nil
+	}
+
+	// Offers returned by createOffer come with the duplicates already found.
+	if obj.Duplicates != nil {
+		return obj.Duplicates, nil
+	}
+
+	duplicates, err := r.Models.Offers.FindDuplicates(obj)
+
+	if err != nil {
+		r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+		return nil, err
+	}
+
+	return duplicates, nil
+}
+
+// User is the resolver for the user field.
+func (r *offerRevisionResolver) User(ctx context.Context, obj *model.OfferRevision) (*model.User, error) {
+	if obj.UserId == nil {
+		return nil, nil
+	}
+
+	return dataloaders.For(ctx).GetUser(ctx, strconv.FormatInt(*obj.UserId, 10))
+}
+
+// Salary is the resolver for the salary field.
+func (r *offerRevisionResolver) Salary(ctx context.Context, obj *model.OfferRevision) ([]*model.SalaryByRoleResult, error) {
+	salariesJSON, err := json.Marshal(obj.Salary)
+	if err != nil {
+		return nil, errors.New("Processing salary error")
+	}
+
+	var salaries []*model.SalaryByRoleResult
+	err = json.Unmarshal(salariesJSON, &salaries)
+	if err != nil {
+		return nil, errors.New("Processing salary error")
+	}
+
+	return salaries, nil
+}
+
+// UserID is the resolver for the userId field.
+func (r *profileResolver) UserID(ctx context.Context, obj *model.Profile) (*string, error) {
+	visible, err := r.profileIdentityVisible(ctx, obj)
+	if err != nil || !visible {
+		return nil, err
+	}
+
+	userId := strconv.FormatInt(obj.UserId, 10)
+
+	return &userId, nil
+}
+
+// User is the resolver for the user field.
+func (r *profileResolver) User(ctx context.Context, obj *model.Profile) (*model.User, error) {
+	viewer, err := r.profileViewer(ctx, obj)
+	if err != nil || !model.ProfileIdentityVisible(viewer) {
+		return nil, err
+	}
+
+	user, err := dataloaders.For(ctx).GetUser(ctx, strconv.FormatInt(obj.UserId, 10))
+	if err != nil || user == nil {
+		return user, err
+	}
+
+	if !model.ProfileDetailsVisible(obj.Visibility, viewer) {
+		return user.Public(), nil
+	}
+
+	return user, nil
+}
+
+// Title is the resolver for the title field.
+func (r *profileResolver) Title(ctx context.Context, obj *model.Profile) (string, error) {
+	visible, err := r.profileIdentityVisible(ctx, obj)
+	if err != nil || !visible {
+		return "", err
+	}
+
+	return obj.Title, nil
+}
+
+// About is the resolver for the about field.
+func (r *profileResolver) About(ctx context.Context, obj *model.Profile) (string, error) {
+	visible, err := r.profileIdentityVisible(ctx, obj)
+	if err != nil || !visible {
+		return "", err
+	}
+
+	return obj.About, nil
+}
+
+// PictureURL is the resolver for the pictureUrl field.
+func (r *profileResolver) PictureURL(ctx context.Context, obj *model.Profile) (*string, error) {
+	visible, err := r.profileIdentityVisible(ctx, obj)
+	if err != nil || !visible {
+		return nil, err
+	}
+
+	return &obj.PictureUrl, nil
+}
+
+// WebsiteURL is the resolver for the websiteUrl field.
+func (r *profileResolver) WebsiteURL(ctx context.Context, obj *model.Profile) (*string, error) {
+	visible, err := r.profileDetailsVisible(ctx, obj)
+	if err != nil || !visible {
+		return nil, err
+	}
+
+	return &obj.WebsiteUrl, nil
+}
+
+// Salary is the resolver for the salary field.
+func (r *profileResolver) Salary(ctx context.Context, obj *model.Profile) ([]*model.SalaryByRoleResult, error) {
+	visible, err := r.profileDetailsVisible(ctx, obj)
+	if err != nil {
+		return nil, err
+	}
+
+	if !visible {
+		return []*model.SalaryByRoleResult{}, nil
+	}
+
+	salariesJSON, err := json.Marshal(obj.Salary)
+
+	if err != nil {
+		return nil, errors.New("Processing salary error")
+	}
+
+	var salaries []*model.SalaryByRoleResult
+	err = json.Unmarshal(salariesJSON, &salaries)
+
+	return salaries, nil
+}
+
+// Skills is the resolver for the skills field.
+func (r *profileResolver) Skills(ctx context.Context, obj *model.Profile) ([]*model.ProfileSkill, error) {
+	skills, err := r.Models.Skills.GetByProfileId(obj.ID)
+
+	if err != nil {
+		r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+		return nil, err
+	}
+
+	return skills, nil
+}
+
+// Resume is the resolver for the resume field.
+func (r *profileResolver) Resume(ctx context.Context, obj *model.Profile) (*model.Resume, error) {
+	viewer := ctx.Value("user").(*model.User)
+
+	// The resume is hidden, rather than failing the whole query, from anyone else.
+	if viewer == nil || viewer.IsAnonymous() {
+		return nil, nil
+	}
+
+	resume, err := r.Models.Resumes.GetLatestByUserId(obj.UserId)
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrRecordNotFound):
+			return nil, nil
+		default:
+			r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+			return nil, err
+		}
+	}
+
+	allowed, err := r.Models.Resumes.CanDownload(resume, viewer.ID)
+	if err != nil {
+		r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+		return nil, err
+	}
+
+	if !allowed {
+		return nil, nil
+	}
+
+	return resume, nil
+}
+
+// Experiences is the resolver for the experiences field.
+func (r *profileResolver) Experiences(ctx context.Context, obj *model.Profile) ([]*model.Experience, error) {
+	visible, err := r.profileIdentityVisible(ctx, obj)
+	if err != nil {
+		return nil, err
+	}
+
+	if !visible {
+		return []*model.Experience{}, nil
+	}
+
+	return dataloaders.For(ctx).GetExperiences(ctx, obj.ID)
+}
+
+// Educations is the resolver for the educations field.
+func (r *profileResolver) Educations(ctx context.Context, obj *model.Profile) ([]*model.Education, error) {
+	visible, err := r.profileIdentityVisible(ctx, obj)
+	if err != nil {
+		return nil, err
+	}
+
+	if !visible {
+		return []*model.Education{}, nil
+	}
+
+	return dataloaders.For(ctx).GetEducations(ctx, obj.ID)
+}
+
+// HiddenCompanies is the resolver for the hiddenCompanies field.
+func (r *profileResolver) HiddenCompanies(ctx context.Context, obj *model.Profile) ([]*model.Company, error) {
+	viewer := ctx.Value("user").(*model.User)
+
+	if viewer.IsAnonymous() || viewer.ID != obj.UserId {
+		return []*model.Company{}, nil
+	}
+
+	companies, err := r.Models.Profiles.GetHiddenCompanies(obj.ID)
+
+	if err != nil {
+		r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+		return nil, err
+	}
+
+	return companies, nil
+}
+
+// Users is the resolver for the users field.
+func (r *queryResolver) Users(ctx context.Context) ([]*model.User, error) {
+	viewer, err := RequireAuthAndActivatedUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	users, err := r.Models.Users.GetAll()
+
+	if err != nil {
+		r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+		return nil, err
+	}
+
+	admin, err := r.isAdmin(viewer)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only the admins see the internal fields of the other users.
+	if !admin {
+		for i, user := range users {
+			if user.ID != viewer.ID {
+				users[i] = user.Public()
+			}
+		}
+	}
+
+	return users, nil
+}
+
+// Offers is the resolver for the offers field.
+func (r *queryResolver) Offers(ctx context.Context, minSalary *float64, maxSalary *float64, currency *string, sort *string, skills []string, near *model.NearInput) ([]*model.Offer, error) {
+	user, err := RequireAuthAndActivatedUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.searchOffers(minSalary, maxSalary, currency, sort, skills, near, false, user.ID)
+}
+
+// Profiles is the resolver for the profiles field.
+func (r *queryResolver) Profiles(ctx context.Context, skills []string, near *model.NearInput) ([]*model.Profile, error) {
+	user, err := RequireAuthAndActivatedUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	filters := model.ProfileFilters{ViewerId: user.ID}
+
+	filters.SkillIds, err = r.resolveSkills(skills)
+	if err != nil {
+		return nil, err
+	}
+
+	v := validator.New()
+
+	filters.Near = geoFilterFromInput(v, near)
+
+	if model.ValidateProfileFilters(v, filters); !v.Valid() {
+		return nil, failedValidationError(v)
+	}
+
+	profiles, err := r.Models.Profiles.GetAll(filters)
+
+	if err != nil {
+		r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+		return nil, err
+	}
+
+	return profiles, nil
+}
+
+// Skills is the resolver for the skills field.
+func (r *queryResolver) Skills(ctx context.Context, search *string) ([]*model.Skill, error) {
+	_, err := RequireAuthAndActivatedUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var s string
+	if search != nil {
+		s = *search
+	}
+
+	skills, err := r.Models.Skills.GetAll(s)
+
+	if err != nil {
+		r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+		return nil, err
+	}
+
+	return skills, nil
+}
+
+// Countries is the resolver for the countries field.
+func (r *queryResolver) Countries(ctx context.Context) ([]*model.Country, error) {
+	var countries []*model.Country
+	for _, c := range geo.Countries() {
+		countries = append(countries, &model.Country{
+			Code: c.Code,
+			Name: c.Name,
+		})
+	}
+
+	return countries, nil
+}
+
+// Subdivisions is the resolver for the subdivisions field.
+func (r *queryResolver) Subdivisions(ctx context.Context, country string) ([]*model.Subdivision, error) {
+	var subdivisions []*model.Subdivision
+	for _, sub := range geo.Subdivisions(geo.NormalizeCountry(country)) {
+		subdivisions = append(subdivisions, &model.Subdivision{
+			Code:        sub.Code,
+			CountryCode: sub.CountryCode,
+			Name:        sub.Name,
+		})
+	}
+
+	return subdivisions, nil
+}
+
+// Profile is the resolver for the profile field.
+func (r *queryResolver) Profile(ctx context.Context, id string) (*model.Profile, error) {
+	_, err := RequireAuthAndActivatedUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	pId, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return nil, errors.New("wrong profile id type")
+	}
+
+	profile, err := r.Models.Profiles.GetProfileById(pId)
+
+	if err != nil {
+		r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+		return nil, err
+	}
+
+	return r.listedProfile(ctx, profile)
+}
+
+// ProfileByUserID is the resolver for the profileByUserId field.
+func (r *queryResolver) ProfileByUserID(ctx context.Context, userID string) (*model.Profile, error) {
+	_, err := RequireAuthAndActivatedUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	pId, err := strconv.ParseInt(userID, 10, 64)
+	if err != nil {
+		return nil, errors.New("wrong user_id type")
+	}
+
+	profile, err := r.Models.Profiles.GetProfileByUserId(pId)
+
+	if err != nil {
+		r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+		return nil, err
+	}
+
+	return r.listedProfile(ctx, profile)
+}
+
+// Bookmarks is the resolver for the bookmarks field.
+func (r *queryResolver) Bookmarks(ctx context.Context, userID string) ([]*model.Profile, error) {
+	user, err := RequireAuthAndActivatedUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	uId, err := strconv.ParseInt(userID, 10, 64)
+	if err != nil {
+		return nil, errors.New("wrong user_id type")
+	}
+
+	if user.ID != uId {
+		return nil, errors.New("you can query only your bookmarks")
+	}
+
+	profiles, err := r.Models.Users.GetAllBookmarksByUserId(uId)
+
+	if err != nil {
+		r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+		return nil, err
+	}
+
+	return profiles, nil
+}
+
+// OfferBookmarks is the resolver for the offerBookmarks field.
+func (r *queryResolver) OfferBookmarks(ctx context.Context, userID string) ([]*model.Offer, error) {
+	user, err := RequireAuthAndActivatedUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	uId, err := strconv.ParseInt(userID, 10, 64)
+	if err != nil {
+		return nil, errors.New("wrong user_id type")
+	}
+
+	if user.ID != uId {
+		return nil, errors.New("you can query only your bookmarks")
+	}
+
+	offers, err := r.Models.Bookmarks.GetAllOfferBookmarksByUserId(uId)
+
+	if err != nil {
+		r.Logger.PrintError(fmt.Errorf("%s", err), nil)
 		return nil, err
 	}
 
 	return offers, nil
 }
 
-// Profile is the resolver for the profile field.
-func (r *queryResolver) Profile(ctx context.Context, id string) (*model.Profile, error) {
-	_, err := RequireAuthAndActivatedUser(ctx)
+// Collections is the resolver for the collections field.
+func (r *queryResolver) Collections(ctx context.Context) ([]*model.BookmarkCollection, error) {
+	user, err := RequireAuthAndActivatedUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	collections, err := r.Models.Bookmarks.GetCollectionsByUserId(user.ID)
+
+	if err != nil {
+		r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+		return nil, err
+	}
+
+	return collections, nil
+}
+
+// SavedSearches is the resolver for the savedSearches field.
+func (r *queryResolver) SavedSearches(ctx context.Context) ([]*model.SavedSearch, error) {
+	user, err := RequireAuthAndActivatedUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	searches, err := r.Models.SavedSearches.GetAllByUserId(user.ID)
+
+	if err != nil {
+		r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+		return nil, err
+	}
+
+	return searches, nil
+}
+
+// RecommendedProfiles is the resolver for the recommendedProfiles field.
+func (r *queryResolver) RecommendedProfiles(ctx context.Context, offerID string, limit *int) ([]*model.ProfileRecommendation, error) {
+	user, err := RequireAuthAndActivatedUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	oId, err := strconv.ParseInt(offerID, 10, 64)
+	if err != nil {
+		return nil, errors.New("wrong offer_id type")
+	}
+
+	offer, err := r.requireOfferOwner(user, oId)
+	if err != nil {
+		return nil, err
+	}
+
+	profiles, err := r.Models.Profiles.GetAllSearching(offer.ID, user.ID)
+
+	if err != nil {
+		r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+		return nil, err
+	}
+
+	target := matchingOffer(offer)
+
+	recommendations := make([]*model.ProfileRecommendation, 0, len(profiles))
+	for _, profile := range profiles {
+		recommendations = append(recommendations, &model.ProfileRecommendation{
+			Profile: profile,
+			Score:   matching.Score(target, matchingCandidate(profile)).Total,
+		})
+	}
+
+	sort.SliceStable(recommendations, func(i, j int) bool {
+		if recommendations[i].Score != recommendations[j].Score {
+			return recommendations[i].Score > recommendations[j].Score
+		}
+
+		return recommendations[i].Profile.ID < recommendations[j].Profile.ID
+	})
+
+	return recommendations[:recommendationsLimit(limit, len(recommendations))], nil
+}
+
+// RecommendedOffers is the resolver for the recommendedOffers field.
+func (r *queryResolver) RecommendedOffers(ctx context.Context, profileID string, limit *int) ([]*model.OfferRecommendation, error) {
+	user, err := RequireAuthAndActivatedUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	pId, err := strconv.ParseInt(profileID, 10, 64)
+	if err != nil {
+		return nil, errors.New("wrong profile_id type")
+	}
+
+	profile, err := r.Models.Profiles.GetProfileById(pId)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrRecordNotFound):
+			return nil, errors.New("profile not found")
+		default:
+			r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+			return nil, err
+		}
+	}
+
+	if profile.UserId != user.ID {
+		return nil, errors.New("you can query only recommendations for your profile")
+	}
+
+	offers, err := r.Models.Offers.GetRecommendationCandidates(profile.ID)
+
+	if err != nil {
+		r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+		return nil, err
+	}
+
+	candidate := matchingCandidate(profile)
+
+	recommendations := make([]*model.OfferRecommendation, 0, len(offers))
+	for _, offer := range offers {
+		recommendations = append(recommendations, &model.OfferRecommendation{
+			Offer: offer,
+			Score: matching.Score(matchingOffer(offer), candidate).Total,
+		})
+	}
+
+	sort.SliceStable(recommendations, func(i, j int) bool {
+		if recommendations[i].Score != recommendations[j].Score {
+			return recommendations[i].Score > recommendations[j].Score
+		}
+
+		return recommendations[i].Offer.ID < recommendations[j].Offer.ID
+	})
+
+	return recommendations[:recommendationsLimit(limit, len(recommendations))], nil
+}
+
+// ExchangeRates is the resolver for the exchangeRates field.
+func (r *queryResolver) ExchangeRates(ctx context.Context) ([]*model.ExchangeRate, error) {
+	_, err := RequireAuthAndActivatedUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rates, err := r.Models.ExchangeRates.GetAll()
+
+	if err != nil {
+		r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+		return nil, err
+	}
+
+	return rates, nil
+}
+
+// SalaryInsights is the resolver for the salaryInsights field.
+func (r *queryResolver) SalaryInsights(ctx context.Context, title string, currency string, country *string) (*model.SalaryInsights, error) {
+	_, err := RequireAuthAndActivatedUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	currency = validator.NormalizeCurrency(currency)
+
+	var c string
+	if country != nil && *country != "" {
+		c = geo.NormalizeCountry(*country)
+	}
+
+	v := validator.New()
+
+	if model.ValidateSalaryInsightsQuery(v, title, currency, c); !v.Valid() {
+		return nil, failedValidationError(v)
+	}
+
+	insights, err := r.Models.SalaryInsights.Get(title, currency, c)
+
+	if err != nil {
+		r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+		return nil, err
+	}
+
+	return insights, nil
+}
+
+// Applicants is the resolver for the applicants field.
+func (r *queryResolver) Applicants(ctx context.Context, offerID string) ([]*model.Profile, error) {
+	user, err := RequireAuthAndActivatedUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	oId, err := strconv.ParseInt(offerID, 10, 64)
+	if err != nil {
+		return nil, errors.New("wrong user_id type")
+	}
+
+	_, err = r.requireOfferOwner(user, oId)
+	if err != nil {
+		return nil, err
+	}
+
+	profiles, err := r.Models.Offers.GetAllApplicants(oId)
+
+	if err != nil {
+		r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+		return nil, err
+	}
+
+	return profiles, nil
+}
+
+// Applications is the resolver for the applications field.
+func (r *queryResolver) Applications(ctx context.Context, offerID string) ([]*model.Application, error) {
+	user, err := RequireAuthAndActivatedUser(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	pId, err := strconv.ParseInt(id, 10, 64)
+	oId, err := strconv.ParseInt(offerID, 10, 64)
 	if err != nil {
-		return nil, errors.New("wrong profile id type")
+		return nil, errors.New("wrong offer_id type")
 	}
 
-	profile, err := r.Models.Profiles.GetProfileById(pId)
+	_, err = r.requireOfferOwner(user, oId)
+	if err != nil {
+		return nil, err
+	}
+
+	applications, err := r.Models.Applications.GetAllByOfferId(oId)
 
 	if err != nil {
 		r.Logger.PrintError(fmt.Errorf("%s", err), nil)
 		return nil, err
 	}
 
-	return profile, nil
+	return applications, nil
 }
 
-// ProfileByUserID is the resolver for the profileByUserId field.
-func (r *queryResolver) ProfileByUserID(ctx context.Context, userID string) (*model.Profile, error) {
-	_, err := RequireAuthAndActivatedUser(ctx)
+// MyApplications is the resolver for the myApplications field.
+func (r *queryResolver) MyApplications(ctx context.Context) ([]*model.Application, error) {
+	user, err := RequireAuthAndActivatedUser(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	pId, err := strconv.ParseInt(userID, 10, 64)
+	applications, err := r.Models.Applications.GetAllByUserId(user.ID)
+
 	if err != nil {
-		return nil, errors.New("wrong user_id type")
+		r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+		return nil, err
 	}
 
-	profile, err := r.Models.Profiles.GetProfileByUserId(pId)
+	return applications, nil
+}
+
+// MyOffers is the resolver for the myOffers field.
+func (r *queryResolver) MyOffers(ctx context.Context) ([]*model.Offer, error) {
+	user, err := RequireAuthAndActivatedUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	offers, err := r.Models.Offers.GetAllByUserId(user.ID)
 
 	if err != nil {
 		r.Logger.PrintError(fmt.Errorf("%s", err), nil)
 		return nil, err
 	}
 
-	return profile, nil
+	return offers, nil
 }
 
-// Bookmarks is the resolver for the bookmarks field.
-func (r *queryResolver) Bookmarks(ctx context.Context, userID string) ([]*model.Profile, error) {
+// MyResumes is the resolver for the myResumes field.
+func (r *queryResolver) MyResumes(ctx context.Context) ([]*model.Resume, error) {
 	user, err := RequireAuthAndActivatedUser(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	uId, err := strconv.ParseInt(userID, 10, 64)
+	resumes, err := r.Models.Resumes.GetAllByUserId(user.ID)
+
 	if err != nil {
-		return nil, errors.New("wrong user_id type")
+		r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+		return nil, err
 	}
 
-	if user.ID != uId {
-		return nil, errors.New("you can query only your bookmarks")
+	return resumes, nil
+}
+
+// MyCompanies is the resolver for the myCompanies field.
+func (r *queryResolver) MyCompanies(ctx context.Context) ([]*model.Company, error) {
+	user, err := RequireAuthAndActivatedUser(ctx)
+	if err != nil {
+		return nil, err
 	}
 
-	profiles, err := r.Models.Users.GetAllBookmarksByUserId(uId)
+	companies, err := r.Models.Companies.GetAllByUserId(user.ID)
 
 	if err != nil {
 		r.Logger.PrintError(fmt.Errorf("%s", err), nil)
 		return nil, err
 	}
 
-	return profiles, nil
+	return companies, nil
 }
 
-// Applicants is the resolver for the applicants field.
-func (r *queryResolver) Applicants(ctx context.Context, offerID string) ([]*model.Profile, error) {
-	_, err := RequireAuthAndActivatedUser(ctx)
+// Company is the resolver for the company field.
+func (r *queryResolver) Company(ctx context.Context, slug string) (*model.Company, error) {
+	company, err := r.Models.Companies.GetBySlug(slug)
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrRecordNotFound):
+			return nil, errors.New("company not found")
+		default:
+			r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+			return nil, err
+		}
+	}
+
+	return company, nil
+}
+
+// Companies is the resolver for the companies field.
+func (r *queryResolver) Companies(ctx context.Context, search *string, industry *string, size *model.CompanySize) ([]*model.Company, error) {
+	filters := model.CompanyFilters{Size: size}
+
+	if search != nil {
+		filters.Search = *search
+	}
+
+	if industry != nil {
+		filters.Industry = *industry
+	}
+
+	v := validator.New()
+
+	v.Check(len(filters.Search) <= 100, "search", "must not be more than 100 bytes long")
+
+	if size != nil {
+		v.Check(size.IsValid(), "size", fmt.Sprintf("%s is not a permitted company size", *size))
+	}
+
+	if !v.Valid() {
+		return nil, failedValidationError(v)
+	}
+
+	companies, err := r.Models.Companies.GetAll(filters)
+
+	if err != nil {
+		r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+		return nil, err
+	}
+
+	return companies, nil
+}
+
+// PublicOffers is the resolver for the publicOffers field.
+func (r *queryResolver) PublicOffers(ctx context.Context, minSalary *float64, maxSalary *float64, currency *string, sort *string, skills []string, near *model.NearInput) ([]*model.Offer, error) {
+	return r.searchOffers(minSalary, maxSalary, currency, sort, skills, near, true, 0)
+}
+
+// Offer is the resolver for the offer field.
+func (r *queryResolver) Offer(ctx context.Context, id string) (*model.Offer, error) {
+	offerId, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return nil, errors.New("wrong offer_id type")
+	}
+
+	offer, err := r.Models.Offers.GetById(offerId)
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrRecordNotFound):
+			return nil, errors.New("offer not found")
+		default:
+			r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+			return nil, err
+		}
+	}
+
+	manager, err := r.viewerManagesOffer(ctx, offer)
+	if err != nil {
+		return nil, err
+	}
+
+	// Inactive offers are only visible to the ones managing them.
+	if !offer.Active && !manager {
+		return nil, errors.New("offer not found")
+	}
+
+	listed, err := r.ownerListed(ctx, offer.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	// Rejected and banned offers, and the ones of suspended or deleted users, are also
+	// visible to the moderators.
+	if !(listed && offer.ModerationStatus.IsListed()) && !manager {
+		admin, err := r.viewerIsAdmin(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		if !admin {
+			return nil, errors.New("offer not found")
+		}
+	}
+
+	if !manager {
+		r.recordOfferView(ctx, offer)
+	}
+
+	return offer, nil
+}
+
+// OfferHistory is the resolver for the offerHistory field.
+func (r *queryResolver) OfferHistory(ctx context.Context, id string) ([]*model.OfferRevision, error) {
+	user, err := RequireAuthAndActivatedUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	offerId, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return nil, errors.New("wrong offer_id type")
+	}
+
+	_, err = r.requireOfferOwner(user, offerId)
+	if err != nil {
+		return nil, err
+	}
+
+	revisions, err := r.Models.Offers.GetRevisions(offerId)
+
+	if err != nil {
+		r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+		return nil, err
+	}
+
+	return revisions, nil
+}
+
+// DuplicateOffers is the resolver for the duplicateOffers field.
+func (r *queryResolver) DuplicateOffers(ctx context.Context, limit *int) ([]*model.OfferDuplicate, error) {
+	_, err := r.requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	l := defaultDuplicatesLimit
+	if limit != nil && *limit > 0 {
+		l = *limit
+	}
+
+	if l > maxDuplicatesLimit {
+		l = maxDuplicatesLimit
+	}
+
+	duplicates, err := r.Models.Offers.GetAllDuplicates(l)
+
+	if err != nil {
+		r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+		return nil, err
+	}
+
+	return duplicates, nil
+}
+
+// OfferAnalytics is the resolver for the offerAnalytics field.
+func (r *queryResolver) OfferAnalytics(ctx context.Context, offerID string, from *time.Time, to *time.Time) (*model.OfferAnalytics, error) {
+	user, err := RequireAuthAndActivatedUser(ctx)
 	if err != nil {
 		return nil, err
 	}
 
 	oId, err := strconv.ParseInt(offerID, 10, 64)
 	if err != nil {
-		return nil, errors.New("wrong user_id type")
+		return nil, errors.New("wrong offer_id type")
 	}
 
-	profiles, err := r.Models.Offers.GetAllApplicants(oId)
+	_, err = r.requireOfferOwner(user, oId)
+	if err != nil {
+		return nil, err
+	}
+
+	end := time.Now()
+	if to != nil {
+		end = *to
+	}
+
+	start := end.Add(-model.DefaultAnalyticsPeriod)
+	if from != nil {
+		start = *from
+	}
+
+	v := validator.New()
+
+	if model.ValidateAnalyticsPeriod(v, start, end); !v.Valid() {
+		return nil, failedValidationError(v)
+	}
+
+	analytics, err := r.Models.Offers.GetAnalytics(oId, start, end)
 
 	if err != nil {
 		r.Logger.PrintError(fmt.Errorf("%s", err), nil)
 		return nil, err
 	}
 
-	return profiles, nil
+	return analytics, nil
+}
+
+// ModerationQueue is the resolver for the moderationQueue field.
+func (r *queryResolver) ModerationQueue(ctx context.Context, limit *int) ([]*model.ModerationItem, error) {
+	_, err := r.requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	l := defaultModerationQueueLimit
+	if limit != nil && *limit > 0 {
+		l = *limit
+	}
+
+	if l > maxModerationQueueLimit {
+		l = maxModerationQueueLimit
+	}
+
+	items, err := r.Models.Moderation.GetQueue(l)
+
+	if err != nil {
+		r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// Reporter is the resolver for the reporter field.
+func (r *reportResolver) Reporter(ctx context.Context, obj *model.Report) (*model.User, error) {
+	return dataloaders.For(ctx).GetUser(ctx, strconv.FormatInt(obj.UserId, 10))
+}
+
+// DownloadURL is the resolver for the downloadUrl field.
+func (r *resumeResolver) DownloadURL(ctx context.Context, obj *model.Resume) (string, error) {
+	user, err := RequireAuthAndActivatedUser(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	path := fmt.Sprintf("/resumes/%d/download", obj.ID)
+
+	return strings.TrimSuffix(r.PublicURL, "/") + r.Signer.Sign(path, user.ID, time.Now().Add(r.DownloadURLTTL)), nil
+}
+
+// SalaryIn is the resolver for the salaryIn field.
+func (r *salaryByRoleResultResolver) SalaryIn(ctx context.Context, obj *model.SalaryByRoleResult, currency string) (*model.SalaryByRoleResult, error) {
+	currency = validator.NormalizeCurrency(currency)
+
+	rates, err := dataloaders.For(ctx).GetRates(ctx, obj.Currency, currency)
+
+	if err != nil {
+		r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+		return nil, err
+	}
+
+	min, ok := rates.Convert(obj.Min, obj.Currency, currency)
+	if !ok {
+		return nil, nil
+	}
+
+	max, _ := rates.Convert(obj.Max, obj.Currency, currency)
+
+	return &model.SalaryByRoleResult{
+		Title:    obj.Title,
+		Min:      min,
+		Max:      max,
+		Currency: currency,
+		Period:   obj.Period,
+		Type:     obj.Type,
+	}, nil
+}
+
+// Email is the resolver for the email field.
+func (r *userResolver) Email(ctx context.Context, obj *model.User) (*string, error) {
+	if obj.IsPublic() {
+		return nil, nil
+	}
+
+	return &obj.Email, nil
+}
+
+// Version is the resolver for the version field.
+func (r *userResolver) Version(ctx context.Context, obj *model.User) (*int, error) {
+	if obj.IsPublic() {
+		return nil, nil
+	}
+
+	return &obj.Version, nil
 }
 
 // Roles is the resolver for the roles field.
 func (r *userResolver) Roles(ctx context.Context, obj *model.User) ([]string, error) {
+	if obj.IsPublic() {
+		return []string{}, nil
+	}
+
 	_, err := RequireAuthAndActivatedUser(ctx)
 	if err != nil {
 		return nil, err
@@ -482,23 +3463,82 @@ func (r *userResolver) Roles(ctx context.Context, obj *model.User) ([]string, er
 	return roles, nil
 }
 
+// SuspendedAt is the resolver for the suspendedAt field.
+func (r *userResolver) SuspendedAt(ctx context.Context, obj *model.User) (*time.Time, error) {
+	if obj.IsPublic() {
+		return nil, nil
+	}
+
+	return obj.SuspendedAt, nil
+}
+
+// Application returns ApplicationResolver implementation.
+func (r *Resolver) Application() ApplicationResolver { return &applicationResolver{r} }
+
+// Bookmark returns BookmarkResolver implementation.
+func (r *Resolver) Bookmark() BookmarkResolver { return &bookmarkResolver{r} }
+
+// BookmarkCollection returns BookmarkCollectionResolver implementation.
+func (r *Resolver) BookmarkCollection() BookmarkCollectionResolver {
+	return &bookmarkCollectionResolver{r}
+}
+
+// Company returns CompanyResolver implementation.
+func (r *Resolver) Company() CompanyResolver { return &companyResolver{r} }
+
+// CompanyMember returns CompanyMemberResolver implementation.
+func (r *Resolver) CompanyMember() CompanyMemberResolver { return &companyMemberResolver{r} }
+
+// ModerationDecision returns ModerationDecisionResolver implementation.
+func (r *Resolver) ModerationDecision() ModerationDecisionResolver {
+	return &moderationDecisionResolver{r}
+}
+
+// ModerationItem returns ModerationItemResolver implementation.
+func (r *Resolver) ModerationItem() ModerationItemResolver { return &moderationItemResolver{r} }
+
 // Mutation returns MutationResolver implementation.
 func (r *Resolver) Mutation() MutationResolver { return &mutationResolver{r} }
 
 // Offer returns OfferResolver implementation.
 func (r *Resolver) Offer() OfferResolver { return &offerResolver{r} }
 
+// OfferRevision returns OfferRevisionResolver implementation.
+func (r *Resolver) OfferRevision() OfferRevisionResolver { return &offerRevisionResolver{r} }
+
 // Profile returns ProfileResolver implementation.
 func (r *Resolver) Profile() ProfileResolver { return &profileResolver{r} }
 
 // Query returns QueryResolver implementation.
 func (r *Resolver) Query() QueryResolver { return &queryResolver{r} }
 
+// Report returns ReportResolver implementation.
+func (r *Resolver) Report() ReportResolver { return &reportResolver{r} }
+
+// Resume returns ResumeResolver implementation.
+func (r *Resolver) Resume() ResumeResolver { return &resumeResolver{r} }
+
+// SalaryByRoleResult returns SalaryByRoleResultResolver implementation.
+func (r *Resolver) SalaryByRoleResult() SalaryByRoleResultResolver {
+	return &salaryByRoleResultResolver{r}
+}
+
 // User returns UserResolver implementation.
 func (r *Resolver) User() UserResolver { return &userResolver{r} }
 
+type applicationResolver struct{ *Resolver }
+type bookmarkResolver struct{ *Resolver }
+type bookmarkCollectionResolver struct{ *Resolver }
+type companyResolver struct{ *Resolver }
+type companyMemberResolver struct{ *Resolver }
+type moderationDecisionResolver struct{ *Resolver }
+type moderationItemResolver struct{ *Resolver }
 type mutationResolver struct{ *Resolver }
 type offerResolver struct{ *Resolver }
+type offerRevisionResolver struct{ *Resolver }
 type profileResolver struct{ *Resolver }
 type queryResolver struct{ *Resolver }
+type reportResolver struct{ *Resolver }
+type resumeResolver struct{ *Resolver }
+type salaryByRoleResultResolver struct{ *Resolver }
 type userResolver struct{ *Resolver }
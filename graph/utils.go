@@ -1,9 +1,36 @@
 package graph
 
 import (
+	"bytes"
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
+	"fmt"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"image"
+	"io"
+	"itfinder.adrianescat.com/graph/dataloaders"
 	"itfinder.adrianescat.com/graph/model"
+	"itfinder.adrianescat.com/internal/geo"
+	"itfinder.adrianescat.com/internal/imaging"
+	"itfinder.adrianescat.com/internal/matching"
+	"itfinder.adrianescat.com/internal/storage"
+	"itfinder.adrianescat.com/internal/validator"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultRecommendationsLimit = 20
+	maxRecommendationsLimit     = 100
+
+	defaultDuplicatesLimit = 50
+	maxDuplicatesLimit     = 200
+
+	defaultModerationQueueLimit = 50
+	maxModerationQueueLimit     = 200
 )
 
 func RequireAuthAndActivatedUser(ctx context.Context) (*model.User, error) {
@@ -19,3 +46,987 @@ func RequireAuthAndActivatedUser(ctx context.Context) (*model.User, error) {
 
 	return userFromCtx, nil
 }
+
+// failedValidationError returns the validation errors keyed by the path of the
+// offending field, for example salary[2].min, in the error extensions.
+func failedValidationError(v *validator.Validator) error {
+	return &gqlerror.Error{
+		Message: "wrong inputs",
+		Extensions: map[string]interface{}{
+			"code":   "VALIDATION_FAILED",
+			"errors": v.Errors,
+		},
+	}
+}
+
+func offerQuestionsFromInput(input []*model.OfferQuestionInput) []*model.OfferQuestion {
+	var questions []*model.OfferQuestion
+
+	for _, q := range input {
+		question := &model.OfferQuestion{
+			Question: q.Question,
+			Kind:     q.Kind,
+			Options:  q.Options,
+		}
+
+		if q.Required != nil {
+			question.Required = *q.Required
+		}
+
+		questions = append(questions, question)
+	}
+
+	return questions
+}
+
+func applicationAnswersFromInput(input []*model.ApplicationAnswerInput) ([]*model.ApplicationAnswer, error) {
+	var answers []*model.ApplicationAnswer
+
+	for _, a := range input {
+		qId, err := strconv.ParseInt(a.QuestionID, 10, 64)
+		if err != nil {
+			return nil, errors.New("wrong question_id type")
+		}
+
+		answers = append(answers, &model.ApplicationAnswer{
+			QuestionId: qId,
+			Answer:     a.Answer,
+		})
+	}
+
+	return answers, nil
+}
+
+// bookmarkFromInput builds the bookmark of the user for the optional profile and offer
+// ids received as arguments.
+func bookmarkFromInput(userId int64, profileID *string, offerID *string) (*model.Bookmark, error) {
+	bookmark := &model.Bookmark{
+		UserId: userId,
+	}
+
+	if profileID != nil {
+		pId, err := strconv.ParseInt(*profileID, 10, 64)
+		if err != nil {
+			return nil, errors.New("wrong profile_id type")
+		}
+
+		bookmark.ProfileId = &pId
+	}
+
+	if offerID != nil {
+		oId, err := strconv.ParseInt(*offerID, 10, 64)
+		if err != nil {
+			return nil, errors.New("wrong offer_id type")
+		}
+
+		bookmark.OfferId = &oId
+	}
+
+	return bookmark, nil
+}
+
+func savedSearchFromInput(input model.SavedSearchInput) *model.SavedSearch {
+	search := &model.SavedSearch{
+		Name:      input.Name,
+		MinSalary: input.MinSalary,
+		MaxSalary: input.MaxSalary,
+		Frequency: input.Frequency,
+	}
+
+	if input.Keywords != nil {
+		search.Keywords = *input.Keywords
+	}
+
+	if input.Currency != nil {
+		search.Currency = validator.NormalizeCurrency(*input.Currency)
+	}
+
+	if input.Location != nil {
+		search.Location = *input.Location
+	}
+
+	return search
+}
+
+// matchingSalaries converts the salaries to monthly amounts, so salaries of different
+// periods can be compared.
+func matchingSalaries(salaries model.Salaries) []matching.Salary {
+	var result []matching.Salary
+
+	for _, s := range salaries {
+		if s == nil {
+			continue
+		}
+
+		factor := model.MonthlyFactor(s.Period)
+
+		result = append(result, matching.Salary{
+			Title:    s.Title,
+			Min:      s.Min * factor,
+			Max:      s.Max * factor,
+			Currency: s.Currency,
+		})
+	}
+
+	return result
+}
+
+// matchingOffer converts the offer to the input of the scoring package. Remote offers
+// are open to candidates anywhere, so their location is left out of the score.
+func matchingOffer(offer *model.Offer) matching.Offer {
+	o := matching.Offer{
+		Title:    offer.Title,
+		Salaries: matchingSalaries(offer.Salary),
+	}
+
+	if offer.WorkMode != model.WorkModeRemote {
+		o.Country = offer.Country
+		o.State = offer.State
+		o.City = offer.City
+	}
+
+	return o
+}
+
+func matchingCandidate(profile *model.Profile) matching.Candidate {
+	return matching.Candidate{
+		Title:    profile.Title,
+		Status:   profile.Status,
+		Salaries: matchingSalaries(profile.Salary),
+		Country:  profile.Country,
+		State:    profile.State,
+		City:     profile.City,
+	}
+}
+
+// recommendationsLimit returns how many of the n recommendations should be returned.
+func recommendationsLimit(limit *int, n int) int {
+	l := defaultRecommendationsLimit
+	if limit != nil && *limit > 0 {
+		l = *limit
+	}
+
+	if l > maxRecommendationsLimit {
+		l = maxRecommendationsLimit
+	}
+
+	if l > n {
+		l = n
+	}
+
+	return l
+}
+
+// requireAdmin checks that the user in the context has the admin or the superadmin
+// role.
+func (r *Resolver) requireAdmin(ctx context.Context) (*model.User, error) {
+	user, err := RequireAuthAndActivatedUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	admin, err := r.isAdmin(user)
+	if err != nil {
+		return nil, err
+	}
+
+	if !admin {
+		return nil, errors.New("you must be an admin to access this resource")
+	}
+
+	return user, nil
+}
+
+// isAdmin reports whether the user has the admin or the superadmin role.
+func (r *Resolver) isAdmin(user *model.User) (bool, error) {
+	roles, err := r.Models.Users.GetRolesByUserId(user.ID)
+	if err != nil {
+		r.Logger.PrintError(err, nil)
+		return false, err
+	}
+
+	for _, role := range roles {
+		if role == "admin" || role == "superadmin" {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// viewerIsAdmin is isAdmin for the user of the request, anonymous users are not admins.
+func (r *Resolver) viewerIsAdmin(ctx context.Context) (bool, error) {
+	viewer := ctx.Value("user").(*model.User)
+
+	if viewer.IsAnonymous() {
+		return false, nil
+	}
+
+	return r.isAdmin(viewer)
+}
+
+// ownerListed reports whether the user owning an offer or a profile is neither suspended
+// nor deleted.
+func (r *Resolver) ownerListed(ctx context.Context, userId int64) (bool, error) {
+	owner, err := dataloaders.For(ctx).GetUser(ctx, strconv.FormatInt(userId, 10))
+	if err != nil {
+		r.Logger.PrintError(err, nil)
+		return false, err
+	}
+
+	return owner.IsListed(), nil
+}
+
+// listedProfile returns the profile unless it was rejected or banned, or its candidate is
+// suspended or deleted, those are only visible to their candidate and to the moderators.
+func (r *Resolver) listedProfile(ctx context.Context, profile *model.Profile) (*model.Profile, error) {
+	listed, err := r.ownerListed(ctx, profile.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	if listed && profile.ModerationStatus.IsListed() {
+		return profile, nil
+	}
+
+	viewer := ctx.Value("user").(*model.User)
+
+	if viewer.ID == profile.UserId {
+		return profile, nil
+	}
+
+	admin, err := r.viewerIsAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if !admin {
+		return nil, errors.New("profile not found")
+	}
+
+	return profile, nil
+}
+
+// moderationDecisionFromInput builds the decision of the moderator for the optional offer
+// and profile ids received as arguments.
+func moderationDecisionFromInput(moderatorId int64, action model.ModerationAction, offerID *string, profileID *string, note *string) (*model.ModerationDecision, error) {
+	decision := &model.ModerationDecision{
+		ModeratorId: &moderatorId,
+		Action:      action,
+	}
+
+	if offerID != nil {
+		oId, err := strconv.ParseInt(*offerID, 10, 64)
+		if err != nil {
+			return nil, errors.New("wrong offer_id type")
+		}
+
+		decision.OfferId = &oId
+	}
+
+	if profileID != nil {
+		pId, err := strconv.ParseInt(*profileID, 10, 64)
+		if err != nil {
+			return nil, errors.New("wrong profile_id type")
+		}
+
+		decision.ProfileId = &pId
+	}
+
+	if note != nil {
+		decision.Note = strings.TrimSpace(*note)
+	}
+
+	return decision, nil
+}
+
+// moderate applies the action of the admin of the request to the offer or the profile and
+// resolves its reports.
+func (r *Resolver) moderate(ctx context.Context, action model.ModerationAction, offerID *string, profileID *string, note *string) (*model.ModerationDecision, error) {
+	user, err := r.requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	decision, err := moderationDecisionFromInput(user.ID, action, offerID, profileID, note)
+	if err != nil {
+		return nil, err
+	}
+
+	v := validator.New()
+
+	var status model.ModerationStatus
+
+	if decision.OfferId != nil || decision.ProfileId != nil {
+		status, err = r.Models.Moderation.GetStatus(decision.OfferId, decision.ProfileId)
+		if err != nil {
+			switch {
+			case errors.Is(err, model.ErrRecordNotFound):
+				return nil, errors.New("content not found")
+			default:
+				r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+				return nil, err
+			}
+		}
+	}
+
+	if model.ValidateModerationDecision(v, decision, status); !v.Valid() {
+		return nil, failedValidationError(v)
+	}
+
+	err = r.Models.Moderation.Decide(decision)
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrEditConflict):
+			return nil, errors.New("the content was modified, please try again")
+		default:
+			r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+			return nil, err
+		}
+	}
+
+	return decision, nil
+}
+
+// reportContent records the report of the user of the request.
+func (r *Resolver) reportContent(report *model.Report, details *string) (*model.ReportResponse, error) {
+	if details != nil {
+		report.Details = strings.TrimSpace(*details)
+	}
+
+	v := validator.New()
+
+	if model.ValidateReport(v, report); !v.Valid() {
+		return nil, failedValidationError(v)
+	}
+
+	err := r.Models.Moderation.InsertReport(report)
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrDuplicateReport):
+			return nil, errors.New("you already reported it")
+		default:
+			r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+			return nil, err
+		}
+	}
+
+	return &model.ReportResponse{Success: true}, nil
+}
+
+// setUserSuspended suspends or unsuspends the user on behalf of the admin of the request.
+func (r *Resolver) setUserSuspended(ctx context.Context, id string, suspended bool) (*model.User, error) {
+	admin, err := r.requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	uId, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return nil, errors.New("wrong user_id type")
+	}
+
+	if uId == admin.ID {
+		return nil, errors.New("you can't suspend yourself")
+	}
+
+	user, err := r.Models.Users.GetById(uId)
+	if err == nil {
+		if suspended {
+			err = r.Models.Users.Suspend(user)
+		} else {
+			err = r.Models.Users.Unsuspend(user)
+		}
+	}
+
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrRecordNotFound):
+			return nil, errors.New("user not found")
+		default:
+			r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+			return nil, err
+		}
+	}
+
+	return user, nil
+}
+
+// eraseFiles deletes the pictures and the resumes of a deleted account in the background.
+func (r *Resolver) eraseFiles(files *model.ErasedFiles) {
+	for _, url := range files.PictureUrls {
+		r.replacePicture(url)
+	}
+
+	r.Background(func() {
+		for _, key := range files.ResumeKeys {
+			err := r.Storage.Delete(context.Background(), key)
+			if err != nil && !errors.Is(err, storage.ErrNotFound) {
+				r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+			}
+		}
+	})
+}
+
+// profileViewer returns the relationship of the user of the request with the candidate of
+// the profile. It's loaded once per profile and request, however many masked fields are
+// resolved.
+func (r *Resolver) profileViewer(ctx context.Context, profile *model.Profile) (model.ProfileViewer, error) {
+	viewer := ctx.Value("user").(*model.User)
+
+	relation, err := dataloaders.For(ctx).GetProfileViewer(ctx, profile, viewer)
+	if err != nil {
+		r.Logger.PrintError(err, nil)
+		return model.ViewerAnonymous, err
+	}
+
+	return relation, nil
+}
+
+// profileDetailsVisible reports whether the user of the request can see the email, the
+// website and the salary of the profile.
+func (r *Resolver) profileDetailsVisible(ctx context.Context, profile *model.Profile) (bool, error) {
+	relation, err := r.profileViewer(ctx, profile)
+	if err != nil {
+		return false, err
+	}
+
+	return model.ProfileDetailsVisible(profile.Visibility, relation), nil
+}
+
+// profileIdentityVisible reports whether the user of the request can tell who the candidate
+// of the profile is.
+func (r *Resolver) profileIdentityVisible(ctx context.Context, profile *model.Profile) (bool, error) {
+	relation, err := r.profileViewer(ctx, profile)
+	if err != nil {
+		return false, err
+	}
+
+	return model.ProfileIdentityVisible(relation), nil
+}
+
+// saveExchangeRates validates and stores the rates uploaded by an admin.
+func (r *Resolver) saveExchangeRates(user *model.User, rates []*model.ExchangeRate) ([]*model.ExchangeRate, error) {
+	v := validator.New()
+
+	if model.ValidateExchangeRates(v, rates); !v.Valid() {
+		return nil, failedValidationError(v)
+	}
+
+	err := r.Models.ExchangeRates.Upsert(rates, user.ID)
+	if err != nil {
+		r.Logger.PrintError(err, nil)
+		return nil, err
+	}
+
+	return rates, nil
+}
+
+// requireOfferOwner loads the offer and checks that the user can manage it: they
+// created it, or it belongs to a company they are a member of.
+func (r *Resolver) requireOfferOwner(user *model.User, offerId int64) (*model.Offer, error) {
+	offer, err := r.Models.Offers.GetById(offerId)
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrRecordNotFound):
+			return nil, errors.New("offer not found")
+		default:
+			r.Logger.PrintError(err, nil)
+			return nil, err
+		}
+	}
+
+	manager, err := r.canManageOffer(user, offer)
+	if err != nil {
+		return nil, err
+	}
+
+	if !manager {
+		return nil, errors.New("you can access only your offers")
+	}
+
+	return offer, nil
+}
+
+// recordSavedSearchMatches matches a newly published offer against the saved searches in
+// the background, the alerts are sent by the periodic digest job.
+func (r *Resolver) recordSavedSearchMatches(offerId int64) {
+	r.Background(func() {
+		_, err := r.Models.SavedSearches.RecordMatches(offerId)
+		if err != nil {
+			r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+		}
+	})
+}
+
+// canManageOffer reports whether the user created the offer or is a member of the company
+// it was published for. The offers of a company are managed by its members only, so
+// removed members lose access to the ones they created.
+func (r *Resolver) canManageOffer(user *model.User, offer *model.Offer) (bool, error) {
+	if user.IsAnonymous() {
+		return false, nil
+	}
+
+	if offer.CompanyId == nil {
+		return offer.UserId == user.ID, nil
+	}
+
+	_, err := r.Models.Companies.GetMember(*offer.CompanyId, user.ID)
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.Is(err, model.ErrRecordNotFound):
+		return false, nil
+	default:
+		r.Logger.PrintError(err, nil)
+		return false, err
+	}
+}
+
+// viewerManagesOffer is canManageOffer for the user of the request.
+func (r *Resolver) viewerManagesOffer(ctx context.Context, offer *model.Offer) (bool, error) {
+	viewer := ctx.Value("user").(*model.User)
+
+	return r.canManageOffer(viewer, offer)
+}
+
+// recordOfferView counts the view of the offer by the user of the request, or by the
+// client when it's anonymous, in the background.
+func (r *Resolver) recordOfferView(ctx context.Context, offer *model.Offer) {
+	viewer := ctx.Value("user").(*model.User)
+
+	var viewerKey string
+	var userId *int64
+
+	if viewer.IsAnonymous() {
+		client, ok := ctx.Value("client").(string)
+		if !ok {
+			return
+		}
+
+		viewerKey = "client:" + client
+	} else {
+		viewerKey = fmt.Sprintf("user:%d", viewer.ID)
+		userId = &viewer.ID
+	}
+
+	r.Background(func() {
+		err := r.Models.Offers.RecordView(offer.ID, viewerKey, userId)
+		if err != nil {
+			r.Logger.PrintError(err, nil)
+		}
+	})
+}
+
+// searchOffers validates the offers filters and lists the matching offers. The inactive
+// ones are left out, unless activeOnly is false and the manager manages them.
+func (r *Resolver) searchOffers(minSalary *float64, maxSalary *float64, currency *string, sort *string, skills []string, near *model.NearInput, activeOnly bool, managerId int64) ([]*model.Offer, error) {
+	var err error
+
+	filters := model.OfferFilters{
+		MinSalary:  minSalary,
+		MaxSalary:  maxSalary,
+		Currency:   model.BaseCurrency,
+		ActiveOnly: activeOnly,
+		ManagerId:  managerId,
+		Filters: model.Filters{
+			Sort:         "id",
+			SortSafelist: model.OfferSortSafelist,
+		},
+	}
+
+	if currency != nil {
+		filters.Currency = validator.NormalizeCurrency(*currency)
+	}
+
+	if sort != nil {
+		filters.Sort = *sort
+	}
+
+	filters.SkillIds, err = r.resolveSkills(skills)
+	if err != nil {
+		return nil, err
+	}
+
+	v := validator.New()
+
+	filters.Near = geoFilterFromInput(v, near)
+
+	if model.ValidateOfferFilters(v, filters); !v.Valid() {
+		return nil, failedValidationError(v)
+	}
+
+	offers, err := r.Models.Offers.GetAll(filters)
+
+	if err != nil {
+		r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+		return nil, err
+	}
+
+	return offers, nil
+}
+
+// companyFromInput sets the company fields from the input, omitted fields are cleared.
+func companyFromInput(company *model.Company, input model.CompanyInput) {
+	company.Name = strings.TrimSpace(input.Name)
+	company.WebsiteUrl = ""
+	company.LogoUrl = ""
+	company.Description = ""
+	company.Size = input.Size
+	company.Industry = ""
+	company.Locations = nil
+
+	if input.WebsiteURL != nil {
+		company.WebsiteUrl = strings.TrimSpace(*input.WebsiteURL)
+	}
+
+	if input.LogoURL != nil {
+		company.LogoUrl = strings.TrimSpace(*input.LogoURL)
+	}
+
+	if input.Description != nil {
+		company.Description = strings.TrimSpace(*input.Description)
+	}
+
+	if input.Industry != nil {
+		company.Industry = strings.TrimSpace(*input.Industry)
+	}
+
+	for _, l := range input.Locations {
+		location := &model.CompanyLocation{Country: geo.NormalizeCountry(l.Country)}
+
+		if l.State != nil {
+			location.State = geo.NormalizeSubdivision(location.Country, *l.State)
+		}
+
+		if l.City != nil {
+			location.City = strings.TrimSpace(*l.City)
+		}
+
+		company.Locations = append(company.Locations, location)
+	}
+}
+
+// requireCompanyMember checks that the user is a member of the company and, when roles
+// are given, that their role is one of them.
+func (r *Resolver) requireCompanyMember(user *model.User, companyId int64, roles ...model.CompanyRole) (*model.CompanyMember, error) {
+	member, err := r.Models.Companies.GetMember(companyId, user.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrRecordNotFound):
+			return nil, errors.New("you are not a member of this company")
+		default:
+			r.Logger.PrintError(err, nil)
+			return nil, err
+		}
+	}
+
+	if len(roles) > 0 && !validator.PermittedValue(member.Role, roles...) {
+		return nil, errors.New("your role in the company doesn't allow this action")
+	}
+
+	return member, nil
+}
+
+// resolveSkills maps the skill names, or aliases, to their distinct skill ids in the same
+// order.
+// Unknown skills are reported as validation errors keyed by their position.
+func (r *Resolver) resolveSkills(names []string) ([]int64, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	resolved, err := r.Models.Skills.ResolveNames(names)
+	if err != nil {
+		r.Logger.PrintError(err, nil)
+		return nil, err
+	}
+
+	v := validator.New()
+	ids := make([]int64, 0, len(names))
+	seen := make(map[int64]bool, len(names))
+
+	for i, name := range names {
+		id, ok := resolved[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			v.AddError(fmt.Sprintf("skills[%d]", i), fmt.Sprintf("%s is not a known skill", name))
+			continue
+		}
+
+		// A skill and its aliases resolve to the same id, which is kept only once.
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		ids = append(ids, id)
+	}
+
+	if !v.Valid() {
+		return nil, failedValidationError(v)
+	}
+
+	return ids, nil
+}
+
+// requireProfileOwner loads the profile and checks that it belongs to the given user.
+func (r *Resolver) requireProfileOwner(user *model.User, profileId int64) (*model.Profile, error) {
+	profile, err := r.Models.Profiles.GetProfileById(profileId)
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrRecordNotFound):
+			return nil, errors.New("profile not found")
+		default:
+			r.Logger.PrintError(err, nil)
+			return nil, err
+		}
+	}
+
+	if profile.UserId != user.ID {
+		return nil, errors.New("you can access only your profile")
+	}
+
+	return profile, nil
+}
+
+func experienceFromInput(input model.ExperienceInput) *model.Experience {
+	experience := &model.Experience{
+		Company:   input.Company,
+		Role:      input.Role,
+		StartDate: input.StartDate,
+		EndDate:   input.EndDate,
+		Current:   input.Current,
+	}
+
+	if input.Description != nil {
+		experience.Description = *input.Description
+	}
+
+	return experience
+}
+
+func educationFromInput(input model.EducationInput) *model.Education {
+	education := &model.Education{
+		Institution: input.Institution,
+		Degree:      input.Degree,
+		StartDate:   input.StartDate,
+		EndDate:     input.EndDate,
+		Current:     input.Current,
+	}
+
+	if input.FieldOfStudy != nil {
+		education.FieldOfStudy = *input.FieldOfStudy
+	}
+
+	if input.Description != nil {
+		education.Description = *input.Description
+	}
+
+	return education
+}
+
+// parseIDs converts the ids received as arguments, name is used in the error message.
+func parseIDs(ids []string, name string) ([]int64, error) {
+	parsed := make([]int64, 0, len(ids))
+
+	for _, id := range ids {
+		i, err := strconv.ParseInt(id, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("wrong %s type", name)
+		}
+
+		parsed = append(parsed, i)
+	}
+
+	return parsed, nil
+}
+
+// requireExperienceOwner loads the experience and checks that its profile belongs to
+// the given user.
+func (r *Resolver) requireExperienceOwner(user *model.User, id int64) (*model.Experience, error) {
+	experience, err := r.Models.Experiences.Get(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrRecordNotFound):
+			return nil, errors.New("experience not found")
+		default:
+			r.Logger.PrintError(err, nil)
+			return nil, err
+		}
+	}
+
+	_, err = r.requireProfileOwner(user, experience.ProfileId)
+	if err != nil {
+		return nil, err
+	}
+
+	return experience, nil
+}
+
+// requireEducationOwner loads the education and checks that its profile belongs to the
+// given user.
+func (r *Resolver) requireEducationOwner(user *model.User, id int64) (*model.Education, error) {
+	education, err := r.Models.Educations.Get(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrRecordNotFound):
+			return nil, errors.New("education not found")
+		default:
+			r.Logger.PrintError(err, nil)
+			return nil, err
+		}
+	}
+
+	_, err = r.requireProfileOwner(user, education.ProfileId)
+	if err != nil {
+		return nil, err
+	}
+
+	return education, nil
+}
+
+// geoFilterFromInput resolves the centre of the proximity search from the gazetteer.
+// Unknown cities are reported in the validator.
+func geoFilterFromInput(v *validator.Validator, near *model.NearInput) *model.GeoFilter {
+	if near == nil {
+		return nil
+	}
+
+	country := geo.NormalizeCountry(near.Country)
+
+	var state string
+	if near.State != nil {
+		state = geo.NormalizeSubdivision(country, *near.State)
+	}
+
+	city, ok := geo.LookupCity(country, state, near.City)
+	if !ok {
+		v.AddError("near.city", "must be a known city of the country")
+		return nil
+	}
+
+	return &model.GeoFilter{
+		Latitude:  city.Latitude,
+		Longitude: city.Longitude,
+		RadiusKm:  near.RadiusKm,
+	}
+}
+
+// sniffLen is the number of bytes read to detect the content type of an upload.
+const sniffLen = 512
+
+// readHead returns the first bytes of the upload and rewinds it, so it can be stored
+// from the start afterwards.
+func readHead(file io.ReadSeeker) ([]byte, error) {
+	head := make([]byte, sniffLen)
+
+	n, err := io.ReadFull(file, head)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, err
+	}
+
+	_, err = file.Seek(0, io.SeekStart)
+	if err != nil {
+		return nil, err
+	}
+
+	return head[:n], nil
+}
+
+// uploadFilename keeps the base name of the file sent by the client, some browsers
+// send the full path, with either kind of separator.
+func uploadFilename(filename string) string {
+	filename = filename[strings.LastIndexAny(filename, `/\`)+1:]
+	return strings.TrimSpace(filename)
+}
+
+// randomID returns 32 random hex characters, used to name the stored files so their
+// URLs can't be guessed.
+func randomID() (string, error) {
+	b := make([]byte, 16)
+
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+// newStorageKey builds a random storage key below the prefix.
+func newStorageKey(prefix string, ext string) (string, error) {
+	id, err := randomID()
+	if err != nil {
+		return "", err
+	}
+
+	return prefix + "/" + id + ext, nil
+}
+
+// storeUpload writes size bytes of the file to the storage and returns their SHA-256
+// checksum.
+func (r *Resolver) storeUpload(ctx context.Context, key string, file io.Reader, size int64, contentType string) (string, error) {
+	hash := sha256.New()
+
+	err := r.Storage.Put(ctx, key, io.TeeReader(io.LimitReader(file, size), hash), size, contentType)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// storePicture stores a thumbnail of the image for every picture size.
+func (r *Resolver) storePicture(ctx context.Context, img image.Image, format string) (*model.Picture, error) {
+	id, err := randomID()
+	if err != nil {
+		return nil, err
+	}
+
+	picture := model.NewPicture(r.PublicURL, id, format)
+
+	for size, pixels := range model.PictureSizes {
+		var buf bytes.Buffer
+
+		err = imaging.Encode(&buf, imaging.Thumbnail(img, pixels), format)
+		if err == nil {
+			contentType := "image/jpeg"
+			if format == imaging.FormatPNG {
+				contentType = "image/png"
+			}
+
+			err = r.Storage.Put(ctx, model.PictureKey(id, size, format), &buf, int64(buf.Len()), contentType)
+		}
+
+		if err != nil {
+			r.deletePicture(picture)
+			return nil, err
+		}
+	}
+
+	return picture, nil
+}
+
+// deletePicture removes every size of the picture from the storage. Missing files are
+// ignored, so it can clean up pictures that were only partially stored.
+func (r *Resolver) deletePicture(picture *model.Picture) {
+	for size := range model.PictureSizes {
+		err := r.Storage.Delete(context.Background(), model.PictureKey(picture.ID, size, picture.Format))
+		if err != nil && !errors.Is(err, storage.ErrNotFound) {
+			r.Logger.PrintError(fmt.Errorf("%s", err), nil)
+		}
+	}
+}
+
+// replacePicture deletes the previous picture of a profile or offer in the background,
+// when it was uploaded to the API.
+func (r *Resolver) replacePicture(previousUrl string) {
+	previous, ok := model.PictureFromURL(r.PublicURL, previousUrl)
+	if !ok {
+		return
+	}
+
+	r.Background(func() {
+		r.deletePicture(previous)
+	})
+}
@@ -0,0 +1,104 @@
+package geo
+
+import (
+	"math"
+	"strconv"
+)
+
+// EarthRadiusKm is the mean radius of the Earth used by the distance calculations.
+const EarthRadiusKm = 6371.0
+
+// City is an entry of the embedded gazetteer. Subdivision is empty for the countries
+// whose subdivisions are not part of the reference.
+type City struct {
+	CountryCode string
+	Subdivision string
+	Name        string
+	Latitude    float64
+	Longitude   float64
+}
+
+var cities = make(map[string][]City)
+
+func init() {
+	for _, record := range readCSV("data/cities.csv") {
+		lat, err := strconv.ParseFloat(record[3], 64)
+		if err != nil {
+			panic(err)
+		}
+
+		lon, err := strconv.ParseFloat(record[4], 64)
+		if err != nil {
+			panic(err)
+		}
+
+		c := City{CountryCode: record[0], Subdivision: record[1], Name: record[2], Latitude: lat, Longitude: lon}
+		key := c.CountryCode + "|" + Fold(c.Name)
+
+		cities[key] = append(cities[key], c)
+	}
+}
+
+// LookupCity returns the gazetteer city of the country with the given name, ignoring
+// case and accents. When several cities share the name, the one in the given state is
+// preferred.
+func LookupCity(countryCode string, state string, name string) (City, bool) {
+	matches := cities[countryCode+"|"+Fold(name)]
+
+	if len(matches) == 0 {
+		return City{}, false
+	}
+
+	for _, c := range matches {
+		if c.Subdivision != "" && c.Subdivision == state {
+			return c, true
+		}
+	}
+
+	return matches[0], true
+}
+
+// Distance returns the great-circle distance in kilometres between two points, using
+// the haversine formula.
+func Distance(lat1, lon1, lat2, lon2 float64) float64 {
+	dLat := radians(lat2 - lat1)
+	dLon := radians(lon2 - lon1)
+
+	a := math.Pow(math.Sin(dLat/2), 2) + math.Cos(radians(lat1))*math.Cos(radians(lat2))*math.Pow(math.Sin(dLon/2), 2)
+
+	return 2 * EarthRadiusKm * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// BoundingBox returns the latitude and longitude ranges that contain every point within
+// radiusKm of the centre. It's used to narrow the candidates with an index before the
+// exact distance is computed. Near the poles, or when the box crosses the antimeridian,
+// the whole longitude range is returned.
+func BoundingBox(lat, lon, radiusKm float64) (minLat, maxLat, minLon, maxLon float64) {
+	dLat := degrees(radiusKm / EarthRadiusKm)
+
+	minLat = math.Max(-90, lat-dLat)
+	maxLat = math.Min(90, lat+dLat)
+
+	if minLat == -90 || maxLat == 90 {
+		return minLat, maxLat, -180, 180
+	}
+
+	dLon := degrees(math.Asin(math.Min(1, math.Sin(radiusKm/EarthRadiusKm)/math.Cos(radians(lat)))))
+
+	minLon = lon - dLon
+	maxLon = lon + dLon
+
+	if minLon < -180 || maxLon > 180 {
+		return minLat, maxLat, -180, 180
+	}
+
+	return minLat, maxLat, minLon, maxLon
+}
+
+func radians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
+
+func degrees(rad float64) float64 {
+	return rad * 180 / math.Pi
+}
@@ -0,0 +1,177 @@
+// Package geo holds the ISO 3166 reference of countries and subdivisions used to
+// validate and normalize locations. The reference is embedded in the binary, so it can
+// be used without a database round trip; cmd/seed-locations loads the same data into
+// the countries and subdivisions tables.
+//
+// Subdivisions are only listed for some countries. States of the other countries are
+// accepted as free text. The gazetteer of cities, used to resolve coordinates, only
+// covers the main cities.
+package geo
+
+import (
+	"embed"
+	"encoding/csv"
+	"sort"
+	"strings"
+)
+
+//go:embed data/*.csv
+var data embed.FS
+
+type Country struct {
+	Code string
+	Name string
+}
+
+type Subdivision struct {
+	Code        string
+	CountryCode string
+	Name        string
+}
+
+var (
+	countries        []Country
+	countriesByCode  = make(map[string]Country)
+	countriesByName  = make(map[string]Country)
+	subdivisions     = make(map[string][]Subdivision)
+	subdivisionsByID = make(map[string]Subdivision)
+)
+
+func init() {
+	for _, record := range readCSV("data/countries.csv") {
+		c := Country{Code: record[0], Name: record[1]}
+
+		countries = append(countries, c)
+		countriesByCode[c.Code] = c
+		countriesByName[Fold(c.Name)] = c
+	}
+
+	for _, record := range readCSV("data/subdivisions.csv") {
+		s := Subdivision{Code: record[0], CountryCode: record[1], Name: record[2]}
+
+		subdivisions[s.CountryCode] = append(subdivisions[s.CountryCode], s)
+		subdivisionsByID[s.Code] = s
+	}
+
+	for _, list := range subdivisions {
+		sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	}
+}
+
+// readCSV returns the records of an embedded file without its header. The files are
+// part of the binary, so a malformed one is a programming error.
+func readCSV(name string) [][]string {
+	f, err := data.Open(name)
+	if err != nil {
+		panic(err)
+	}
+
+	defer f.Close()
+
+	records, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		panic(err)
+	}
+
+	return records[1:]
+}
+
+// Countries returns every country, sorted by code.
+func Countries() []Country {
+	return countries
+}
+
+// Subdivisions returns the subdivisions of the country sorted by name, nil when they
+// are not part of the reference.
+func Subdivisions(countryCode string) []Subdivision {
+	return subdivisions[countryCode]
+}
+
+// LookupCountry returns the country by its code.
+func LookupCountry(code string) (Country, bool) {
+	c, ok := countriesByCode[code]
+	return c, ok
+}
+
+// LookupSubdivision returns the subdivision by its code, for example AR-B.
+func LookupSubdivision(code string) (Subdivision, bool) {
+	s, ok := subdivisionsByID[code]
+	return s, ok
+}
+
+// ValidCountry returns true if the value is an ISO 3166-1 alpha-2 code.
+func ValidCountry(code string) bool {
+	_, ok := countriesByCode[code]
+	return ok
+}
+
+// HasSubdivisions reports whether the states of the country must be one of its ISO
+// 3166-2 subdivisions.
+func HasSubdivisions(countryCode string) bool {
+	return len(subdivisions[countryCode]) > 0
+}
+
+// ValidSubdivision returns true if the value is an ISO 3166-2 code of the country.
+func ValidSubdivision(countryCode string, code string) bool {
+	s, ok := subdivisionsByID[code]
+	return ok && s.CountryCode == countryCode
+}
+
+// NormalizeCountry returns the alpha-2 code of the country given either its code or its
+// name, ignoring case and accents. Unknown values are returned trimmed.
+func NormalizeCountry(value string) string {
+	value = strings.TrimSpace(value)
+
+	if c, ok := countriesByCode[strings.ToUpper(value)]; ok {
+		return c.Code
+	}
+
+	if c, ok := countriesByName[Fold(value)]; ok {
+		return c.Code
+	}
+
+	return value
+}
+
+// NormalizeSubdivision returns the ISO 3166-2 code of the state given either its code,
+// with or without the country prefix, or its name. States of countries without
+// subdivisions in the reference, and unknown values, are returned trimmed.
+func NormalizeSubdivision(countryCode string, value string) string {
+	value = strings.TrimSpace(value)
+
+	if !HasSubdivisions(countryCode) {
+		return value
+	}
+
+	code := strings.ToUpper(value)
+	if !strings.HasPrefix(code, countryCode+"-") {
+		code = countryCode + "-" + code
+	}
+
+	if ValidSubdivision(countryCode, code) {
+		return code
+	}
+
+	folded := Fold(value)
+	for _, s := range subdivisions[countryCode] {
+		if Fold(s.Name) == folded {
+			return s.Code
+		}
+	}
+
+	return value
+}
+
+var accents = strings.NewReplacer(
+	"á", "a", "à", "a", "â", "a", "ã", "a", "ä", "a", "å", "a",
+	"é", "e", "è", "e", "ê", "e", "ë", "e",
+	"í", "i", "ì", "i", "î", "i", "ï", "i",
+	"ó", "o", "ò", "o", "ô", "o", "õ", "o", "ö", "o",
+	"ú", "u", "ù", "u", "û", "u", "ü", "u",
+	"ñ", "n", "ç", "c",
+)
+
+// Fold lower-cases the value and removes the accents, so "Córdoba" matches "cordoba".
+func Fold(value string) string {
+	return accents.Replace(strings.ToLower(strings.TrimSpace(value)))
+}
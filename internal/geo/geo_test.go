@@ -0,0 +1,81 @@
+package geo
+
+import "testing"
+
+func TestNormalizeCountry(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{name: "Code", value: "AR", want: "AR"},
+		{name: "Lower-cased code", value: "ar", want: "AR"},
+		{name: "Name", value: "Argentina", want: "AR"},
+		{name: "Name in another case", value: "united states", want: "US"},
+		{name: "Name with accents", value: "Cote d'Ivoire", want: "CI"},
+		{name: "Surrounding spaces", value: "  Spain ", want: "ES"},
+		{name: "Unknown value", value: " Atlantis ", want: "Atlantis"},
+		{name: "Empty value", value: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NormalizeCountry(tt.value)
+
+			if got != tt.want {
+				t.Errorf("NormalizeCountry(%q) = %q; want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeSubdivision(t *testing.T) {
+	tests := []struct {
+		name    string
+		country string
+		value   string
+		want    string
+	}{
+		{name: "Code", country: "AR", value: "AR-X", want: "AR-X"},
+		{name: "Code without the country prefix", country: "AR", value: "x", want: "AR-X"},
+		{name: "Name", country: "US", value: "California", want: "US-CA"},
+		{name: "Name without accents", country: "AR", value: "cordoba", want: "AR-X"},
+		{name: "Name with accents", country: "AR", value: "Tucumán", want: "AR-T"},
+		{name: "Code of another country", country: "AR", value: "US-CA", want: "US-CA"},
+		{name: "Unknown state", country: "AR", value: " Springfield ", want: "Springfield"},
+		{name: "Country without subdivisions", country: "ES", value: " Madrid ", want: "Madrid"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NormalizeSubdivision(tt.country, tt.value)
+
+			if got != tt.want {
+				t.Errorf("NormalizeSubdivision(%q, %q) = %q; want %q", tt.country, tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidSubdivision(t *testing.T) {
+	tests := []struct {
+		name    string
+		country string
+		code    string
+		want    bool
+	}{
+		{name: "Subdivision of the country", country: "US", code: "US-NY", want: true},
+		{name: "Subdivision of another country", country: "AR", code: "US-NY", want: false},
+		{name: "Unknown code", country: "AR", code: "AR-ZZ", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ValidSubdivision(tt.country, tt.code)
+
+			if got != tt.want {
+				t.Errorf("ValidSubdivision(%q, %q) = %t; want %t", tt.country, tt.code, got, tt.want)
+			}
+		})
+	}
+}
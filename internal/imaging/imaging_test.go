@@ -0,0 +1,201 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+var (
+	red  = color.NRGBA{R: 255, A: 255}
+	blue = color.NRGBA{B: 255, A: 255}
+)
+
+// halves returns a w x h image, red on its left half and blue on its right half.
+func halves(w, h int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if x < w/2 {
+				img.Set(x, y, red)
+			} else {
+				img.Set(x, y, blue)
+			}
+		}
+	}
+
+	return img
+}
+
+// exifSegment returns an APP1 segment holding an EXIF IFD with the orientation tag.
+func exifSegment(order binary.ByteOrder, orientation uint16) []byte {
+	tiff := make([]byte, 8+2+12+4)
+	if order == binary.LittleEndian {
+		copy(tiff, "II")
+	} else {
+		copy(tiff, "MM")
+	}
+	order.PutUint16(tiff[2:], 42)
+	order.PutUint32(tiff[4:], 8)
+	order.PutUint16(tiff[8:], 1)
+	order.PutUint16(tiff[10:], 0x0112)
+	order.PutUint16(tiff[12:], 3)
+	order.PutUint32(tiff[14:], 1)
+	order.PutUint16(tiff[18:], orientation)
+
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+
+	segment := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(payload)+2))
+
+	return append(segment, payload...)
+}
+
+// encodeJPEG encodes the image, with the given segment right after the start of image
+// marker.
+func encodeJPEG(t *testing.T, img image.Image, segment []byte) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 100}); err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+
+	data := buf.Bytes()
+
+	return append(append(append([]byte{}, data[:2]...), segment...), data[2:]...)
+}
+
+func TestJPEGOrientation(t *testing.T) {
+	img := halves(4, 2)
+
+	tests := []struct {
+		name string
+		data []byte
+		want int
+	}{
+		{name: "No EXIF", data: encodeJPEG(t, img, nil), want: 1},
+		{name: "Little endian", data: encodeJPEG(t, img, exifSegment(binary.LittleEndian, 6)), want: 6},
+		{name: "Big endian", data: encodeJPEG(t, img, exifSegment(binary.BigEndian, 3)), want: 3},
+		{name: "Out of range", data: encodeJPEG(t, img, exifSegment(binary.LittleEndian, 9)), want: 1},
+		{name: "Truncated", data: encodeJPEG(t, img, exifSegment(binary.LittleEndian, 6))[:12], want: 1},
+		{name: "Not a JPEG", data: []byte("GIF89a"), want: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := jpegOrientation(tt.data); got != tt.want {
+				t.Errorf("got orientation %d; want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOrient(t *testing.T) {
+	// The red half is on the left of the 2 x 1 source.
+	src := halves(2, 1)
+
+	tests := []struct {
+		orientation int
+		bounds      image.Rectangle
+		redAt       image.Point
+	}{
+		{orientation: 1, bounds: image.Rect(0, 0, 2, 1), redAt: image.Pt(0, 0)},
+		{orientation: 2, bounds: image.Rect(0, 0, 2, 1), redAt: image.Pt(1, 0)},
+		{orientation: 3, bounds: image.Rect(0, 0, 2, 1), redAt: image.Pt(1, 0)},
+		{orientation: 4, bounds: image.Rect(0, 0, 2, 1), redAt: image.Pt(0, 0)},
+		{orientation: 5, bounds: image.Rect(0, 0, 1, 2), redAt: image.Pt(0, 0)},
+		{orientation: 6, bounds: image.Rect(0, 0, 1, 2), redAt: image.Pt(0, 0)},
+		{orientation: 7, bounds: image.Rect(0, 0, 1, 2), redAt: image.Pt(0, 1)},
+		{orientation: 8, bounds: image.Rect(0, 0, 1, 2), redAt: image.Pt(0, 1)},
+	}
+
+	for _, tt := range tests {
+		got := orient(src, tt.orientation)
+
+		if got.Bounds() != tt.bounds {
+			t.Errorf("orientation %d: got bounds %v; want %v", tt.orientation, got.Bounds(), tt.bounds)
+			continue
+		}
+
+		if c := color.NRGBAModel.Convert(got.At(tt.redAt.X, tt.redAt.Y)); c != red {
+			t.Errorf("orientation %d: got %v at %v; want red", tt.orientation, c, tt.redAt)
+		}
+	}
+}
+
+func TestDecodeAppliesOrientation(t *testing.T) {
+	data := encodeJPEG(t, halves(16, 8), exifSegment(binary.BigEndian, 6))
+
+	img, format, err := Decode(data)
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+
+	if format != FormatJPEG {
+		t.Errorf("got format %q; want %q", format, FormatJPEG)
+	}
+
+	if got, want := img.Bounds(), image.Rect(0, 0, 8, 16); got != want {
+		t.Fatalf("got bounds %v; want %v", got, want)
+	}
+
+	// Rotated clockwise, the red half of the source is now on top.
+	r, _, b, _ := img.At(4, 2).RGBA()
+	if r < b {
+		t.Errorf("got a blue pixel on top; want red")
+	}
+}
+
+// pngWithSize returns a PNG whose header claims the given dimensions, with a valid
+// checksum, while holding the pixels of a 1 x 1 image.
+func pngWithSize(t *testing.T, w, h uint32) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewNRGBA(image.Rect(0, 0, 1, 1))); err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+
+	data := buf.Bytes()
+
+	// The IHDR chunk follows the 8 bytes signature: length, type, data and CRC.
+	ihdr := data[8+8 : 8+8+13]
+	binary.BigEndian.PutUint32(ihdr[0:], w)
+	binary.BigEndian.PutUint32(ihdr[4:], h)
+	binary.BigEndian.PutUint32(data[8+8+13:], crc32.ChecksumIEEE(data[8+4:8+8+13]))
+
+	return data
+}
+
+func TestDecodeLimits(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		err  error
+	}{
+		{name: "Too many pixels", data: pngWithSize(t, 10_000, 10_000), err: ErrTooLarge},
+		{name: "Just over the limit", data: pngWithSize(t, 1, MaxPixels+1), err: ErrTooLarge},
+		{name: "Not an image", data: []byte("%PDF-1.4"), err: ErrUnsupportedFormat},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := Decode(tt.data)
+
+			if !errors.Is(err, tt.err) {
+				t.Errorf("got error %v; want %v", err, tt.err)
+			}
+		})
+	}
+
+	if _, _, err := Decode(pngWithSize(t, 1, 1)); err != nil {
+		t.Errorf("got error %v decoding a 1 x 1 image", err)
+	}
+}
@@ -0,0 +1,142 @@
+package mailer
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"html/template"
+	"mime"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"itfinder.adrianescat.com/internal/jsonlog"
+)
+
+// templateFS holds the email templates. Each template file defines a "subject", a
+// "plainBody" and an "htmlBody" named template.
+//
+//go:embed "templates"
+var templateFS embed.FS
+
+// Mailer sends the email built from the given template to the recipient.
+type Mailer interface {
+	Send(recipient, templateFile string, data any) error
+}
+
+// Message is the rendered content of an email template.
+type Message struct {
+	Subject   string
+	PlainBody string
+	HTMLBody  string
+}
+
+// Render executes the subject, plainBody and htmlBody templates of the template file.
+func Render(templateFile string, data any) (*Message, error) {
+	tmpl, err := template.New("email").ParseFS(templateFS, "templates/"+templateFile)
+	if err != nil {
+		return nil, err
+	}
+
+	subject := new(bytes.Buffer)
+	err = tmpl.ExecuteTemplate(subject, "subject", data)
+	if err != nil {
+		return nil, err
+	}
+
+	plainBody := new(bytes.Buffer)
+	err = tmpl.ExecuteTemplate(plainBody, "plainBody", data)
+	if err != nil {
+		return nil, err
+	}
+
+	htmlBody := new(bytes.Buffer)
+	err = tmpl.ExecuteTemplate(htmlBody, "htmlBody", data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Message{
+		Subject:   strings.TrimSpace(subject.String()),
+		PlainBody: plainBody.String(),
+		HTMLBody:  htmlBody.String(),
+	}, nil
+}
+
+// SMTPMailer sends the emails through an SMTP server using PLAIN authentication.
+type SMTPMailer struct {
+	addr   string
+	auth   smtp.Auth
+	sender string
+}
+
+func NewSMTPMailer(host string, port int, username, password, sender string) *SMTPMailer {
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+
+	return &SMTPMailer{
+		addr:   fmt.Sprintf("%s:%d", host, port),
+		auth:   auth,
+		sender: sender,
+	}
+}
+
+func (m *SMTPMailer) Send(recipient, templateFile string, data any) error {
+	msg, err := Render(templateFile, data)
+	if err != nil {
+		return err
+	}
+
+	boundary := fmt.Sprintf("itfinder-%d", time.Now().UnixNano())
+
+	body := new(bytes.Buffer)
+	fmt.Fprintf(body, "From: %s\r\n", m.sender)
+	fmt.Fprintf(body, "To: %s\r\n", recipient)
+	fmt.Fprintf(body, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(body, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(body, "Content-Type: multipart/alternative; boundary=%q\r\n\r\n", boundary)
+	fmt.Fprintf(body, "--%s\r\nContent-Type: text/plain; charset=utf-8\r\n\r\n%s\r\n", boundary, msg.PlainBody)
+	fmt.Fprintf(body, "--%s\r\nContent-Type: text/html; charset=utf-8\r\n\r\n%s\r\n", boundary, msg.HTMLBody)
+	fmt.Fprintf(body, "--%s--\r\n", boundary)
+
+	// Try sending the email up to three times before aborting and returning the final
+	// error. We sleep for 500 milliseconds between each attempt.
+	for i := 1; i <= 3; i++ {
+		err = smtp.SendMail(m.addr, m.auth, m.sender, []string{recipient}, body.Bytes())
+		if err == nil {
+			return nil
+		}
+
+		time.Sleep(500 * time.Millisecond)
+	}
+
+	return err
+}
+
+// LogMailer logs the emails instead of sending them. It's used when no SMTP server is
+// configured, for example in development. Only the recipient and the template are
+// logged, the bodies carry tokens that must not end up in the logs.
+type LogMailer struct {
+	logger *jsonlog.Logger
+}
+
+func NewLogMailer(logger *jsonlog.Logger) *LogMailer {
+	return &LogMailer{logger: logger}
+}
+
+func (m *LogMailer) Send(recipient, templateFile string, data any) error {
+	// Render anyway, so broken templates fail as they would with the SMTP mailer.
+	_, err := Render(templateFile, data)
+	if err != nil {
+		return err
+	}
+
+	m.logger.PrintInfo("email", map[string]string{
+		"recipient": recipient,
+		"template":  templateFile,
+	})
+
+	return nil
+}
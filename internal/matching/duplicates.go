@@ -0,0 +1,148 @@
+package matching
+
+import (
+	"hash/fnv"
+	"sort"
+	"strings"
+	"unicode"
+
+	"itfinder.adrianescat.com/internal/geo"
+)
+
+// ShingleSize is the number of consecutive words of the description shingles.
+const ShingleSize = 3
+
+// TitleSimilarity compares the normalized titles, from 0 to 1, with the Levenshtein
+// distance. The titles are lower-cased, without accents nor punctuation, and their words
+// sorted, so "Senior Go Developer" and "Go developer (senior)" are the same title.
+func TitleSimilarity(a, b string) float64 {
+	na, nb := []rune(NormalizeTitle(a)), []rune(NormalizeTitle(b))
+
+	longest := len(na)
+	if len(nb) > longest {
+		longest = len(nb)
+	}
+
+	if longest == 0 {
+		return 1
+	}
+
+	return 1 - float64(levenshtein(na, nb))/float64(longest)
+}
+
+// NormalizeTitle returns the sorted words of the title, folded and without stop words.
+func NormalizeTitle(title string) string {
+	var kept []string
+
+	for _, word := range words(title) {
+		if !stopWords[word] {
+			kept = append(kept, word)
+		}
+	}
+
+	sort.Strings(kept)
+
+	return strings.Join(kept, " ")
+}
+
+// DescriptionSimilarity is the Jaccard similarity of the description shingles.
+func DescriptionSimilarity(a, b string) float64 {
+	return Jaccard(Shingles(a, ShingleSize), Shingles(b, ShingleSize))
+}
+
+// Shingles returns the hashes of every run of size consecutive words of the text. Texts
+// shorter than size are a single shingle.
+func Shingles(text string, size int) map[uint64]bool {
+	w := words(text)
+	shingles := make(map[uint64]bool)
+
+	if len(w) == 0 {
+		return shingles
+	}
+
+	if len(w) < size {
+		size = len(w)
+	}
+
+	for i := 0; i+size <= len(w); i++ {
+		h := fnv.New64a()
+		h.Write([]byte(strings.Join(w[i:i+size], " ")))
+		shingles[h.Sum64()] = true
+	}
+
+	return shingles
+}
+
+// Jaccard is the size of the intersection of the sets over the size of their union. Two
+// empty sets are the same.
+func Jaccard(a, b map[uint64]bool) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 1
+	}
+
+	var shared int
+	for shingle := range a {
+		if b[shingle] {
+			shared++
+		}
+	}
+
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
+
+// words returns the folded words of the text in order, keeping the characters Keywords
+// keeps.
+func words(text string) []string {
+	fields := strings.FieldsFunc(geo.Fold(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '+' && r != '#' && r != '.'
+	})
+
+	w := make([]string, 0, len(fields))
+
+	for _, field := range fields {
+		field = strings.Trim(field, ".")
+		if field != "" {
+			w = append(w, field)
+		}
+	}
+
+	return w
+}
+
+func levenshtein(a, b []rune) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			current[j] = minInt(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+
+		previous, current = current, previous
+	}
+
+	return previous[len(b)]
+}
+
+func minInt(values ...int) int {
+	m := values[0]
+
+	for _, v := range values[1:] {
+		if v < m {
+			m = v
+		}
+	}
+
+	return m
+}
@@ -0,0 +1,106 @@
+package matching
+
+import (
+	"math"
+	"testing"
+)
+
+func TestTitleSimilarity(t *testing.T) {
+	tests := []struct {
+		name string
+		a    string
+		b    string
+		want float64
+	}{
+		{name: "Same title", a: "Go Developer", b: "Go Developer", want: 1},
+		{name: "Reordered words and punctuation", a: "Senior Go Developer", b: "Go developer (senior)", want: 1},
+		{name: "Accents", a: "Développeur Go", b: "developpeur go", want: 1},
+		{name: "Stop words", a: "The Go Developer", b: "Go Developer", want: 1},
+		{name: "One edit", a: "Go Developer", b: "Go Developers", want: 1 - 1.0/13},
+		{name: "Empty titles", a: "", b: "", want: 1},
+		{name: "One empty title", a: "Go Developer", b: "", want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := TitleSimilarity(tt.a, tt.b)
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("got %v; want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestShingles(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		size int
+		want int
+	}{
+		{name: "Empty text", text: "", size: 3, want: 0},
+		{name: "Shorter than the size", text: "Go developer", size: 3, want: 1},
+		{name: "Consecutive words", text: "one two three four", size: 3, want: 2},
+		{name: "Repeated shingles", text: "a b a b a b", size: 2, want: 2},
+		{name: "Kept characters", text: "C++, C# and Node.js.", size: 3, want: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Shingles(tt.text, tt.size)
+			if len(got) != tt.want {
+				t.Errorf("got %d shingles; want %d", len(got), tt.want)
+			}
+		})
+	}
+
+	if !equalSets(Shingles("Build GraphQL APIs in Go", 3), Shingles("build graphql APIs, in go!", 3)) {
+		t.Errorf("got different shingles for the same words")
+	}
+}
+
+func TestJaccard(t *testing.T) {
+	set := func(values ...uint64) map[uint64]bool {
+		s := make(map[uint64]bool)
+		for _, v := range values {
+			s[v] = true
+		}
+		return s
+	}
+
+	tests := []struct {
+		name string
+		a    map[uint64]bool
+		b    map[uint64]bool
+		want float64
+	}{
+		{name: "Empty sets", a: set(), b: set(), want: 1},
+		{name: "One empty set", a: set(1, 2), b: set(), want: 0},
+		{name: "Same sets", a: set(1, 2), b: set(1, 2), want: 1},
+		{name: "Overlapping sets", a: set(1, 2, 3), b: set(2, 3, 4), want: 0.5},
+		{name: "Disjoint sets", a: set(1, 2), b: set(3, 4), want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Jaccard(tt.a, tt.b)
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("got %v; want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func equalSets(a, b map[uint64]bool) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for v := range a {
+		if !b[v] {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,258 @@
+package matching
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+// Weights of every component of the score. Components that can't be evaluated, for
+// example the salary when the offer and the candidate don't share a currency, are left
+// out and the remaining weights are scaled up accordingly.
+const (
+	TitleWeight    = 0.4
+	SalaryWeight   = 0.3
+	LocationWeight = 0.15
+	StatusWeight   = 0.15
+)
+
+// Salary is a salary range for a role title in a given currency.
+type Salary struct {
+	Title    string
+	Min      float64
+	Max      float64
+	Currency string
+}
+
+// Offer holds the offer data taken into account by the score.
+type Offer struct {
+	Title    string
+	Salaries []Salary
+	Country  string
+	State    string
+	City     string
+}
+
+// Candidate holds the profile data taken into account by the score.
+type Candidate struct {
+	Title    string
+	Status   string
+	Salaries []Salary
+	Country  string
+	State    string
+	City     string
+}
+
+// Result is the total score, from 0 to 100, together with the components it was
+// computed from. A nil component couldn't be evaluated.
+type Result struct {
+	Total    int
+	Title    *float64
+	Salary   *float64
+	Location *float64
+	Status   *float64
+}
+
+// stopWords are ignored when comparing titles.
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "the": true, "of": true, "for": true, "to": true,
+	"in": true, "with": true, "or": true, "de": true, "en": true, "y": true, "con": true,
+}
+
+// Score computes how well the candidate matches the offer. It's deterministic: the same
+// offer and candidate always get the same score.
+func Score(offer Offer, candidate Candidate) Result {
+	result := Result{
+		Title:    TitleScore(offer, candidate),
+		Salary:   SalaryScore(offer.Salaries, candidate.Salaries),
+		Location: LocationScore(offer, candidate),
+		Status:   StatusScore(candidate.Status),
+	}
+
+	var total, weights float64
+
+	for _, c := range []struct {
+		score  *float64
+		weight float64
+	}{
+		{result.Title, TitleWeight},
+		{result.Salary, SalaryWeight},
+		{result.Location, LocationWeight},
+		{result.Status, StatusWeight},
+	} {
+		if c.score == nil {
+			continue
+		}
+
+		total += *c.score * c.weight
+		weights += c.weight
+	}
+
+	if weights > 0 {
+		result.Total = int(math.Round(100 * total / weights))
+	}
+
+	return result
+}
+
+// TitleScore is the fraction of the offer keywords, taken from its title and the role
+// titles of its salaries, that are found in the candidate title and role titles.
+func TitleScore(offer Offer, candidate Candidate) *float64 {
+	offerKeywords := Keywords(offer.Title)
+	for _, s := range offer.Salaries {
+		addKeywords(offerKeywords, s.Title)
+	}
+
+	if len(offerKeywords) == 0 {
+		return nil
+	}
+
+	candidateKeywords := Keywords(candidate.Title)
+	for _, s := range candidate.Salaries {
+		addKeywords(candidateKeywords, s.Title)
+	}
+
+	var found int
+	for keyword := range offerKeywords {
+		if candidateKeywords[keyword] {
+			found++
+		}
+	}
+
+	score := float64(found) / float64(len(offerKeywords))
+	return &score
+}
+
+// SalaryScore compares the salary ranges of the offer and the candidate for the same
+// role and currency, and returns the best score among them. Ranges that overlap score
+// between 0.5 and 1 depending on how much they overlap, ranges that don't overlap get
+// at most 0.5 depending on the gap between them.
+func SalaryScore(offer []Salary, candidate []Salary) *float64 {
+	var best *float64
+
+	for _, o := range offer {
+		for _, c := range candidate {
+			if !strings.EqualFold(strings.TrimSpace(o.Currency), strings.TrimSpace(c.Currency)) {
+				continue
+			}
+
+			if !sameRole(o.Title, c.Title) {
+				continue
+			}
+
+			score := RangeScore(o.Min, o.Max, c.Min, c.Max)
+			if best == nil || score > *best {
+				best = &score
+			}
+		}
+	}
+
+	return best
+}
+
+// RangeScore scores the overlap between the offered and the expected salary ranges.
+func RangeScore(offerMin, offerMax, candidateMin, candidateMax float64) float64 {
+	lo := math.Max(offerMin, candidateMin)
+	hi := math.Min(offerMax, candidateMax)
+
+	if hi >= lo {
+		span := math.Min(offerMax-offerMin, candidateMax-candidateMin)
+		if span <= 0 {
+			return 1
+		}
+
+		return 0.5 + 0.5*math.Min(1, (hi-lo)/span)
+	}
+
+	if lo <= 0 {
+		return 0
+	}
+
+	return math.Max(0, 0.5*(1-(lo-hi)/lo))
+}
+
+// LocationScore gives half of the score for the same country, and a quarter for each of
+// the state and the city.
+func LocationScore(offer Offer, candidate Candidate) *float64 {
+	if offer.Country == "" || candidate.Country == "" {
+		return nil
+	}
+
+	var score float64
+
+	if sameText(offer.Country, candidate.Country) {
+		score += 0.5
+
+		if offer.State == "" || sameText(offer.State, candidate.State) {
+			score += 0.25
+
+			if offer.City == "" || sameText(offer.City, candidate.City) {
+				score += 0.25
+			}
+		}
+	}
+
+	return &score
+}
+
+// StatusScore favours candidates open to new opportunities.
+func StatusScore(status string) *float64 {
+	var score float64
+
+	switch strings.ToLower(status) {
+	case "open":
+		score = 1
+	case "idle":
+		score = 0.5
+	default:
+		score = 0
+	}
+
+	return &score
+}
+
+// Keywords returns the set of lower-cased words of the text, ignoring stop words.
+// Characters such as '+', '#' and '.' are kept so "C++", "C#" and "Node.js" survive.
+func Keywords(text string) map[string]bool {
+	keywords := make(map[string]bool)
+	addKeywords(keywords, text)
+	return keywords
+}
+
+func addKeywords(keywords map[string]bool, text string) {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '+' && r != '#' && r != '.'
+	})
+
+	for _, word := range words {
+		word = strings.Trim(word, ".")
+		if word == "" || stopWords[word] {
+			continue
+		}
+
+		keywords[word] = true
+	}
+}
+
+// sameRole reports whether both role titles share at least one keyword. An empty title
+// matches any role.
+func sameRole(a, b string) bool {
+	aKeywords := Keywords(a)
+	bKeywords := Keywords(b)
+
+	if len(aKeywords) == 0 || len(bKeywords) == 0 {
+		return true
+	}
+
+	for keyword := range aKeywords {
+		if bKeywords[keyword] {
+			return true
+		}
+	}
+
+	return false
+}
+
+func sameText(a, b string) bool {
+	return strings.EqualFold(strings.TrimSpace(a), strings.TrimSpace(b))
+}
@@ -0,0 +1,251 @@
+package matching
+
+import (
+	"math"
+	"testing"
+)
+
+func TestScore(t *testing.T) {
+	goOffer := Offer{
+		Title:    "Senior Go Developer",
+		Salaries: []Salary{{Title: "Go Developer", Min: 3000, Max: 4000, Currency: "USD"}},
+		Country:  "Argentina",
+		State:    "Buenos Aires",
+		City:     "La Plata",
+	}
+
+	tests := []struct {
+		name      string
+		offer     Offer
+		candidate Candidate
+		want      int
+	}{
+		{
+			name:  "Perfect match",
+			offer: goOffer,
+			candidate: Candidate{
+				Title:    "Senior Go Developer",
+				Status:   "open",
+				Salaries: []Salary{{Title: "Go Developer", Min: 3000, Max: 4000, Currency: "USD"}},
+				Country:  "argentina",
+				State:    "buenos aires",
+				City:     "la plata",
+			},
+			want: 100,
+		},
+		{
+			name:  "No match",
+			offer: goOffer,
+			candidate: Candidate{
+				Title:    "Graphic Designer",
+				Status:   "closed",
+				Salaries: []Salary{{Title: "Designer", Min: 1000, Max: 2000, Currency: "USD"}},
+				Country:  "Chile",
+			},
+			want: 0,
+		},
+		{
+			name:  "Salary left out without a shared currency",
+			offer: goOffer,
+			candidate: Candidate{
+				Title:    "Senior Go Developer",
+				Status:   "idle",
+				Salaries: []Salary{{Title: "Go Developer", Min: 3000, Max: 4000, Currency: "EUR"}},
+				Country:  "Argentina",
+				State:    "Buenos Aires",
+				City:     "La Plata",
+			},
+			// (0.4 + 0.15 + 0.15*0.5) / 0.7
+			want: 89,
+		},
+		{
+			name:      "Only the status can be evaluated",
+			offer:     Offer{},
+			candidate: Candidate{Status: "open"},
+			want:      100,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Score(tt.offer, tt.candidate)
+
+			if got.Total != tt.want {
+				t.Errorf("got total %d; want %d", got.Total, tt.want)
+			}
+		})
+	}
+}
+
+func TestScoreIsDeterministic(t *testing.T) {
+	offer := Offer{Title: "Backend Engineer Go Kubernetes", Country: "Spain"}
+	candidate := Candidate{Title: "Go Engineer", Status: "open", Country: "Spain", State: "Madrid"}
+
+	first := Score(offer, candidate)
+
+	for i := 0; i < 10; i++ {
+		if got := Score(offer, candidate); got.Total != first.Total {
+			t.Fatalf("got total %d; want %d", got.Total, first.Total)
+		}
+	}
+}
+
+func TestTitleScore(t *testing.T) {
+	tests := []struct {
+		name      string
+		offer     Offer
+		candidate Candidate
+		want      *float64
+	}{
+		{
+			name:      "No offer keywords",
+			offer:     Offer{Title: "the and of"},
+			candidate: Candidate{Title: "Go Developer"},
+			want:      nil,
+		},
+		{
+			name:      "Half the keywords",
+			offer:     Offer{Title: "Go Developer"},
+			candidate: Candidate{Title: "Go Engineer"},
+			want:      float(0.5),
+		},
+		{
+			name:      "Keywords of the salary titles",
+			offer:     Offer{Title: "Engineer", Salaries: []Salary{{Title: "C++"}}},
+			candidate: Candidate{Title: "Developer", Salaries: []Salary{{Title: "c++ engineer"}}},
+			want:      float(1),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertScore(t, TitleScore(tt.offer, tt.candidate), tt.want)
+		})
+	}
+}
+
+func TestSalaryScore(t *testing.T) {
+	tests := []struct {
+		name      string
+		offer     []Salary
+		candidate []Salary
+		want      *float64
+	}{
+		{
+			name:      "No salaries",
+			offer:     nil,
+			candidate: []Salary{{Min: 1000, Max: 2000, Currency: "USD"}},
+			want:      nil,
+		},
+		{
+			name:      "Different currencies",
+			offer:     []Salary{{Min: 1000, Max: 2000, Currency: "USD"}},
+			candidate: []Salary{{Min: 1000, Max: 2000, Currency: "EUR"}},
+			want:      nil,
+		},
+		{
+			name:      "Different roles",
+			offer:     []Salary{{Title: "Go Developer", Min: 1000, Max: 2000, Currency: "USD"}},
+			candidate: []Salary{{Title: "Designer", Min: 1000, Max: 2000, Currency: "USD"}},
+			want:      nil,
+		},
+		{
+			name:      "Best of the matching ranges",
+			offer:     []Salary{{Min: 1000, Max: 2000, Currency: "usd"}},
+			candidate: []Salary{{Min: 5000, Max: 6000, Currency: "USD"}, {Min: 1000, Max: 2000, Currency: " USD "}},
+			want:      float(1),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertScore(t, SalaryScore(tt.offer, tt.candidate), tt.want)
+		})
+	}
+}
+
+func TestRangeScore(t *testing.T) {
+	tests := []struct {
+		name                                                 string
+		offerMin, offerMax, candidateMin, candidateMax, want float64
+	}{
+		{"Same range", 1000, 2000, 1000, 2000, 1},
+		{"Half overlap", 1000, 2000, 1500, 2500, 0.75},
+		{"Contained range", 1000, 3000, 1500, 2000, 1},
+		{"Touching ranges", 1000, 2000, 2000, 3000, 0.5},
+		{"Fixed salaries", 2000, 2000, 2000, 2000, 1},
+		{"Small gap", 1000, 2000, 2500, 3000, 0.4},
+		{"Large gap", 100, 200, 5000, 6000, 0.02},
+		{"Gap without a minimum", 0, 0, 0, 0, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := RangeScore(tt.offerMin, tt.offerMax, tt.candidateMin, tt.candidateMax)
+
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("got %v; want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLocationScore(t *testing.T) {
+	offer := Offer{Country: "Argentina", State: "Córdoba", City: "Córdoba"}
+
+	tests := []struct {
+		name      string
+		offer     Offer
+		candidate Candidate
+		want      *float64
+	}{
+		{"No offer country", Offer{}, Candidate{Country: "Argentina"}, nil},
+		{"No candidate country", offer, Candidate{}, nil},
+		{"Other country", offer, Candidate{Country: "Uruguay", State: "Córdoba"}, float(0)},
+		{"Same country", offer, Candidate{Country: "argentina", State: "Salta"}, float(0.5)},
+		{"Same state", offer, Candidate{Country: "Argentina", State: "Córdoba", City: "Villa María"}, float(0.75)},
+		{"Same city", offer, Candidate{Country: "Argentina", State: "córdoba", City: "CÓRDOBA"}, float(1)},
+		{"Offer in the whole country", Offer{Country: "Argentina"}, Candidate{Country: "Argentina", State: "Salta"}, float(1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertScore(t, LocationScore(tt.offer, tt.candidate), tt.want)
+		})
+	}
+}
+
+func TestStatusScore(t *testing.T) {
+	tests := []struct {
+		status string
+		want   float64
+	}{
+		{"open", 1},
+		{"OPEN", 1},
+		{"idle", 0.5},
+		{"closed", 0},
+		{"", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			assertScore(t, StatusScore(tt.status), float(tt.want))
+		})
+	}
+}
+
+func float(f float64) *float64 {
+	return &f
+}
+
+func assertScore(t *testing.T, got, want *float64) {
+	t.Helper()
+
+	switch {
+	case got == nil && want == nil:
+	case got == nil || want == nil:
+		t.Errorf("got %v; want %v", got, want)
+	case math.Abs(*got-*want) > 1e-9:
+		t.Errorf("got %v; want %v", *got, *want)
+	}
+}
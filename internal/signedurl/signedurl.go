@@ -0,0 +1,75 @@
+package signedurl
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+var (
+	ErrInvalidSignature = errors.New("invalid signature")
+	ErrExpired          = errors.New("signed url expired")
+)
+
+// Signer builds and verifies URLs signed with HMAC-SHA256. The signature covers the
+// path, the expiry and the id of the user the URL was issued to, so none of them can
+// be changed without invalidating it.
+type Signer struct {
+	secret []byte
+}
+
+func New(secret []byte) *Signer {
+	return &Signer{secret: secret}
+}
+
+// Sign returns the path with the expires, user and signature query parameters.
+func (s *Signer) Sign(path string, userId int64, expires time.Time) string {
+	exp := strconv.FormatInt(expires.Unix(), 10)
+	user := strconv.FormatInt(userId, 10)
+
+	query := url.Values{
+		"expires":   {exp},
+		"user":      {user},
+		"signature": {s.signature(path, exp, user)},
+	}
+
+	return path + "?" + query.Encode()
+}
+
+// Verify checks the signature of the path and its query, and returns the id of the
+// user the URL was issued to.
+func (s *Signer) Verify(path string, query url.Values, now time.Time) (int64, error) {
+	exp := query.Get("expires")
+	user := query.Get("user")
+
+	expected := s.signature(path, exp, user)
+	if !hmac.Equal([]byte(expected), []byte(query.Get("signature"))) {
+		return 0, ErrInvalidSignature
+	}
+
+	expires, err := strconv.ParseInt(exp, 10, 64)
+	if err != nil {
+		return 0, ErrInvalidSignature
+	}
+
+	if now.Unix() > expires {
+		return 0, ErrExpired
+	}
+
+	userId, err := strconv.ParseInt(user, 10, 64)
+	if err != nil {
+		return 0, ErrInvalidSignature
+	}
+
+	return userId, nil
+}
+
+func (s *Signer) signature(path, expires, user string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(path + "\n" + expires + "\n" + user))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,92 @@
+package signedurl
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	s := New([]byte("secret"))
+	now := time.Unix(1_700_000_000, 0)
+
+	signed := s.Sign("/resumes/7", 42, now.Add(time.Hour))
+
+	path, rawQuery, ok := strings.Cut(signed, "?")
+	if !ok {
+		t.Fatalf("got %q; want a query", signed)
+	}
+
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		t.Fatalf("parse query: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		secret string
+		path   string
+		change func(q url.Values)
+		now    time.Time
+		err    error
+	}{
+		{name: "Valid", secret: "secret", path: path, now: now},
+		{name: "Valid until the expiry", secret: "secret", path: path, now: now.Add(time.Hour)},
+		{name: "Expired", secret: "secret", path: path, now: now.Add(time.Hour + time.Second), err: ErrExpired},
+		{name: "Another path", secret: "secret", path: "/resumes/8", now: now, err: ErrInvalidSignature},
+		{name: "Another secret", secret: "other", path: path, now: now, err: ErrInvalidSignature},
+		{
+			name:   "Another user",
+			secret: "secret",
+			path:   path,
+			change: func(q url.Values) { q.Set("user", "43") },
+			now:    now,
+			err:    ErrInvalidSignature,
+		},
+		{
+			name:   "Extended expiry",
+			secret: "secret",
+			path:   path,
+			change: func(q url.Values) { q.Set("expires", "9999999999") },
+			now:    now.Add(2 * time.Hour),
+			err:    ErrInvalidSignature,
+		},
+		{
+			name:   "Tampered signature",
+			secret: "secret",
+			path:   path,
+			change: func(q url.Values) { q.Set("signature", strings.ToUpper(q.Get("signature"))) },
+			now:    now,
+			err:    ErrInvalidSignature,
+		},
+		{
+			name:   "Missing signature",
+			secret: "secret",
+			path:   path,
+			change: func(q url.Values) { q.Del("signature") },
+			now:    now,
+			err:    ErrInvalidSignature,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, _ := url.ParseQuery(query.Encode())
+			if tt.change != nil {
+				tt.change(q)
+			}
+
+			userId, err := New([]byte(tt.secret)).Verify(tt.path, q, tt.now)
+
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("got error %v; want %v", err, tt.err)
+			}
+
+			if err == nil && userId != 42 {
+				t.Errorf("got user %d; want 42", userId)
+			}
+		})
+	}
+}
@@ -0,0 +1,307 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	testAccessKey = "AKIDEXAMPLE"
+	testSecretKey = "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"
+	testRegion    = "eu-west-1"
+	testBucket    = "itfinder"
+)
+
+var authorizationRX = regexp.MustCompile(`^AWS4-HMAC-SHA256 Credential=([^/]+)/(\d{8})/([^/]+)/s3/aws4_request, SignedHeaders=([a-z0-9;-]+), Signature=([0-9a-f]{64})$`)
+
+// fakeS3 is an in-memory bucket that checks the signature of every request the way S3
+// does, from what it receives.
+type fakeS3 struct {
+	t       *testing.T
+	mu      sync.Mutex
+	objects map[string][]byte
+	types   map[string]string
+}
+
+func newTestS3(t *testing.T) (*S3Storage, *fakeS3) {
+	t.Helper()
+
+	fake := &fakeS3{t: t, objects: make(map[string][]byte), types: make(map[string]string)}
+
+	ts := httptest.NewServer(fake)
+	t.Cleanup(ts.Close)
+
+	s, err := NewS3Storage(ts.URL+"/", testRegion, testBucket, testAccessKey, testSecretKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return s, fake
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !f.validSignature(r) {
+		http.Error(w, "SignatureDoesNotMatch", http.StatusForbidden)
+		return
+	}
+
+	prefix := "/" + testBucket + "/"
+	if !strings.HasPrefix(r.URL.Path, prefix) {
+		http.Error(w, "NoSuchBucket", http.StatusNotFound)
+		return
+	}
+
+	key := strings.TrimPrefix(r.URL.Path, prefix)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch r.Method {
+	case http.MethodPut:
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if int64(len(body)) != r.ContentLength {
+			http.Error(w, "IncompleteBody", http.StatusBadRequest)
+			return
+		}
+
+		f.objects[key] = body
+		f.types[key] = r.Header.Get("Content-Type")
+	case http.MethodHead, http.MethodGet:
+		body, ok := f.objects[key]
+		if !ok {
+			http.Error(w, "NoSuchKey", http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", f.types[key])
+		http.ServeContent(w, r, key, time.Time{}, bytes.NewReader(body))
+	case http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "MethodNotAllowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// validSignature recomputes the Signature Version 4 of the request from the headers it
+// names as signed.
+func (f *fakeS3) validSignature(r *http.Request) bool {
+	m := authorizationRX.FindStringSubmatch(r.Header.Get("Authorization"))
+	if m == nil {
+		f.t.Errorf("malformed Authorization header %q", r.Header.Get("Authorization"))
+		return false
+	}
+
+	accessKey, date, region, signedHeaders, signature := m[1], m[2], m[3], m[4], m[5]
+
+	if accessKey != testAccessKey || region != testRegion {
+		f.t.Errorf("got credential %s for %s; want %s for %s", accessKey, region, testAccessKey, testRegion)
+		return false
+	}
+
+	amzDate := r.Header.Get("X-Amz-Date")
+	if !strings.HasPrefix(amzDate, date+"T") {
+		f.t.Errorf("got X-Amz-Date %q for the scope date %s", amzDate, date)
+		return false
+	}
+
+	if got := r.Header.Get("X-Amz-Content-Sha256"); got != unsignedPayload {
+		f.t.Errorf("got X-Amz-Content-Sha256 %q; want %q", got, unsignedPayload)
+		return false
+	}
+
+	names := strings.Split(signedHeaders, ";")
+	for _, required := range []string{"host", "x-amz-content-sha256", "x-amz-date"} {
+		if !contains(names, required) {
+			f.t.Errorf("header %s isn't signed in %q", required, signedHeaders)
+			return false
+		}
+	}
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		value := r.Header.Get(name)
+		if name == "host" {
+			value = r.Host
+		}
+
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(value) + "\n")
+	}
+
+	canonicalRequest := strings.Join([]string{
+		r.Method,
+		r.URL.EscapedPath(),
+		r.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		unsignedPayload,
+	}, "\n")
+
+	hash := sha256.Sum256([]byte(canonicalRequest))
+
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		date + "/" + region + "/s3/aws4_request",
+		hex.EncodeToString(hash[:]),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+testSecretKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+
+	if want := hex.EncodeToString(hmacSHA256(key, stringToSign)); signature != want {
+		f.t.Errorf("got signature %s; want %s", signature, want)
+		return false
+	}
+
+	return true
+}
+
+func TestS3PutGetDelete(t *testing.T) {
+	s, fake := newTestS3(t)
+	ctx := context.Background()
+
+	key := "resumes/42/cv final.pdf"
+	content := []byte("%PDF-1.4 resume")
+
+	err := s.Put(ctx, key, bytes.NewReader(content), int64(len(content)), "application/pdf")
+	if err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	if got := fake.types[key]; got != "application/pdf" {
+		t.Errorf("got content type %q; want %q", got, "application/pdf")
+	}
+
+	object, err := s.Get(ctx, key)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+
+	got, err := io.ReadAll(object)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+
+	if !bytes.Equal(got, content) {
+		t.Errorf("got %q; want %q", got, content)
+	}
+
+	// Seeking requests the rest of the object with a new ranged GET.
+	_, err = object.Seek(5, io.SeekStart)
+	if err != nil {
+		t.Fatalf("Seek: %v", err)
+	}
+
+	got, err = io.ReadAll(object)
+	if err != nil {
+		t.Fatalf("read after seek: %v", err)
+	}
+
+	if !bytes.Equal(got, content[5:]) {
+		t.Errorf("got %q after seeking; want %q", got, content[5:])
+	}
+
+	object.Close()
+
+	err = s.Delete(ctx, key)
+	if err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	if _, ok := fake.objects[key]; ok {
+		t.Errorf("object %q wasn't deleted", key)
+	}
+}
+
+func TestS3NotFound(t *testing.T) {
+	s, _ := newTestS3(t)
+
+	_, err := s.Get(context.Background(), "resumes/1/missing.pdf")
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("got error %v; want %v", err, ErrNotFound)
+	}
+}
+
+func TestS3Errors(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "AccessDenied", http.StatusForbidden)
+	}))
+	defer ts.Close()
+
+	s, err := NewS3Storage(ts.URL, testRegion, testBucket, testAccessKey, "wrong")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = s.Delete(context.Background(), "resumes/1/cv.pdf")
+	if err == nil || errors.Is(err, ErrNotFound) || !strings.Contains(err.Error(), "AccessDenied") {
+		t.Errorf("got error %v; want the AccessDenied response", err)
+	}
+}
+
+func TestS3InvalidKey(t *testing.T) {
+	s, _ := newTestS3(t)
+
+	for _, key := range []string{"", "/resumes/cv.pdf", "resumes/../cv.pdf", "resumes//cv.pdf"} {
+		err := s.Delete(context.Background(), key)
+		if !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("got error %v for %q; want %v", err, key, ErrInvalidKey)
+		}
+	}
+}
+
+func TestNewS3Storage(t *testing.T) {
+	tests := []struct {
+		name     string
+		endpoint string
+		bucket   string
+		valid    bool
+	}{
+		{"Valid", "http://localhost:9000", "itfinder", true},
+		{"No scheme", "localhost:9000", "itfinder", false},
+		{"No bucket", "http://localhost:9000", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := NewS3Storage(tt.endpoint, "", tt.bucket, testAccessKey, testSecretKey)
+
+			if (err == nil) != tt.valid {
+				t.Fatalf("got error %v; want valid %v", err, tt.valid)
+			}
+
+			if tt.valid && s.Region != "us-east-1" {
+				t.Errorf("got region %q; want the us-east-1 default", s.Region)
+			}
+		})
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -65,9 +65,20 @@ func Unique[T comparable](values []T) bool {
 	return len(values) == len(uniqueValues)
 }
 
+// ValidatePictureUrl checks a picture URL set by hand. It's optional, as the picture
+// can also be uploaded, which sets the URL.
 func ValidatePictureUrl(v *Validator, pictureUrl string) {
-	v.Check(pictureUrl != "", "pictureUrl", "must be provided")
-	v.Check(Matches(pictureUrl, GralURlRX), "pictureUrl", "must be a valid url")
+	ValidateOptionalUrl(v, "pictureUrl", pictureUrl)
+}
+
+// ValidateOptionalUrl checks the url under the given key, when it's provided.
+func ValidateOptionalUrl(v *Validator, key string, url string) {
+	if url == "" {
+		return
+	}
+
+	v.Check(len(url) <= 2048, key, "must not be more than 2048 bytes long")
+	v.Check(Matches(url, GralURlRX), key, "must be a valid url")
 }
 
 func ValidateWebsiteUrl(v *Validator, websiteUrl string) {
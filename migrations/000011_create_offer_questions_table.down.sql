@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS application_answers;
+DROP TABLE IF EXISTS offer_questions;
+ALTER TABLE offers_applicants DROP COLUMN IF EXISTS created_at;
+ALTER TABLE offers_applicants DROP COLUMN IF EXISTS cover_letter;
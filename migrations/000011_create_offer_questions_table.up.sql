@@ -0,0 +1,24 @@
+ALTER TABLE offers_applicants ADD COLUMN IF NOT EXISTS cover_letter text;
+ALTER TABLE offers_applicants ADD COLUMN IF NOT EXISTS created_at timestamp(0) with time zone NOT NULL DEFAULT NOW();
+
+CREATE TABLE IF NOT EXISTS offer_questions (
+    id bigserial PRIMARY KEY,
+    offer_id bigint NOT NULL REFERENCES offers ON DELETE CASCADE,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    question text NOT NULL,
+    kind text NOT NULL CHECK (kind IN ('text', 'yes_no', 'multiple_choice')),
+    options jsonb,
+    required bool NOT NULL DEFAULT false,
+    position integer NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS offer_questions_offer_id_idx ON offer_questions (offer_id);
+
+CREATE TABLE IF NOT EXISTS application_answers (
+    profile_id bigint NOT NULL,
+    offer_id bigint NOT NULL,
+    question_id bigint NOT NULL REFERENCES offer_questions ON DELETE CASCADE,
+    answer text NOT NULL,
+    PRIMARY KEY (profile_id, question_id),
+    FOREIGN KEY (profile_id, offer_id) REFERENCES offers_applicants (profile_id, offer_id) ON DELETE CASCADE
+);
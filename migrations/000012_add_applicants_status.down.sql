@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS offers_applicants_offer_id_idx;
+DROP INDEX IF EXISTS offers_user_id_idx;
+ALTER TABLE offers_applicants DROP COLUMN IF EXISTS updated_at;
+ALTER TABLE offers_applicants DROP COLUMN IF EXISTS status;
@@ -0,0 +1,6 @@
+ALTER TABLE offers_applicants ADD COLUMN IF NOT EXISTS status text NOT NULL DEFAULT 'applied'
+    CHECK (status IN ('applied', 'reviewing', 'interviewing', 'offered', 'hired', 'rejected'));
+ALTER TABLE offers_applicants ADD COLUMN IF NOT EXISTS updated_at timestamp(0) with time zone NOT NULL DEFAULT NOW();
+
+CREATE INDEX IF NOT EXISTS offers_user_id_idx ON offers (user_id);
+CREATE INDEX IF NOT EXISTS offers_applicants_offer_id_idx ON offers_applicants (offer_id);
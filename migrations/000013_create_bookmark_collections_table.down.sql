@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS offer_bookmarks;
+DROP INDEX IF EXISTS profile_bookmarks_collection_id_idx;
+ALTER TABLE profile_bookmarks DROP COLUMN IF EXISTS created_at;
+ALTER TABLE profile_bookmarks DROP COLUMN IF EXISTS note;
+ALTER TABLE profile_bookmarks DROP COLUMN IF EXISTS collection_id;
+DROP TABLE IF EXISTS bookmark_collections;
@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS bookmark_collections (
+    id bigserial PRIMARY KEY,
+    user_id bigint NOT NULL REFERENCES users ON DELETE CASCADE,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    updated_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    name text NOT NULL,
+    version integer NOT NULL DEFAULT 1,
+    UNIQUE (user_id, name)
+);
+
+ALTER TABLE profile_bookmarks ADD COLUMN IF NOT EXISTS collection_id bigint REFERENCES bookmark_collections ON DELETE SET NULL;
+ALTER TABLE profile_bookmarks ADD COLUMN IF NOT EXISTS note text;
+ALTER TABLE profile_bookmarks ADD COLUMN IF NOT EXISTS created_at timestamp(0) with time zone NOT NULL DEFAULT NOW();
+
+CREATE TABLE IF NOT EXISTS offer_bookmarks (
+    user_id bigint NOT NULL REFERENCES users ON DELETE CASCADE,
+    offer_id bigint NOT NULL REFERENCES offers ON DELETE CASCADE,
+    collection_id bigint REFERENCES bookmark_collections ON DELETE SET NULL,
+    note text,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, offer_id)
+);
+
+CREATE INDEX IF NOT EXISTS profile_bookmarks_collection_id_idx ON profile_bookmarks (collection_id);
+CREATE INDEX IF NOT EXISTS offer_bookmarks_collection_id_idx ON offer_bookmarks (collection_id);
@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS saved_searches (
+    id bigserial PRIMARY KEY,
+    user_id bigint NOT NULL REFERENCES users ON DELETE CASCADE,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    updated_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    name text NOT NULL,
+    keywords text NOT NULL DEFAULT '',
+    min_salary numeric,
+    max_salary numeric,
+    currency text,
+    location text,
+    frequency text NOT NULL CHECK (frequency IN ('daily', 'weekly')),
+    last_notified_at timestamp(0) with time zone,
+    version integer NOT NULL DEFAULT 1
+);
+
+CREATE INDEX IF NOT EXISTS saved_searches_user_id_idx ON saved_searches (user_id);
+
+CREATE TABLE IF NOT EXISTS saved_search_matches (
+    saved_search_id bigint NOT NULL REFERENCES saved_searches ON DELETE CASCADE,
+    offer_id bigint NOT NULL REFERENCES offers ON DELETE CASCADE,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    notified_at timestamp(0) with time zone,
+    PRIMARY KEY (saved_search_id, offer_id)
+);
+
+CREATE INDEX IF NOT EXISTS saved_search_matches_pending_idx ON saved_search_matches (saved_search_id) WHERE notified_at IS NULL;
@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS exchange_rates (
+    currency char(3) PRIMARY KEY,
+    rate numeric NOT NULL CHECK (rate > 0),
+    updated_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    updated_by bigint REFERENCES users ON DELETE SET NULL
+);
+
+-- Rates are expressed in units of the currency per 1 USD, the base currency.
+INSERT INTO exchange_rates (currency, rate)
+VALUES ('USD', 1)
+ON CONFLICT DO NOTHING;
+
+-- Normalize the currency of the stored salaries so they can be joined to the rates.
+UPDATE offers SET salary = (
+    SELECT jsonb_agg(jsonb_set(e, '{currency}', to_jsonb(upper(trim(e->>'currency')))))
+    FROM jsonb_array_elements(salary) e
+) WHERE jsonb_typeof(salary) = 'array' AND jsonb_array_length(salary) > 0;
+
+UPDATE profiles SET salary = (
+    SELECT jsonb_agg(jsonb_set(e, '{currency}', to_jsonb(upper(trim(e->>'currency')))))
+    FROM jsonb_array_elements(salary) e
+) WHERE jsonb_typeof(salary) = 'array' AND jsonb_array_length(salary) > 0;
@@ -0,0 +1,9 @@
+UPDATE offers SET salary = (
+    SELECT jsonb_agg(e - 'period' - 'type')
+    FROM jsonb_array_elements(salary) e
+) WHERE jsonb_typeof(salary) = 'array' AND jsonb_array_length(salary) > 0;
+
+UPDATE profiles SET salary = (
+    SELECT jsonb_agg(e - 'period' - 'type')
+    FROM jsonb_array_elements(salary) e
+) WHERE jsonb_typeof(salary) = 'array' AND jsonb_array_length(salary) > 0;
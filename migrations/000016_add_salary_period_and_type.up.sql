@@ -0,0 +1,10 @@
+-- Salaries stored before the period and type existed are monthly gross salaries.
+UPDATE offers SET salary = (
+    SELECT jsonb_agg('{"period": "MONTHLY", "type": "GROSS"}'::jsonb || e)
+    FROM jsonb_array_elements(salary) e
+) WHERE jsonb_typeof(salary) = 'array' AND jsonb_array_length(salary) > 0;
+
+UPDATE profiles SET salary = (
+    SELECT jsonb_agg('{"period": "MONTHLY", "type": "GROSS"}'::jsonb || e)
+    FROM jsonb_array_elements(salary) e
+) WHERE jsonb_typeof(salary) = 'array' AND jsonb_array_length(salary) > 0;
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS offer_skills;
+DROP TABLE IF EXISTS profile_skills;
+DROP TABLE IF EXISTS skill_aliases;
+DROP TABLE IF EXISTS skills;
@@ -0,0 +1,48 @@
+CREATE TABLE IF NOT EXISTS skills (
+    id bigserial PRIMARY KEY,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    name text NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS skills_lower_name_idx ON skills (lower(name));
+
+-- Aliases are stored lower-cased, so "Golang" and "golang" resolve to the same skill.
+CREATE TABLE IF NOT EXISTS skill_aliases (
+    alias text PRIMARY KEY CHECK (alias = lower(alias)),
+    skill_id bigint NOT NULL REFERENCES skills ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS skill_aliases_skill_id_idx ON skill_aliases (skill_id);
+
+CREATE TABLE IF NOT EXISTS profile_skills (
+    profile_id bigint NOT NULL REFERENCES profiles ON DELETE CASCADE,
+    skill_id bigint NOT NULL REFERENCES skills ON DELETE CASCADE,
+    years integer NOT NULL DEFAULT 0 CHECK (years >= 0),
+    level text NOT NULL CHECK (level IN ('beginner', 'intermediate', 'advanced', 'expert')),
+    PRIMARY KEY (profile_id, skill_id)
+);
+
+CREATE TABLE IF NOT EXISTS offer_skills (
+    offer_id bigint NOT NULL REFERENCES offers ON DELETE CASCADE,
+    skill_id bigint NOT NULL REFERENCES skills ON DELETE CASCADE,
+    required boolean NOT NULL DEFAULT true,
+    PRIMARY KEY (offer_id, skill_id)
+);
+
+-- The listings filter by skill, so the links are also indexed by skill.
+CREATE INDEX IF NOT EXISTS profile_skills_skill_id_idx ON profile_skills (skill_id);
+CREATE INDEX IF NOT EXISTS offer_skills_skill_id_idx ON offer_skills (skill_id);
+
+INSERT INTO skills (name)
+VALUES ('Go'), ('JavaScript'), ('TypeScript'), ('Python'), ('Java'), ('PostgreSQL'),
+    ('Kubernetes'), ('React'), ('Node.js'), ('GraphQL'), ('Docker'), ('AWS')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO skill_aliases (alias, skill_id)
+SELECT a.alias, s.id
+FROM (VALUES ('golang', 'Go'), ('js', 'JavaScript'), ('ts', 'TypeScript'), ('py', 'Python'),
+    ('postgres', 'PostgreSQL'), ('psql', 'PostgreSQL'), ('k8s', 'Kubernetes'), ('reactjs', 'React'),
+    ('react.js', 'React'), ('node', 'Node.js'), ('nodejs', 'Node.js'), ('amazon web services', 'AWS')
+) AS a (alias, name)
+INNER JOIN skills s ON s.name = a.name
+ON CONFLICT DO NOTHING;
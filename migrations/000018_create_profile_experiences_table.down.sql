@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS profile_educations;
+DROP TABLE IF EXISTS profile_experiences;
@@ -0,0 +1,37 @@
+CREATE TABLE IF NOT EXISTS profile_experiences (
+    id bigserial PRIMARY KEY,
+    profile_id bigint NOT NULL REFERENCES profiles ON DELETE CASCADE,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    updated_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    company text NOT NULL,
+    role text NOT NULL,
+    start_date date NOT NULL,
+    end_date date,
+    current boolean NOT NULL DEFAULT false,
+    description text NOT NULL DEFAULT '',
+    position integer NOT NULL DEFAULT 0,
+    version integer NOT NULL DEFAULT 1,
+    CONSTRAINT profile_experiences_dates_check CHECK (end_date IS NULL OR end_date >= start_date),
+    CONSTRAINT profile_experiences_current_check CHECK (NOT current OR end_date IS NULL)
+);
+
+CREATE TABLE IF NOT EXISTS profile_educations (
+    id bigserial PRIMARY KEY,
+    profile_id bigint NOT NULL REFERENCES profiles ON DELETE CASCADE,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    updated_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    institution text NOT NULL,
+    degree text NOT NULL,
+    field_of_study text NOT NULL DEFAULT '',
+    start_date date NOT NULL,
+    end_date date,
+    current boolean NOT NULL DEFAULT false,
+    description text NOT NULL DEFAULT '',
+    position integer NOT NULL DEFAULT 0,
+    version integer NOT NULL DEFAULT 1,
+    CONSTRAINT profile_educations_dates_check CHECK (end_date IS NULL OR end_date >= start_date),
+    CONSTRAINT profile_educations_current_check CHECK (NOT current OR end_date IS NULL)
+);
+
+CREATE INDEX IF NOT EXISTS profile_experiences_profile_id_idx ON profile_experiences (profile_id, position);
+CREATE INDEX IF NOT EXISTS profile_educations_profile_id_idx ON profile_educations (profile_id, position);
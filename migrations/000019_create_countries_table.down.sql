@@ -0,0 +1,12 @@
+DROP INDEX IF EXISTS profiles_country_idx;
+DROP INDEX IF EXISTS offers_country_idx;
+
+ALTER TABLE offers DROP CONSTRAINT IF EXISTS offers_work_mode_check;
+
+ALTER TABLE offers DROP COLUMN IF EXISTS work_mode;
+ALTER TABLE offers DROP COLUMN IF EXISTS city;
+ALTER TABLE offers DROP COLUMN IF EXISTS state;
+ALTER TABLE offers DROP COLUMN IF EXISTS country;
+
+DROP TABLE IF EXISTS subdivisions;
+DROP TABLE IF EXISTS countries;
@@ -0,0 +1,23 @@
+-- The reference is loaded from the data embedded in internal/geo by cmd/seed-locations.
+CREATE TABLE IF NOT EXISTS countries (
+    code char(2) PRIMARY KEY,
+    name text NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS subdivisions (
+    code text PRIMARY KEY,
+    country_code char(2) NOT NULL REFERENCES countries ON DELETE CASCADE,
+    name text NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS subdivisions_country_code_idx ON subdivisions (country_code);
+
+ALTER TABLE offers ADD COLUMN IF NOT EXISTS country text;
+ALTER TABLE offers ADD COLUMN IF NOT EXISTS state text;
+ALTER TABLE offers ADD COLUMN IF NOT EXISTS city text;
+ALTER TABLE offers ADD COLUMN IF NOT EXISTS work_mode text NOT NULL DEFAULT 'ONSITE';
+
+ALTER TABLE offers ADD CONSTRAINT offers_work_mode_check CHECK (work_mode IN ('REMOTE', 'HYBRID', 'ONSITE'));
+
+CREATE INDEX IF NOT EXISTS offers_country_idx ON offers (country);
+CREATE INDEX IF NOT EXISTS profiles_country_idx ON profiles (country);
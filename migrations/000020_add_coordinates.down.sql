@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS offers_coordinates_idx;
+DROP INDEX IF EXISTS profiles_coordinates_idx;
+
+ALTER TABLE offers DROP COLUMN IF EXISTS longitude;
+ALTER TABLE offers DROP COLUMN IF EXISTS latitude;
+
+ALTER TABLE profiles DROP COLUMN IF EXISTS longitude;
+ALTER TABLE profiles DROP COLUMN IF EXISTS latitude;
@@ -0,0 +1,11 @@
+-- Coordinates are resolved from the gazetteer embedded in internal/geo. Proximity
+-- searches first narrow the rows to a bounding box with these indexes, and only then
+-- compute the exact haversine distance, so PostGIS isn't needed.
+ALTER TABLE profiles ADD COLUMN IF NOT EXISTS latitude double precision;
+ALTER TABLE profiles ADD COLUMN IF NOT EXISTS longitude double precision;
+
+ALTER TABLE offers ADD COLUMN IF NOT EXISTS latitude double precision;
+ALTER TABLE offers ADD COLUMN IF NOT EXISTS longitude double precision;
+
+CREATE INDEX IF NOT EXISTS profiles_coordinates_idx ON profiles (latitude, longitude) WHERE latitude IS NOT NULL;
+CREATE INDEX IF NOT EXISTS offers_coordinates_idx ON offers (latitude, longitude) WHERE latitude IS NOT NULL;
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS resumes (
+    id bigserial PRIMARY KEY,
+    user_id bigint NOT NULL REFERENCES users ON DELETE CASCADE,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    storage_key text NOT NULL UNIQUE,
+    filename text NOT NULL,
+    content_type text NOT NULL,
+    size bigint NOT NULL CHECK (size > 0),
+    checksum text NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS resumes_user_id_idx ON resumes (user_id, created_at);
@@ -0,0 +1 @@
+ALTER TABLE offers_applicants DROP COLUMN IF EXISTS applied_by;
@@ -0,0 +1,4 @@
+-- The user that made the application. Only the applications made by the owner of the
+-- profile let the recruiters see their resume and private details, the ones made before
+-- this column existed are left NULL as nobody checked who made them.
+ALTER TABLE offers_applicants ADD COLUMN IF NOT EXISTS applied_by bigint REFERENCES users ON DELETE SET NULL;
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS offers_company_id_idx;
+
+ALTER TABLE offers DROP COLUMN IF EXISTS company_id;
+
+DROP TABLE IF EXISTS company_invitations;
+DROP TABLE IF EXISTS company_members;
+DROP TABLE IF EXISTS companies;
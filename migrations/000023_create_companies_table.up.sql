@@ -0,0 +1,40 @@
+CREATE TABLE IF NOT EXISTS companies (
+    id bigserial PRIMARY KEY,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    updated_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    name text NOT NULL,
+    website_url text NOT NULL DEFAULT '',
+    version integer NOT NULL DEFAULT 1
+);
+
+CREATE TABLE IF NOT EXISTS company_members (
+    company_id bigint NOT NULL REFERENCES companies ON DELETE CASCADE,
+    user_id bigint NOT NULL REFERENCES users ON DELETE CASCADE,
+    role text NOT NULL,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (company_id, user_id),
+    CONSTRAINT company_members_role_check CHECK (role IN ('OWNER', 'ADMIN', 'RECRUITER'))
+);
+
+-- Every company has exactly one owner.
+CREATE UNIQUE INDEX IF NOT EXISTS company_members_owner_idx ON company_members (company_id) WHERE role = 'OWNER';
+CREATE INDEX IF NOT EXISTS company_members_user_id_idx ON company_members (user_id);
+
+-- Invitations are addressed to an email, the invitee may not have an account yet. Like
+-- the tokens, only the hash of the plaintext sent by email is stored.
+CREATE TABLE IF NOT EXISTS company_invitations (
+    hash bytea PRIMARY KEY,
+    company_id bigint NOT NULL REFERENCES companies ON DELETE CASCADE,
+    email citext NOT NULL,
+    role text NOT NULL,
+    invited_by bigint NOT NULL REFERENCES users ON DELETE CASCADE,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    expiry timestamp(0) with time zone NOT NULL,
+    UNIQUE (company_id, email),
+    CONSTRAINT company_invitations_role_check CHECK (role IN ('ADMIN', 'RECRUITER'))
+);
+
+-- Deleting a company keeps its offers, they go back to the users that created them.
+ALTER TABLE offers ADD COLUMN IF NOT EXISTS company_id bigint REFERENCES companies ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS offers_company_id_idx ON offers (company_id);
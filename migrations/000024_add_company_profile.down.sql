@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS companies_industry_idx;
+
+ALTER TABLE companies DROP CONSTRAINT IF EXISTS companies_size_check;
+ALTER TABLE companies DROP CONSTRAINT IF EXISTS companies_slug_key;
+
+ALTER TABLE companies DROP COLUMN IF EXISTS locations;
+ALTER TABLE companies DROP COLUMN IF EXISTS industry;
+ALTER TABLE companies DROP COLUMN IF EXISTS size;
+ALTER TABLE companies DROP COLUMN IF EXISTS description;
+ALTER TABLE companies DROP COLUMN IF EXISTS logo_url;
+ALTER TABLE companies DROP COLUMN IF EXISTS slug;
@@ -0,0 +1,16 @@
+-- Public company page. The slug is generated from the name when the company is created
+-- and kept afterwards, so the page URL doesn't break when the company is renamed.
+ALTER TABLE companies ADD COLUMN IF NOT EXISTS slug text;
+ALTER TABLE companies ADD COLUMN IF NOT EXISTS logo_url text NOT NULL DEFAULT '';
+ALTER TABLE companies ADD COLUMN IF NOT EXISTS description text NOT NULL DEFAULT '';
+ALTER TABLE companies ADD COLUMN IF NOT EXISTS size text;
+ALTER TABLE companies ADD COLUMN IF NOT EXISTS industry text NOT NULL DEFAULT '';
+ALTER TABLE companies ADD COLUMN IF NOT EXISTS locations jsonb NOT NULL DEFAULT '[]';
+
+UPDATE companies SET slug = 'company-' || id WHERE slug IS NULL;
+
+ALTER TABLE companies ALTER COLUMN slug SET NOT NULL;
+ALTER TABLE companies ADD CONSTRAINT companies_slug_key UNIQUE (slug);
+ALTER TABLE companies ADD CONSTRAINT companies_size_check CHECK (size IN ('TINY', 'SMALL', 'MEDIUM', 'LARGE', 'ENTERPRISE'));
+
+CREATE INDEX IF NOT EXISTS companies_industry_idx ON companies (lower(industry));
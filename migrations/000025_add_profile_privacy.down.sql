@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS profile_hidden_companies;
+
+ALTER TABLE profiles DROP CONSTRAINT IF EXISTS profiles_visibility_check;
+ALTER TABLE profiles DROP COLUMN IF EXISTS visibility;
@@ -0,0 +1,13 @@
+-- Who can see the contact details of the profile: its email, website and salary. The
+-- companies of profile_hidden_companies never can, whatever the visibility.
+ALTER TABLE profiles ADD COLUMN IF NOT EXISTS visibility text NOT NULL DEFAULT 'PUBLIC';
+ALTER TABLE profiles ADD CONSTRAINT profiles_visibility_check CHECK (visibility IN ('PUBLIC', 'RECRUITERS', 'PRIVATE'));
+
+CREATE TABLE IF NOT EXISTS profile_hidden_companies (
+    profile_id bigint NOT NULL REFERENCES profiles ON DELETE CASCADE,
+    company_id bigint NOT NULL REFERENCES companies ON DELETE CASCADE,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (profile_id, company_id)
+);
+
+CREATE INDEX IF NOT EXISTS profile_hidden_companies_company_id_idx ON profile_hidden_companies (company_id);
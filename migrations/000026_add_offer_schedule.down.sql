@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS offers_expires_at_idx;
+DROP INDEX IF EXISTS offers_publish_at_idx;
+
+ALTER TABLE offers_applicants DROP COLUMN IF EXISTS closure_notified_at;
+
+ALTER TABLE offers DROP COLUMN IF EXISTS closed_at;
+ALTER TABLE offers DROP COLUMN IF EXISTS expires_at;
+ALTER TABLE offers DROP COLUMN IF EXISTS publish_at;
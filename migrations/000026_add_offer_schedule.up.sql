@@ -0,0 +1,11 @@
+-- The scheduler publishes the offers once publish_at is reached, clearing it, and closes
+-- them at expires_at. closed_at is kept until the offer is renewed, the applicants are
+-- told about the closing once, tracked by closure_notified_at.
+ALTER TABLE offers ADD COLUMN IF NOT EXISTS publish_at timestamp(0) with time zone;
+ALTER TABLE offers ADD COLUMN IF NOT EXISTS expires_at timestamp(0) with time zone;
+ALTER TABLE offers ADD COLUMN IF NOT EXISTS closed_at timestamp(0) with time zone;
+
+ALTER TABLE offers_applicants ADD COLUMN IF NOT EXISTS closure_notified_at timestamp(0) with time zone;
+
+CREATE INDEX IF NOT EXISTS offers_publish_at_idx ON offers (publish_at) WHERE publish_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS offers_expires_at_idx ON offers (expires_at) WHERE closed_at IS NULL;
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS offer_revisions;
+
+ALTER TABLE offers DROP CONSTRAINT IF EXISTS offers_active_status_check;
+ALTER TABLE offers DROP CONSTRAINT IF EXISTS offers_status_check;
+ALTER TABLE offers DROP COLUMN IF EXISTS status;
@@ -0,0 +1,29 @@
+-- The status replaces the active flag as the source of truth, active is kept for the
+-- listings and must match it.
+ALTER TABLE offers ADD COLUMN IF NOT EXISTS status text NOT NULL DEFAULT 'DRAFT';
+
+UPDATE offers SET status = CASE
+    WHEN active THEN 'PUBLISHED'
+    WHEN closed_at IS NOT NULL THEN 'CLOSED'
+    ELSE 'DRAFT'
+END;
+
+ALTER TABLE offers ADD CONSTRAINT offers_status_check CHECK (status IN ('DRAFT', 'PUBLISHED', 'CLOSED', 'ARCHIVED'));
+ALTER TABLE offers ADD CONSTRAINT offers_active_status_check CHECK (active = (status = 'PUBLISHED'));
+
+CREATE TABLE IF NOT EXISTS offer_revisions (
+    id bigserial PRIMARY KEY,
+    offer_id bigint NOT NULL REFERENCES offers ON DELETE CASCADE,
+    version integer NOT NULL,
+    user_id bigint REFERENCES users ON DELETE SET NULL,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    title text NOT NULL,
+    description text NOT NULL,
+    salary jsonb NOT NULL,
+    status text NOT NULL,
+    UNIQUE (offer_id, version)
+);
+
+-- The current state of the existing offers is their first revision.
+INSERT INTO offer_revisions (offer_id, version, user_id, created_at, title, description, salary, status)
+SELECT id, version, user_id, created_at, title, description, COALESCE(salary, '[]'), status FROM offers;
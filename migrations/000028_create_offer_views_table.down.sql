@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS offers_applicants_offer_id_created_at_idx;
+
+DROP TABLE IF EXISTS offer_views;
@@ -0,0 +1,12 @@
+-- A viewer counts once per offer and day. viewer_key is the user id for the logged in
+-- viewers and a hash of the IP address and the user agent for the anonymous ones.
+CREATE TABLE IF NOT EXISTS offer_views (
+    offer_id bigint NOT NULL REFERENCES offers ON DELETE CASCADE,
+    viewer_key text NOT NULL,
+    day date NOT NULL,
+    user_id bigint REFERENCES users ON DELETE SET NULL,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (offer_id, day, viewer_key)
+);
+
+CREATE INDEX IF NOT EXISTS offers_applicants_offer_id_created_at_idx ON offers_applicants (offer_id, created_at);
@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS moderation_decisions;
+DROP TABLE IF EXISTS reports;
+
+ALTER TABLE profiles DROP CONSTRAINT IF EXISTS profiles_moderation_status_check;
+ALTER TABLE profiles DROP COLUMN IF EXISTS moderation_status;
+
+ALTER TABLE offers DROP CONSTRAINT IF EXISTS offers_moderation_status_check;
+ALTER TABLE offers DROP COLUMN IF EXISTS moderation_status;
@@ -0,0 +1,40 @@
+-- Offers and profiles are listed until a moderator rejects or bans them. A report moves
+-- them to PENDING, they stay listed while they wait for a decision.
+ALTER TABLE offers ADD COLUMN IF NOT EXISTS moderation_status text NOT NULL DEFAULT 'APPROVED';
+ALTER TABLE offers ADD CONSTRAINT offers_moderation_status_check CHECK (moderation_status IN ('APPROVED', 'PENDING', 'REJECTED', 'BANNED'));
+
+ALTER TABLE profiles ADD COLUMN IF NOT EXISTS moderation_status text NOT NULL DEFAULT 'APPROVED';
+ALTER TABLE profiles ADD CONSTRAINT profiles_moderation_status_check CHECK (moderation_status IN ('APPROVED', 'PENDING', 'REJECTED', 'BANNED'));
+
+-- A user reports an offer or a profile once until it's decided on, the report is resolved
+-- by the next decision on it and the user can report it again afterwards.
+CREATE TABLE IF NOT EXISTS reports (
+    id bigserial PRIMARY KEY,
+    user_id bigint NOT NULL REFERENCES users ON DELETE CASCADE,
+    offer_id bigint REFERENCES offers ON DELETE CASCADE,
+    profile_id bigint REFERENCES profiles ON DELETE CASCADE,
+    reason text NOT NULL,
+    details text NOT NULL DEFAULT '',
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    resolved_at timestamp(0) with time zone,
+    CONSTRAINT reports_target_check CHECK ((offer_id IS NULL) <> (profile_id IS NULL)),
+    CONSTRAINT reports_reason_check CHECK (reason IN ('SPAM', 'SCAM', 'INAPPROPRIATE', 'OTHER'))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS reports_open_user_id_offer_id_idx ON reports (user_id, offer_id) WHERE resolved_at IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS reports_open_user_id_profile_id_idx ON reports (user_id, profile_id) WHERE resolved_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS reports_offer_id_idx ON reports (offer_id) WHERE resolved_at IS NULL;
+CREATE INDEX IF NOT EXISTS reports_profile_id_idx ON reports (profile_id) WHERE resolved_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS moderation_decisions (
+    id bigserial PRIMARY KEY,
+    moderator_id bigint REFERENCES users ON DELETE SET NULL,
+    offer_id bigint REFERENCES offers ON DELETE CASCADE,
+    profile_id bigint REFERENCES profiles ON DELETE CASCADE,
+    action text NOT NULL,
+    note text NOT NULL DEFAULT '',
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    CONSTRAINT moderation_decisions_target_check CHECK ((offer_id IS NULL) <> (profile_id IS NULL)),
+    CONSTRAINT moderation_decisions_action_check CHECK (action IN ('APPROVE', 'REJECT', 'BAN'))
+);
@@ -0,0 +1,6 @@
+-- Suspended users can't log in. Deleted users are anonymized right away and purged, with
+-- everything that belongs to them, once the grace period is over.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS suspended_at timestamp(0) with time zone;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deleted_at timestamp(0) with time zone;
+
+CREATE INDEX IF NOT EXISTS users_deleted_at_idx ON users (deleted_at) WHERE deleted_at IS NOT NULL;